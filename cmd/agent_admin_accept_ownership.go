@@ -7,7 +7,7 @@ Copyright © 2023 Glif LTD
 package cmd
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -31,31 +31,28 @@ var acceptOwnershipCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		exitevt := journal.RegisterEventType("agent", "admin")
 		evt := &events.AgentAdmin{
 			Action:  "accept-ownership",
 			AgentID: agentAddr.String(),
 		}
+		txj := newTxJournal("agent", "admin", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(exitevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentAcceptOwnership(ctx, auth, agentAddr)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
 		s.Stop()
 
-		log.Printf("Successfully accepted ownership change on agent %s\n", agentAddr.String())
+		slog.Info("Successfully accepted ownership change", logKeyAgent, agentAddr.String())
 	},
 }
 
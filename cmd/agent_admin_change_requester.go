@@ -7,7 +7,7 @@ Copyright © 2023 Glif LTD
 package cmd
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -37,32 +37,29 @@ var changeRequesterCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		exitevt := journal.RegisterEventType("agent", "admin")
 		evt := &events.AgentAdmin{
 			Action:          "change-requester",
 			AgentID:         agentAddr.String(),
 			NewAdminAddress: newRequester.Hex(),
 		}
+		txj := newTxJournal("agent", "admin", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(exitevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentChangeRequester(ctx, auth, agentAddr, newRequester)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
 		s.Stop()
 
-		log.Printf("Successfully changed requester key on the agent: %s, new requester: %s\n", agentAddr.String(), newRequester.Hex())
+		slog.Info("Successfully changed requester key", logKeyAgent, agentAddr.String(), "requester", newRequester.Hex())
 	},
 }
 
@@ -4,7 +4,7 @@ Copyright © 2023 Glif LTD
 package cmd
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -29,31 +29,28 @@ var setRecoveredCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		exitevt := journal.RegisterEventType("agent", "admin")
 		evt := &events.AgentAdmin{
 			Action:  "set-recovered",
 			AgentID: agentAddr.String(),
 		}
+		txj := newTxJournal("agent", "admin", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(exitevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentSetRecovered(ctx, auth, agentAddr, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
 		s.Stop()
 
-		log.Println("Successfully recovered agent: ", agentAddr.String())
+		slog.Info("Successfully recovered agent", logKeyAgent, agentAddr.String())
 	},
 }
 
@@ -7,7 +7,8 @@ Copyright © 2023 Glif LTD
 package cmd
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -36,35 +37,38 @@ var transferOperatorCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		exitevt := journal.RegisterEventType("agent", "admin")
 		evt := &events.AgentAdmin{
 			Action:          "transfer-operator",
 			AgentID:         agentAddr.String(),
 			NewAdminAddress: newOperator.Hex(),
 		}
+		txj := newTxJournal("agent", "admin", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(exitevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentTransferOperator(ctx, auth, agentAddr, newOperator)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
+		}
+		if unsignedOutFlag != "" {
+			s.Stop()
+			writeUnsignedTx(txj, tx, auth.From, fmt.Sprintf("Propose %s as the operator of agent %s", newOperator.Hex(), agentAddr))
+			return
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
 		s.Stop()
 
-		log.Printf("Successfully proposed operator change to agent %s, new operator %s\n", agentAddr.String(), newOperator.Hex())
+		slog.Info("Successfully proposed operator change", logKeyAgent, agentAddr.String(), "operator", newOperator.Hex())
 	},
 }
 
 func init() {
 	adminCmd.AddCommand(transferOperatorCmd)
+	addUnsignedOutFlag(transferOperatorCmd)
 }
@@ -1,21 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/filecoin-project/go-address"
 	"github.com/glifio/glif/v2/events"
-	"github.com/glifio/glif/v2/journal/fsjournal"
-	"github.com/glifio/go-pools/abigen"
+	"github.com/glifio/glif/v2/util"
 	"github.com/glifio/go-pools/constants"
-	"github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,7 +26,32 @@ import (
 var agentAutopilotCmd = &cobra.Command{
 	Use:   "autopilot",
 	Short: "Background service that automatically repays FIL to pools",
-	Long:  `Background service that automatically repays FIL to pools.`,
+	Long: `Background service that automatically repays FIL to pools.
+
+With --once, autopilot runs a single payment cycle for cron jobs or systemd
+timers, prints a JSON summary of the cycle to stdout (YAML with --output yaml)
+and exits with one of the following codes:
+
+  0   no payment was due
+  2   a payment was made
+  3   funds were pulled from a miner and a payment was made
+  4   a payment is due, but --dry-run only explained it
+  5   a payment is due, but it was deferred until the base fee drops
+  6   a payment was sent but did not land yet
+  1   autopilot could not start, e.g. another autopilot is running
+  10  invalid autopilot config
+  11  the agent address could not be resolved
+  12  the infinity pool account could not be read
+  13  the chain height could not be read
+  14  pulling funds from the miner failed
+  15  the payment failed
+
+Autopilot manages several agents when its config lists [[autopilot.profiles]],
+each with its own config directory. It runs an autopilot per profile, which
+logs with a profile field unless it has its own log file, and
+restarts the autopilot of a profile that exits. With --once, it prints the
+summary of every profile and exits with the code of the first failed profile,
+or else with the highest code of the profiles.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		defer func() {
 			if r := recover(); r != nil {
@@ -31,149 +59,179 @@ var agentAutopilotCmd = &cobra.Command{
 			}
 		}()
 
+		// --logfile predates the global --log-file, and still overrides it
 		if cmd.Flag("logfile") != nil && cmd.Flag("logfile").Changed {
-			file, err := os.OpenFile(cmd.Flag("logfile").Value.String(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-			if err != nil {
-				log.Fatal(err)
+			if err := setupLogging(cmd.Flag("logfile").Value.String()); err != nil {
+				logFatal(err)
+			}
+		}
+
+		profiles, err := autopilotProfiles()
+		if err != nil {
+			logFatal(err)
+		}
+
+		once, _ := cmd.Flags().GetBool("once")
+		if len(profiles) > 0 {
+			if once {
+				autopilotProfilesOnce(cmd, profiles)
+			} else {
+				runAutopilotProfiles(cmd, profiles)
 			}
-			log.SetOutput(file)
+			return
+		}
+		if once {
+			autopilotOnce(cmd)
+			return
 		}
 
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
-		ctx := cmd.Context()
+		slog.Info("Starting autopilot...", "daemon", viper.GetString("daemon.rpc-url"))
+
+		pidLock, err := lockAutopilot()
+		if err != nil {
+			logFatal(err)
+		}
+		defer pidLock.Unlock()
+
+		metrics := newAutopilotMetrics()
+		if listen := viper.GetString("autopilot.metrics.listen"); listen != "" {
+			healthIntervals := viper.GetInt("autopilot.metrics.health-intervals")
+			if healthIntervals <= 0 {
+				healthIntervals = 3
+			}
+			serveAutopilotMetrics(listen, metrics, autopilotInterval(), healthIntervals)
+		}
 
-		log.Println("Starting autopilot...")
+		alerts, err := newAutopilotAlerts()
+		if err != nil {
+			logFatal(err)
+		}
+
+		control, err := listenAutopilotControl(metrics)
+		if err != nil {
+			logFatal(err)
+		}
+		defer control.Close()
+		slog.Info("Listening for control commands", "socket", autopilotSocketPath())
 
-		log.Println("Lotus Daemon: ", viper.GetString("daemon.rpc-url"))
+		// the journal is reopened every loop to pick up config changes
+		defer func() { journal.Close() }()
 		for {
+			journal.Close()
+
 			var err error
-			if journal, err = fsjournal.OpenFSJournal(cfgDir, nil); err != nil {
+			if journal, err = openJournal(); err != nil {
 				logFatal(err)
 			}
-			defer journal.Close()
 
 			select {
 			case <-sigs:
-				log.Println("Shutting down...")
+				slog.Info("Shutting down...")
+				Exit(0)
+			case <-control.stopped():
+				slog.Info("Shutting down...")
 				Exit(0)
 			default:
-				log.Println("Checking for payments...")
-
-				// CONFIG options
-				// each loop retrieve config values aka hot-reload
-				paymentType, err := ParsePaymentType(viper.GetString("autopilot.payment-type"))
-				if err != nil {
-					log.Println(err)
-					continue
-				}
-				log.Println("Payment type: ", paymentType)
-				payargs := []string{}
-				if paymentType == Principal || paymentType == Custom {
-					amount := viper.GetInt64("autopilot.amount")
-					payargs = append(payargs, fmt.Sprintf("%d", amount))
+				runAutopilotCycle(cmd, metrics, alerts)
+
+				sleepTime := autopilotInterval()
+				metrics.loopCompleted(clock.Now().Add(sleepTime))
+				if !control.sleep(sleepTime, sigs) {
+					slog.Info("Shutting down...")
+					Exit(0)
 				}
+			}
+		}
+	},
+}
 
-				pullFundsEnabled := viper.GetBool("autopilot.pullfunds.enabled")
-				pullFundsFactor := viper.GetInt("autopilot.pullfunds.pull-amount-factor")
-				log.Println("pullfunds: ", pullFundsEnabled)
-				log.Println("pullfunds-factor: ", pullFundsFactor)
+// autopilotOnce runs a single autopilot cycle, prints its summary to stdout
+// and exits with the code of its outcome
+func autopilotOnce(cmd *cobra.Command) {
+	// the summary is always machine-readable, which also keeps spinners off
+	// stdout
+	if outputFormat() == OutputTable {
+		outputFlag = string(OutputJSON)
+	}
 
-				//TODO: maybe change frequency to max debt or max epoch difference
-				frequency := viper.GetFloat64("autopilot.frequency")
+	alerts, err := newAutopilotAlerts()
+	if err != nil {
+		logFatal(err)
+	}
 
-				log.Println("frequency (days): ", frequency)
+	// a cycle must not overlap with a running autopilot or another scheduled
+	// cycle
+	pidLock, err := lockAutopilot()
+	if err != nil {
+		logFatal(err)
+	}
+	defer pidLock.Unlock()
+	defer journal.Close()
 
-				// goto can't jump over variable declarations
-				var chainHeadHeight *big.Int
-				var account abigen.Account
-				var pullFundsMiner address.Address
+	res := runAutopilotCycle(cmd, newAutopilotMetrics(), alerts)
+	printOutput(res, nil)
+	Exit(res.exitCode())
+}
 
-				agent, err := getAgentAddressWithFlags(cmd)
-				if err != nil {
-					log.Println(err)
-					goto SLEEP
-				}
+// lockAutopilot takes the lock that keeps a single autopilot running per config
+// directory, and records the PID of this process in the lock file. The lock is
+// released by the OS if the process dies.
+func lockAutopilot() (*util.FileLock, error) {
+	path := filepath.Join(cfgDir, "autopilot.pid")
 
-				account, err = PoolsSDK.Query().InfPoolGetAccount(ctx, agent, nil)
-				if err != nil {
-					log.Println(err)
-					goto SLEEP
-				}
-				if account == (abigen.Account{}) {
-					log.Println("failed to get infinity pool account, check evm api provider status")
-					goto SLEEP
-				}
+	l, err := util.TryLockFile(path)
+	if errors.Is(err, util.ErrLocked) {
+		pid, _ := os.ReadFile(path)
+		return nil, fmt.Errorf("autopilot is already running with pid %s (lock file %s)", strings.TrimSpace(string(pid)), path)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-				chainHeadHeight, err = PoolsSDK.Query().ChainHeight(cmd.Context())
-				if err != nil {
-					log.Println(err)
-					goto SLEEP
-				}
-				if chainHeadHeight == nil {
-					log.Println("failed to get chainheight, check lotus api provider status")
-					goto SLEEP
-				}
+	f := l.File()
+	if err := f.Truncate(0); err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	if _, err := f.WriteAt([]byte(fmt.Sprintf("%d\n", os.Getpid())), 0); err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return l, nil
+}
 
-				// check if payment is due
-				// if so, make payment
-				if paymentDue(frequency, chainHeadHeight, account.EpochsPaid) {
-					if pullFundsEnabled {
-						pullFundsMiner, err = ToMinerID(cmd.Context(), viper.GetString("autopilot.pullfunds.miner"))
-						if err != nil {
-							log.Println(err)
-							goto SLEEP
-						}
-
-						payAmt, err := payAmount(ctx, cmd, payargs, paymentType)
-						if err != nil {
-							log.Println(err)
-							goto SLEEP
-						}
-
-						pull, err := needToPullFunds(cmd, payAmt)
-						if err != nil {
-							log.Println(err)
-							goto SLEEP
-						}
-
-						if pull {
-							factoredPullAmt := new(big.Int).Mul(payAmt, big.NewInt(int64(pullFundsFactor)))
-
-							factoredPullAmtFIL, _ := util.ToFIL(factoredPullAmt).Float64()
-							log.Printf("Pulling %0.08f (or max available) from miner %s", factoredPullAmtFIL, pullFundsMiner)
-							err = pullFundsFromMiner(cmd, pullFundsMiner, factoredPullAmt)
-							if err != nil {
-								log.Println(err)
-								goto SLEEP
-							}
-						}
-
-					}
-
-					log.Printf("Making payment: %v", payargs)
-					_, err = pay(cmd, payargs, paymentType)
-					if err != nil {
-						log.Println(err)
-					}
+// autopilotInterval is the time autopilot sleeps between two checks
+func autopilotInterval() time.Duration {
+	if debugSetup {
+		return 30 * time.Second
+	}
+	return 30 * time.Minute
+}
 
-				}
-			SLEEP:
-				sleepTime := 30 * time.Minute
-				if debugSetup {
-					sleepTime = 30 * time.Second
-				}
-				select {
-				case <-time.After(sleepTime):
-					continue
-				case <-sigs:
-					log.Println("Shutting down...")
-					Exit(0)
-				}
-			}
-		}
-	},
+// checkAgentHealth refreshes the agent's economic state exposed in the metrics,
+// and raises or resolves the alerts that derive from the agent's health
+func checkAgentHealth(ctx context.Context, agent common.Address, metrics *autopilotMetrics, alerts *autopilotAlerts) error {
+	agentID, err := PoolsSDK.Query().AgentID(ctx, agent)
+	if err != nil {
+		return err
+	}
+
+	econ, err := econInfo(ctx, agent, agentID)
+	if err != nil {
+		return err
+	}
+	metrics.recordEcon(econ)
+
+	health, err := agentHealth(ctx, agent, econ.agentData, econ.ats)
+	if err != nil {
+		return err
+	}
+	alerts.checkHealth(agent, health)
+
+	return nil
 }
 
 func paymentDue(frequency float64, chainHeadHeight, epochsPaid *big.Int) bool {
@@ -187,20 +245,20 @@ func paymentDue(frequency float64, chainHeadHeight, epochsPaid *big.Int) bool {
 	return epochsPassed.Cmp(epochFreqInt) >= 0
 }
 
-// needToPullFunds returns whether the payAmt is larger than the agent
-// liquid assets.
-func needToPullFunds(cmd *cobra.Command, payAmt *big.Int) (bool, error) {
+// pullShortfall returns the part of payAmt that the agent liquid assets do
+// not cover, which is not positive when no funds need to be pulled
+func pullShortfall(cmd *cobra.Command, payAmt *big.Int) (*big.Int, error) {
 	agentAddr, err := getAgentAddressWithFlags(cmd)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	assets, err := PoolsSDK.Query().AgentLiquidAssets(cmd.Context(), agentAddr, nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return payAmt.Cmp(assets) > 0, nil
+	return new(big.Int).Sub(payAmt, assets), nil
 }
 
 func pullFundsFromMiner(cmd *cobra.Command, miner address.Address, amount *big.Int) error {
@@ -210,25 +268,22 @@ func pullFundsFromMiner(cmd *cobra.Command, miner address.Address, amount *big.I
 	if err != nil {
 		return err
 	}
-	pullevt := journal.RegisterEventType("agent", "pull")
 	evt := &events.AgentMinerPull{
 		AgentID: agentAddr.String(),
 		MinerID: miner.String(),
 		Amount:  amount.String(),
 	}
-	defer journal.RecordEvent(pullevt, func() interface{} { return evt })
+	txj := newTxJournal("agent", "pull", evt)
 
 	tx, err := PoolsSDK.Act().AgentPullFunds(cmd.Context(), auth, agentAddr, amount, miner, requesterKey)
 	if err != nil {
-		evt.Error = err.Error()
-		return err
+		return txj.failed(err)
 	}
-	evt.Tx = tx.Hash().String()
+	txj.submitted(tx)
 
 	// transaction landed on chain or errored
-	_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+	_, err = txj.wait(cmd.Context(), tx)
 	if err != nil {
-		evt.Error = err.Error()
 		return err
 	}
 	return nil
@@ -242,5 +297,7 @@ func init() {
 	agentAutopilotCmd.Flags().String("pool-name", "infinity-pool", "name of the pool to make a payment")
 	agentAutopilotCmd.Flags().String("from", "", "address to send the transaction from")
 	agentAutopilotCmd.Flags().String("logfile", "", "Logfile path, if empty autopilot logs to stderr")
+	agentAutopilotCmd.Flags().MarkDeprecated("logfile", "use --log-file instead")
+	agentAutopilotCmd.Flags().Bool("once", false, "run a single payment cycle, print its summary and exit with the code of its outcome")
 	agentAutopilotCmd.Flags().BoolVar(&debugSetup, "debug", false, "enable debug setup, i.e. 30 second sleep in main loop")
 }
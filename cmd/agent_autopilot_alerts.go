@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/journal/alerting"
+	"github.com/glifio/glif/v2/util"
+	"github.com/spf13/viper"
+)
+
+// currentJournal forwards to the package level journal, which autopilot
+// reopens on every loop iteration
+type currentJournal struct{}
+
+var _ jnal.Journal = currentJournal{}
+
+func (currentJournal) RegisterEventType(system, event string) jnal.EventType {
+	return journal.RegisterEventType(system, event)
+}
+
+func (currentJournal) RecordEvent(evtType jnal.EventType, supplier func() interface{}) {
+	journal.RecordEvent(evtType, supplier)
+}
+
+func (currentJournal) RecordEventAt(evtType jnal.EventType, t time.Time, supplier func() interface{}) {
+	journal.RecordEventAt(evtType, t, supplier)
+}
+
+func (currentJournal) ReadEvents() ([]jnal.Event, error) {
+	return journal.ReadEvents()
+}
+
+func (currentJournal) QueryEvents(q jnal.Query, fn func(jnal.Event) error) error {
+	return journal.QueryEvents(q, fn)
+}
+
+func (currentJournal) Close() error {
+	return journal.Close()
+}
+
+// autopilotAlertMessage is the payload of raised and resolved autopilot alerts
+type autopilotAlertMessage struct {
+	Agent   string `json:"agent,omitempty"`
+	Message string `json:"message"`
+}
+
+// autopilotAlerts raises and resolves the alerts autopilot watches for
+type autopilotAlerts struct {
+	a *alerting.Alerting
+
+	latePayment      alerting.AlertType
+	overLTV          alerting.AlertType
+	faultySectors    alerting.AlertType
+	defaulted        alerting.AlertType
+	operatorUnfunded alerting.AlertType
+	paymentFailures  alerting.AlertType
+	rpcUnreachable   alerting.AlertType
+
+	failedPayments    int
+	maxFailedPayments int
+}
+
+func newAutopilotAlerts() (*autopilotAlerts, error) {
+	a := alerting.NewAlertingSystem(currentJournal{})
+
+	sinks, err := alertSinksFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	renotify := viper.GetDuration("autopilot.alerts.renotify-interval")
+	a.SetNotifier(alerting.NewNotifier(renotify, sinks...))
+
+	maxFailedPayments := viper.GetInt("autopilot.alerts.payment-failures")
+	if maxFailedPayments <= 0 {
+		maxFailedPayments = 3
+	}
+
+	return &autopilotAlerts{
+		a:                 a,
+		latePayment:       a.AddAlertType("autopilot", "late-payment"),
+		overLTV:           a.AddAlertType("autopilot", "over-ltv"),
+		faultySectors:     a.AddAlertType("autopilot", "faulty-sectors"),
+		defaulted:         a.AddAlertType("autopilot", "defaulted"),
+		operatorUnfunded:  a.AddAlertType("autopilot", "operator-unfunded"),
+		paymentFailures:   a.AddAlertType("autopilot", "payment-failures"),
+		rpcUnreachable:    a.AddAlertType("autopilot", "rpc-unreachable"),
+		maxFailedPayments: maxFailedPayments,
+	}, nil
+}
+
+// alertSinksFromConfig builds the notification sinks configured in the
+// [autopilot.alerts] config section
+func alertSinksFromConfig() ([]alerting.Sink, error) {
+	sinks := []alerting.Sink{}
+
+	for _, url := range viper.GetStringSlice("autopilot.alerts.webhooks") {
+		sinks = append(sinks, alerting.NewWebhookSink(url))
+	}
+
+	for _, url := range viper.GetStringSlice("autopilot.alerts.chat-webhooks") {
+		sinks = append(sinks, alerting.NewChatWebhookSink(url))
+	}
+
+	if server := viper.GetString("autopilot.alerts.email.smtp-server"); server != "" {
+		to := viper.GetStringSlice("autopilot.alerts.email.to")
+		if len(to) == 0 {
+			return nil, fmt.Errorf("autopilot.alerts.email.to must list at least one recipient")
+		}
+		sinks = append(sinks, &alerting.EmailSink{
+			Server:   server,
+			Username: viper.GetString("autopilot.alerts.email.username"),
+			Password: viper.GetString("autopilot.alerts.email.password"),
+			From:     viper.GetString("autopilot.alerts.email.from"),
+			To:       to,
+		})
+	}
+
+	// the exec command is a list of the program followed by its arguments
+	if command := viper.GetStringSlice("autopilot.alerts.exec"); len(command) > 0 {
+		sinks = append(sinks, &alerting.ExecSink{Command: command[0], Args: command[1:]})
+	}
+
+	return sinks, nil
+}
+
+func (aa *autopilotAlerts) set(at alerting.AlertType, active bool, agent common.Address, msg string) {
+	m := autopilotAlertMessage{Message: msg}
+	if agent != (common.Address{}) {
+		m.Agent = agent.String()
+	}
+
+	if active {
+		aa.a.Raise(at, m)
+	} else {
+		aa.a.Resolve(at, m)
+	}
+}
+
+// checkHealth raises or resolves the alerts that derive from the agent's health
+func (aa *autopilotAlerts) checkHealth(agent common.Address, health *agentHealthInfo) {
+	aa.set(aa.latePayment, health.LatePayment, agent,
+		fmt.Sprintf("agent is late on its weekly payment, it must pay to-current by epoch %s", health.DefaultEpoch))
+	aa.set(aa.overLTV, health.OverLTV, agent,
+		"agent is over the LTV limit, pay down debt or increase collateral to avoid liquidation")
+	aa.set(aa.faultySectors, health.OverFaultySectorLimit, agent,
+		fmt.Sprintf("faulty sector ratio %.02f%% is over the %v%% limit", health.FaultySectorRatioPercent, health.FaultySectorLimitPercent))
+	aa.set(aa.defaulted, health.Defaulted, agent, "agent is in default")
+}
+
+// checkOperatorFunded raises an alert when the operator account cannot pay for gas
+func (aa *autopilotAlerts) checkOperatorFunded(ctx context.Context, agent common.Address) {
+	_, opFevm, err := util.AccountsStore().GetAddrs(string(util.OperatorKey))
+	if err != nil {
+		// no operator account configured, payments are sent from the owner
+		return
+	}
+
+	funded, err := isFunded(ctx, opFevm)
+	if err != nil {
+		slog.Warn("failed to check operator balance", logKeyAgent, agent.String(), "error", err)
+		return
+	}
+	aa.set(aa.operatorUnfunded, !funded, agent, fmt.Sprintf("operator %s has no FIL to pay for gas", opFevm))
+}
+
+// rpcError raises an alert when the node or ADO cannot be reached, a nil error
+// resolves it
+func (aa *autopilotAlerts) rpcError(err error) {
+	msg := "RPC provider is reachable"
+	if err != nil {
+		msg = err.Error()
+	}
+	aa.set(aa.rpcUnreachable, err != nil, common.Address{}, msg)
+}
+
+// paymentResult tracks consecutive payment failures, raising an alert once
+// the configured number of payments failed in a row
+func (aa *autopilotAlerts) paymentResult(agent common.Address, err error) {
+	if err == nil {
+		aa.failedPayments = 0
+		aa.set(aa.paymentFailures, false, agent, "payment succeeded")
+		return
+	}
+
+	aa.failedPayments++
+	if aa.failedPayments >= aa.maxFailedPayments {
+		aa.set(aa.paymentFailures, true, agent, fmt.Sprintf("%d payments failed in a row, last error: %s", aa.failedPayments, err))
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// States of the autopilot loop reported by the control socket
+const (
+	autopilotChecking = "checking"
+	autopilotSleeping = "sleeping"
+	autopilotPaused   = "paused"
+)
+
+type autopilotStatus struct {
+	PID         int               `json:"pid"`
+	State       string            `json:"state"`
+	Paused      bool              `json:"paused"`
+	Started     time.Time         `json:"started"`
+	Iterations  uint64            `json:"iterations"`
+	LastCheck   time.Time         `json:"last_check"`
+	NextCheck   time.Time         `json:"next_check"`
+	PaymentDue  bool              `json:"payment_due"`
+	LastError   *autopilotError   `json:"last_error,omitempty"`
+	LastPayment *autopilotPayment `json:"last_payment,omitempty"`
+}
+
+type autopilotError struct {
+	Stage string    `json:"stage"`
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+type autopilotPayment struct {
+	Amount string    `json:"amount"`
+	Epoch  string    `json:"epoch"`
+	Time   time.Time `json:"time"`
+}
+
+type autopilotControlResponse struct {
+	Message string `json:"message"`
+}
+
+// autopilotControl lets other glif processes pause, resume, trigger and stop
+// the autopilot loop through a unix socket in the config directory
+type autopilotControl struct {
+	lk     sync.Mutex
+	paused bool
+
+	runNow   chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	metrics  *autopilotMetrics
+	listener net.Listener
+}
+
+func autopilotSocketPath() string {
+	return filepath.Join(cfgDir, "autopilot.sock")
+}
+
+// listenAutopilotControl serves the control socket. It fails if another
+// autopilot already serves it, and replaces the socket left behind by an
+// autopilot that did not shut down cleanly.
+func listenAutopilotControl(metrics *autopilotMetrics) (*autopilotControl, error) {
+	path := autopilotSocketPath()
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("autopilot is already running, control socket %s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	c := &autopilotControl{
+		runNow:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		metrics:  metrics,
+		listener: listener,
+	}
+
+	go func() {
+		if err := http.Serve(listener, c.handler()); err != nil && !errors.Is(err, net.ErrClosed) {
+			slog.Error("autopilot control socket stopped", "error", err)
+		}
+	}()
+
+	return c, nil
+}
+
+func (c *autopilotControl) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeControlResponse(w, c.status())
+	})
+	mux.HandleFunc("/pause", c.action(func() string {
+		c.setPaused(true)
+		slog.Info("Autopilot paused")
+		return "autopilot paused, payment checks are skipped until it is resumed"
+	}))
+	mux.HandleFunc("/resume", c.action(func() string {
+		c.setPaused(false)
+		slog.Info("Autopilot resumed")
+		return "autopilot resumed"
+	}))
+	mux.HandleFunc("/run-now", c.action(func() string {
+		select {
+		case c.runNow <- struct{}{}:
+		default:
+		}
+		return "payment check requested"
+	}))
+	mux.HandleFunc("/stop", c.action(func() string {
+		c.stopOnce.Do(func() { close(c.stop) })
+		return "autopilot is shutting down"
+	}))
+	return mux
+}
+
+func (c *autopilotControl) action(do func() string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeControlResponse(w, autopilotControlResponse{Message: do()})
+	}
+}
+
+func writeControlResponse(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write control response", "error", err)
+	}
+}
+
+func (c *autopilotControl) status() autopilotStatus {
+	st := c.metrics.status()
+	st.PID = os.Getpid()
+	st.Paused = c.isPaused()
+	if st.State == "" {
+		st.State = autopilotSleeping
+		if st.Paused {
+			st.State = autopilotPaused
+		}
+	}
+	return st
+}
+
+func (c *autopilotControl) setPaused(paused bool) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.paused = paused
+}
+
+func (c *autopilotControl) isPaused() bool {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	return c.paused
+}
+
+// stopped is closed once a stop was requested through the socket
+func (c *autopilotControl) stopped() <-chan struct{} {
+	return c.stop
+}
+
+// sleep waits until the next payment check is due, skipping the checks that
+// fall due while autopilot is paused. A run-now request ends the wait early,
+// even when paused. It returns false when autopilot must shut down.
+func (c *autopilotControl) sleep(interval time.Duration, sigs <-chan os.Signal) bool {
+	for {
+		timer := clock.Timer(interval)
+		select {
+		case <-timer.C:
+			if !c.isPaused() {
+				return true
+			}
+			slog.Info("Autopilot is paused, skipping payment check")
+			c.metrics.loopSkipped(clock.Now().Add(interval))
+		case <-c.runNow:
+			timer.Stop()
+			slog.Info("Payment check requested")
+			return true
+		case <-c.stop:
+			timer.Stop()
+			return false
+		case <-sigs:
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// Close stops serving the control socket and removes it
+func (c *autopilotControl) Close() error {
+	err := c.listener.Close()
+	if rmErr := os.Remove(autopilotSocketPath()); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// autopilotRequest sends a request to the control socket of the running
+// autopilot and decodes its response into out
+func autopilotRequest(ctx context.Context, method, path string, out interface{}) error {
+	socket := autopilotSocketPath()
+	if _, err := os.Stat(socket); os.IsNotExist(err) {
+		return fmt.Errorf("autopilot is not running, no control socket at %s", socket)
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "http://autopilot"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach autopilot on %s: %w", socket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("autopilot: %s", body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// autopilotControlAction posts an action to the control socket of the running
+// autopilot and prints its response
+func autopilotControlAction(cmd *cobra.Command, path string) {
+	var resp autopilotControlResponse
+	if err := autopilotRequest(cmd.Context(), http.MethodPost, path, &resp); err != nil {
+		logFatal(err)
+	}
+	printOutput(resp, func() {
+		fmt.Println(resp.Message)
+	})
+}
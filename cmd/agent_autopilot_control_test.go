@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutopilotControl(t *testing.T) {
+	prevCfgDir := cfgDir
+	cfgDir = t.TempDir()
+	defer func() { cfgDir = prevCfgDir }()
+
+	ctx := context.Background()
+	metrics := newAutopilotMetrics()
+	metrics.recordError(stagePay, errors.New("insufficient funds"))
+
+	control, err := listenAutopilotControl(metrics)
+	require.NoError(t, err)
+	defer control.Close()
+
+	// a second autopilot must not take over the socket
+	_, err = listenAutopilotControl(metrics)
+	assert.Error(t, err)
+
+	var st autopilotStatus
+	require.NoError(t, autopilotRequest(ctx, http.MethodGet, "/status", &st))
+	assert.Equal(t, autopilotSleeping, st.State)
+	require.NotNil(t, st.LastError)
+	assert.Equal(t, "insufficient funds", st.LastError.Error)
+
+	var resp autopilotControlResponse
+	require.NoError(t, autopilotRequest(ctx, http.MethodPost, "/pause", &resp))
+	require.NoError(t, autopilotRequest(ctx, http.MethodGet, "/status", &st))
+	assert.Equal(t, autopilotPaused, st.State)
+
+	// a paused autopilot still runs a requested check
+	require.NoError(t, autopilotRequest(ctx, http.MethodPost, "/run-now", &resp))
+	assert.True(t, control.sleep(time.Hour, make(chan os.Signal)))
+
+	require.NoError(t, autopilotRequest(ctx, http.MethodPost, "/stop", &resp))
+	assert.False(t, control.sleep(time.Hour, make(chan os.Signal)))
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/glifio/go-pools/abigen"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Outcomes of an autopilot cycle
+const (
+	outcomeNothingDue    = "nothing_due"
+	outcomePaid          = "paid"
+	outcomePulledAndPaid = "pulled_and_paid"
+	outcomeDryRun        = "dry_run"
+	outcomeDeferred      = "deferred"
+	outcomePending       = "pending"
+	outcomeFailed        = "failed"
+)
+
+// Exit codes of `glif agent autopilot --once`. Payments exit with a non-zero
+// code so that schedulers can tell them apart from cycles with nothing due,
+// failures exit with a code per loop stage.
+var autopilotExitCodes = map[string]int{
+	outcomeNothingDue:    0,
+	outcomePaid:          2,
+	outcomePulledAndPaid: 3,
+	outcomeDryRun:        4,
+	outcomeDeferred:      5,
+	outcomePending:       6,
+	stageConfig:          10,
+	stageAgent:           11,
+	stageAccount:         12,
+	stageChainHeight:     13,
+	stagePull:            14,
+	stagePay:             15,
+}
+
+// autopilotCycleResult summarizes one check/pull/pay cycle of autopilot
+type autopilotCycleResult struct {
+	Outcome     string                `json:"outcome"`
+	Stage       string                `json:"stage,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	Agent       string                `json:"agent,omitempty"`
+	PaymentType string                `json:"payment_type,omitempty"`
+	Epoch       string                `json:"epoch,omitempty"`
+	EpochsPaid  string                `json:"epochs_paid,omitempty"`
+	PaymentDue  bool                  `json:"payment_due"`
+	Plan        *autopilotPaymentPlan `json:"plan,omitempty"`
+	Gas         string                `json:"gas,omitempty"`
+	Pulled      *FILAmount            `json:"pulled,omitempty"`
+	Paid        *FILAmount            `json:"paid,omitempty"`
+	Tx          string                `json:"tx,omitempty"`
+	Started     time.Time             `json:"started"`
+	Finished    time.Time             `json:"finished"`
+
+	// logger logs the lines of the cycle with the fields of its agent
+	logger *slog.Logger
+}
+
+// autopilotPaymentPlan is the payment a cycle chose to make, and why
+type autopilotPaymentPlan struct {
+	Amount      FILAmount            `json:"amount"`
+	Explanation string               `json:"explanation"`
+	Pull        *FILAmount           `json:"pull,omitempty"`
+	Pulls       []autopilotMinerPull `json:"pulls,omitempty"`
+}
+
+type autopilotMinerPull struct {
+	Miner  string    `json:"miner"`
+	Amount FILAmount `json:"amount"`
+}
+
+// exitCode is the exit code of `glif agent autopilot --once` for the result
+func (r *autopilotCycleResult) exitCode() int {
+	key := r.Outcome
+	if r.Outcome == outcomeFailed {
+		key = r.Stage
+	}
+	if code, ok := autopilotExitCodes[key]; ok {
+		return code
+	}
+	return 1
+}
+
+// fail ends the cycle with an error in the given stage
+func (r *autopilotCycleResult) fail(metrics *autopilotMetrics, stage string, err error) *autopilotCycleResult {
+	r.logger.Error(err.Error(), logKeyStage, stage)
+	metrics.recordError(stage, err)
+	r.Outcome = outcomeFailed
+	r.Stage = stage
+	r.Error = err.Error()
+	r.Finished = clock.Now()
+	return r
+}
+
+// runAutopilotCycle checks whether a payment is due and makes it, pulling funds
+// from a miner first when the agent does not have enough liquid assets. Config
+// values are read on every cycle, so that a running autopilot picks up changes.
+func runAutopilotCycle(cmd *cobra.Command, metrics *autopilotMetrics, alerts *autopilotAlerts) *autopilotCycleResult {
+	ctx := cmd.Context()
+	res := &autopilotCycleResult{Outcome: outcomeNothingDue, Started: clock.Now(), logger: slog.Default()}
+	// a payment that failed before it was submitted must not keep manual
+	// commands from sending transactions
+	defer unlockTxs()
+
+	slog.Info("Checking for payments...")
+	metrics.loopStarted()
+
+	paymentType, err := ParsePaymentType(viper.GetString("autopilot.payment-type"))
+	if err != nil {
+		return res.fail(metrics, stageConfig, err)
+	}
+	res.PaymentType = paymentType.String()
+	payargs := []string{}
+	if paymentType == Principal || paymentType == Custom {
+		amount := viper.GetInt64("autopilot.amount")
+		payargs = append(payargs, fmt.Sprintf("%d", amount))
+	}
+
+	pullFundsEnabled := viper.GetBool("autopilot.pullfunds.enabled")
+	pullFundsFactor := viper.GetInt("autopilot.pullfunds.pull-amount-factor")
+
+	//TODO: maybe change frequency to max debt or max epoch difference
+	frequency := viper.GetFloat64("autopilot.frequency")
+	slog.Debug("Autopilot config", "payment_type", paymentType, "pullfunds", pullFundsEnabled, "pullfunds_factor", pullFundsFactor, "frequency_days", frequency)
+
+	timeout, err := stuckTimeout()
+	if err != nil {
+		return res.fail(metrics, stageConfig, err)
+	}
+
+	agent, err := getAgentAddressWithFlags(cmd)
+	if err != nil {
+		return res.fail(metrics, stageAgent, err)
+	}
+	res.Agent = agent.String()
+	res.logger = res.logger.With(logKeyAgent, res.Agent)
+
+	// a payment sent by an earlier cycle that may still land blocks new ones
+	pending, err := reconcileInflight(cmd, metrics, alerts)
+	if err != nil {
+		alerts.paymentResult(agent, err)
+		return res.fail(metrics, stagePay, err)
+	}
+	if pending {
+		res.Outcome = outcomePending
+		res.Finished = clock.Now()
+		return res
+	}
+
+	account, err := PoolsSDK.Query().InfPoolGetAccount(ctx, agent, nil)
+	if err != nil {
+		alerts.rpcError(err)
+		return res.fail(metrics, stageAccount, err)
+	}
+	if account == (abigen.Account{}) {
+		err = errors.New("failed to get infinity pool account, check evm api provider status")
+		alerts.rpcError(err)
+		return res.fail(metrics, stageAccount, err)
+	}
+	res.EpochsPaid = account.EpochsPaid.String()
+
+	chainHeadHeight, err := PoolsSDK.Query().ChainHeight(ctx)
+	if err != nil {
+		alerts.rpcError(err)
+		return res.fail(metrics, stageChainHeight, err)
+	}
+	if chainHeadHeight == nil {
+		err = errors.New("failed to get chain height, check lotus api provider status")
+		alerts.rpcError(err)
+		return res.fail(metrics, stageChainHeight, err)
+	}
+	alerts.rpcError(nil)
+	res.Epoch = chainHeadHeight.String()
+	res.logger = res.logger.With(logKeyEpoch, res.Epoch)
+
+	if err := checkAgentHealth(ctx, agent, metrics, alerts); err != nil {
+		res.logger.Warn(err.Error(), logKeyStage, stageEcon)
+		metrics.recordError(stageEcon, err)
+	}
+	alerts.checkOperatorFunded(ctx, agent)
+
+	// check if payment is due
+	// if so, make payment
+	res.PaymentDue = paymentDue(frequency, chainHeadHeight, account.EpochsPaid)
+	metrics.recordPaymentDue(res.PaymentDue)
+	if !res.PaymentDue {
+		res.Finished = clock.Now()
+		return res
+	}
+
+	plan, err := planPayment(ctx, cmd, payargs, paymentType)
+	if err != nil {
+		return res.fail(metrics, stagePay, err)
+	}
+	res.logger.Info("Payment is due", logAmount(plan.Amount), "payment_type", paymentType, "explanation", plan.Explanation)
+	res.Plan = &autopilotPaymentPlan{
+		Amount:      NewFILAmount(plan.Amount),
+		Explanation: plan.Explanation,
+	}
+
+	// gas is not worth deferring a payment for when its price cannot be
+	// checked, the error is only reported
+	gas, err := checkGasDeferral(ctx, account.EpochsPaid)
+	if err != nil {
+		res.logger.Warn(err.Error(), logKeyStage, stageGas)
+		metrics.recordError(stageGas, err)
+	} else if gas.Reason != "" {
+		res.logger.Info(gas.Reason, logKeyStage, stageGas)
+		res.Gas = gas.Reason
+	}
+	if gas != nil && gas.Deferred {
+		res.logger.Info("Deferring payment until the base fee drops", logKeyStage, stageGas)
+		res.Outcome = outcomeDeferred
+		res.Finished = clock.Now()
+		return res
+	}
+
+	var pulls []minerPull
+	if pullFundsEnabled {
+		shortfall, err := pullShortfall(cmd, plan.Amount)
+		if err != nil {
+			return res.fail(metrics, stagePull, err)
+		}
+
+		if shortfall.Sign() > 0 {
+			miners, err := autopilotPullMiners(ctx, agent)
+			if err != nil {
+				return res.fail(metrics, stagePull, err)
+			}
+
+			// to save on gas fees, pull a multiple of the payment, but at least
+			// what the agent lacks to make it
+			target := new(big.Int).Mul(plan.Amount, big.NewInt(int64(pullFundsFactor)))
+			target = maxBig(target, shortfall)
+			pulls, err = planPulls(target, shortfall, miners)
+			logPullPlan(res.logger, target, miners, pulls)
+			if err != nil {
+				return res.fail(metrics, stagePull, err)
+			}
+
+			total := big.NewInt(0)
+			for _, p := range pulls {
+				total.Add(total, p.Amount)
+				res.Plan.Pulls = append(res.Plan.Pulls, autopilotMinerPull{Miner: p.Miner.String(), Amount: NewFILAmount(p.Amount)})
+			}
+			pull := NewFILAmount(total)
+			res.Plan.Pull = &pull
+		}
+	}
+
+	if dryRunFlag {
+		res.logger.Info("Dry run, no funds are pulled and no payment is made")
+		res.Outcome = outcomeDryRun
+		res.Finished = clock.Now()
+		return res
+	}
+
+	pulled := big.NewInt(0)
+	for _, p := range pulls {
+		res.logger.Info("Pulling funds from miner", logKeyStage, stagePull, logKeyMiner, p.Miner.String(), logAmount(p.Amount))
+		err := pullFundsFromMiner(cmd, p.Miner, p.Amount)
+		if err != nil {
+			return res.fail(metrics, stagePull, err)
+		}
+		metrics.recordPull(p.Amount)
+
+		pulled.Add(pulled, p.Amount)
+		amt := NewFILAmount(pulled)
+		res.Pulled = &amt
+	}
+
+	res.logger.Info("Making payment", logKeyStage, stagePay, logAmount(plan.Amount))
+	ptx, err := submitPayment(cmd, cmd.Flag("from").Value.String(), plan.Amount, paymentType, "", nil)
+	if err != nil {
+		alerts.paymentResult(agent, err)
+		return res.fail(metrics, stagePay, err)
+	}
+	res.Tx = ptx.tx.Hash().String()
+	res.logger = res.logger.With(logKeyTx, res.Tx)
+	if _, err := newInflight(ptx, account.EpochsPaid); err != nil {
+		res.logger.Error("failed to persist in-flight payment", "error", err)
+	}
+
+	receipt, err := ptx.txj.waitTimeout(ctx, ptx.tx, timeout)
+	if errors.Is(err, errTxPending) {
+		res.logger.Warn(fmt.Sprintf("Payment did not land within %s, it is checked again next cycle", timeout), logKeyStage, stagePay)
+		res.Outcome = outcomePending
+		res.Finished = clock.Now()
+		return res
+	}
+	// a payment without a receipt may still land, it stays in flight for the
+	// next cycle to settle
+	if err == nil || receipt != nil {
+		if err := clearInflight(); err != nil {
+			res.logger.Error("failed to clear in-flight payment", "error", err)
+		}
+	}
+	if err == nil {
+		err = verifyEpochsPaid(ctx, agent, account.EpochsPaid.String(), res.Tx)
+	}
+	if err == nil {
+		res.logger.Info("Payment landed", logKeyStage, stagePay, logAmount(plan.Amount))
+	}
+	alerts.paymentResult(agent, err)
+	if err != nil {
+		return res.fail(metrics, stagePay, err)
+	}
+	paid := plan.Amount
+	metrics.recordPayment(receipt.BlockNumber, paid)
+
+	paidAmt := NewFILAmount(paid)
+	res.Paid = &paidAmt
+	res.Outcome = outcomePaid
+	if res.Pulled != nil {
+		res.Outcome = outcomePulledAndPaid
+	}
+	res.Finished = clock.Now()
+	return res
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/glifio/go-pools/constants"
+	"github.com/spf13/viper"
+)
+
+// gasDeferral is the decision of autopilot to wait for a lower base fee before
+// making a payment that is due
+type gasDeferral struct {
+	Deferred bool
+	Reason   string
+}
+
+// checkGasDeferral compares the current base fee with autopilot.gas.max-base-fee
+// and the epochs left before the payment of the agent is late with
+// autopilot.gas.deadline-margin
+func checkGasDeferral(ctx context.Context, epochsPaid *big.Int) (*gasDeferral, error) {
+	threshold := viper.GetString("autopilot.gas.max-base-fee")
+	if threshold == "" {
+		return &gasDeferral{}, nil
+	}
+	maxBaseFee, err := parseGasPrice(threshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid autopilot.gas.max-base-fee: %w", err)
+	}
+
+	margin := 24 * time.Hour
+	if s := viper.GetString("autopilot.gas.deadline-margin"); s != "" {
+		if margin, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("invalid autopilot.gas.deadline-margin: %w", err)
+		}
+	}
+
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		return nil, err
+	}
+	defer eapi.Close()
+
+	header, err := eapi.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, fmt.Errorf("chain head has no base fee")
+	}
+
+	// the agent is late once its epochs paid fall behind the week one
+	// deadline, two weeks after the default epoch
+	defaultEpoch, err := PoolsSDK.Query().DefaultEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline := new(big.Int).Add(defaultEpoch, big.NewInt(constants.EpochsInWeek*2))
+	epochsLeft := new(big.Int).Sub(epochsPaid, deadline).Int64()
+
+	return deferForGas(header.BaseFee, maxBaseFee, epochsLeft, int64(margin.Seconds())/int64(build.BlockDelaySecs)), nil
+}
+
+// deferForGas defers a payment while the base fee is above maxBaseFee, unless
+// at most marginEpochs are left before the payment deadline
+func deferForGas(baseFee, maxBaseFee *big.Int, epochsLeft, marginEpochs int64) *gasDeferral {
+	if baseFee.Cmp(maxBaseFee) <= 0 {
+		return &gasDeferral{Reason: fmt.Sprintf("base fee of %s attoFIL is within the limit of %s attoFIL", baseFee, maxBaseFee)}
+	}
+	if epochsLeft <= marginEpochs {
+		return &gasDeferral{Reason: fmt.Sprintf(
+			"base fee of %s attoFIL is above the limit of %s attoFIL, but only %d epochs are left before the payment deadline",
+			baseFee, maxBaseFee, epochsLeft,
+		)}
+	}
+	return &gasDeferral{
+		Deferred: true,
+		Reason: fmt.Sprintf(
+			"base fee of %s attoFIL is above the limit of %s attoFIL, %d epochs are left before the payment deadline",
+			baseFee, maxBaseFee, epochsLeft,
+		),
+	}
+}
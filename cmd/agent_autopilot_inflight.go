@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/glifio/glif/v2/events"
+	"github.com/glifio/glif/v2/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// inflightPayment is a payment of autopilot whose transaction has not landed
+// yet. It is persisted so that autopilot never sends a second payment while
+// the first one may still land, even across restarts.
+type inflightPayment struct {
+	Agent   string `json:"agent"`
+	From    string `json:"from"`
+	Nonce   uint64 `json:"nonce"`
+	Amount  string `json:"amount"`
+	PayType string `json:"pay_type"`
+	// EpochsPaid of the account before the payment, which must have moved
+	// once the payment landed
+	EpochsPaid string    `json:"epochs_paid"`
+	Submitted  time.Time `json:"submitted"`
+	// Attempts are the transactions sent with the nonce, the first one and the
+	// replacements with bumped fees
+	Attempts []inflightAttempt `json:"attempts"`
+}
+
+type inflightAttempt struct {
+	Hash      string           `json:"hash"`
+	GasFeeCap string           `json:"gas_fee_cap"`
+	GasTipCap string           `json:"gas_tip_cap"`
+	Event     *events.AgentPay `json:"event"`
+}
+
+func inflightPath() string {
+	return filepath.Join(cfgDir, "autopilot-inflight.json")
+}
+
+// loadInflight returns the in-flight payment, or nil if there is none
+func loadInflight() (*inflightPayment, error) {
+	b, err := os.ReadFile(inflightPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p inflightPayment
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode in-flight payment %s: %w", inflightPath(), err)
+	}
+	return &p, nil
+}
+
+func (p *inflightPayment) save() error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(inflightPath(), b, 0600)
+}
+
+func clearInflight() error {
+	if err := os.Remove(inflightPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// logger logs with the fields of the payment and of its last attempt
+func (p *inflightPayment) logger() *slog.Logger {
+	return slog.With(logKeyAgent, p.Agent, logKeyStage, stagePay, logKeyTx, p.Attempts[len(p.Attempts)-1].Hash)
+}
+
+func (p *inflightPayment) addAttempt(ptx *paymentTx) {
+	p.Submitted = clock.Now()
+	p.Attempts = append(p.Attempts, inflightAttempt{
+		Hash:      ptx.tx.Hash().String(),
+		GasFeeCap: ptx.tx.GasFeeCap().String(),
+		GasTipCap: ptx.tx.GasTipCap().String(),
+		Event:     ptx.evt,
+	})
+}
+
+// newInflight records the payment that was just sent, before autopilot waits
+// for it to land
+func newInflight(ptx *paymentTx, epochsPaid *big.Int) (*inflightPayment, error) {
+	p := &inflightPayment{
+		Agent:      ptx.evt.AgentID,
+		From:       ptx.from.String(),
+		Nonce:      ptx.tx.Nonce(),
+		Amount:     ptx.evt.Amount,
+		PayType:    ptx.evt.PayType,
+		EpochsPaid: epochsPaid.String(),
+	}
+	p.addAttempt(ptx)
+	return p, p.save()
+}
+
+// stuckTimeout is how long autopilot waits for a payment to land before it
+// replaces it with a bumped fee
+func stuckTimeout() (time.Duration, error) {
+	s := viper.GetString("autopilot.gas.stuck-timeout")
+	if s == "" {
+		return 30 * time.Minute, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid autopilot.gas.stuck-timeout: %w", err)
+	}
+	return d, nil
+}
+
+// reconcileInflight settles the in-flight payment left by an earlier cycle. It
+// returns true while the payment is still pending, in which case no other
+// payment must be sent. A payment that landed without moving EpochsPaid, or
+// that reverted, is returned as an error.
+func reconcileInflight(cmd *cobra.Command, metrics *autopilotMetrics, alerts *autopilotAlerts) (bool, error) {
+	ctx := cmd.Context()
+
+	p, err := loadInflight()
+	if err != nil || p == nil {
+		return false, err
+	}
+	if dryRunFlag {
+		p.logger().Info("Dry run, the in-flight payment is not settled")
+		return true, nil
+	}
+
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		return true, err
+	}
+	defer eapi.Close()
+
+	if settled, err := p.settleLanded(ctx, eapi, metrics, alerts); settled || err != nil {
+		return !settled, err
+	}
+
+	// the nonce was used by a transaction autopilot does not know of, unless
+	// one of the attempts landed since its receipt was looked up, or the node
+	// did not index its receipt yet
+	nonce, err := eapi.NonceAt(ctx, common.HexToAddress(p.From), nil)
+	if err != nil {
+		return true, err
+	}
+	if nonce > p.Nonce {
+		if settled, err := p.settleLanded(ctx, eapi, metrics, alerts); settled || err != nil {
+			return !settled, err
+		}
+		paid, err := p.epochsPaidMoved(ctx)
+		if err != nil {
+			return true, err
+		}
+		if paid {
+			p.logger().Info("Epochs paid moved but the receipt of the payment is not indexed yet")
+			return true, nil
+		}
+		p.logger().Warn("Nonce of in-flight payment was used by another transaction", "nonce", p.Nonce)
+		p.recordFailed(-1, fmt.Errorf("nonce %d was used by another transaction", p.Nonce))
+		return false, clearInflight()
+	}
+
+	timeout, err := stuckTimeout()
+	if err != nil {
+		return true, err
+	}
+	if clock.Since(p.Submitted) < timeout {
+		p.logger().Info("Payment is pending", "submitted", p.Submitted.Format(time.RFC3339))
+		return true, nil
+	}
+
+	p.logger().Warn("Payment is stuck, replacing it with a bumped fee", "submitted", p.Submitted.Format(time.RFC3339))
+	return true, p.replace(cmd)
+}
+
+// settleLanded looks up the receipts of the attempts, and settles the payment
+// once one of them landed. It returns whether the payment was settled, an
+// error being returned for a payment that landed but failed.
+func (p *inflightPayment) settleLanded(ctx context.Context, eapi *ethclient.Client, metrics *autopilotMetrics, alerts *autopilotAlerts) (bool, error) {
+	for i, a := range p.Attempts {
+		receipt, err := eapi.TransactionReceipt(ctx, common.HexToHash(a.Hash))
+		if errors.Is(err, ethereum.NotFound) || (err == nil && receipt == nil) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if err := p.landed(ctx, i, receipt); err != nil {
+			return true, err
+		}
+		if amount, ok := new(big.Int).SetString(p.Amount, 10); ok {
+			metrics.recordPayment(receipt.BlockNumber, amount)
+		}
+		alerts.paymentResult(common.HexToAddress(p.Agent), nil)
+		return true, nil
+	}
+	return false, nil
+}
+
+// epochsPaidMoved returns whether the EpochsPaid of the agent's account moved
+// past the value it had before the payment
+func (p *inflightPayment) epochsPaidMoved(ctx context.Context) (bool, error) {
+	prev, ok := new(big.Int).SetString(p.EpochsPaid, 10)
+	if !ok {
+		return false, fmt.Errorf("invalid epochs paid %s", p.EpochsPaid)
+	}
+	account, err := PoolsSDK.Query().InfPoolGetAccount(ctx, common.HexToAddress(p.Agent), nil)
+	if err != nil {
+		return false, err
+	}
+	return account.EpochsPaid.Cmp(prev) > 0, nil
+}
+
+// landed settles the payment once the attempt at index landed with receipt
+func (p *inflightPayment) landed(ctx context.Context, index int, receipt *types.Receipt) error {
+	attempt := p.Attempts[index]
+	p.recordFailed(index, fmt.Errorf("replaced by transaction %s", attempt.Hash))
+
+	txj := resumeTxJournal("agent", "pay", attempt.Event)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		attempt.Event.SetFailed(errors.New("transaction failed"), receipt, "")
+		txj.record()
+		if err := clearInflight(); err != nil {
+			return err
+		}
+		return fmt.Errorf("payment %s failed", attempt.Hash)
+	}
+	txj.confirmed(receipt)
+	p.logger().Info("Payment landed", logKeyTx, attempt.Hash, logKeyEpoch, receipt.BlockNumber.String())
+
+	if err := clearInflight(); err != nil {
+		return err
+	}
+	return verifyEpochsPaid(ctx, common.HexToAddress(p.Agent), p.EpochsPaid, attempt.Hash)
+}
+
+// recordFailed records the attempts other than the one at index as failed
+func (p *inflightPayment) recordFailed(index int, err error) {
+	for i, a := range p.Attempts {
+		if i == index {
+			continue
+		}
+		a.Event.SetFailed(err, nil, "")
+		resumeTxJournal("agent", "pay", a.Event).record()
+	}
+}
+
+// replace sends the payment again with the same nonce and fees bumped by
+// autopilot.gas.fee-bump-percent, which must be enough for the mpool to
+// replace the pending transaction
+func (p *inflightPayment) replace(cmd *cobra.Command) error {
+	last := p.Attempts[len(p.Attempts)-1]
+
+	bump := viper.GetInt64("autopilot.gas.fee-bump-percent")
+	if bump < 25 {
+		bump = 25
+	}
+	feeCap, ok := new(big.Int).SetString(last.GasFeeCap, 10)
+	if !ok {
+		return fmt.Errorf("invalid gas fee cap %s of payment %s", last.GasFeeCap, last.Hash)
+	}
+	tipCap, ok := new(big.Int).SetString(last.GasTipCap, 10)
+	if !ok {
+		return fmt.Errorf("invalid gas tip cap %s of payment %s", last.GasTipCap, last.Hash)
+	}
+	feeCap, tipCap, err := bumpFees(feeCap, tipCap, bump)
+	if err != nil {
+		return fmt.Errorf("cannot replace payment %s: %w", last.Hash, err)
+	}
+
+	amount, ok := new(big.Int).SetString(p.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %s of payment %s", p.Amount, last.Hash)
+	}
+	paymentType, err := ParsePaymentType(p.PayType)
+	if err != nil {
+		return err
+	}
+
+	from := common.HexToAddress(p.From)
+	ptx, err := submitPayment(cmd, from.String(), amount, paymentType, p.Attempts[0].Event.CorrelationID, func(auth *bind.TransactOpts) error {
+		if auth.From != from {
+			return fmt.Errorf("cannot replace payment %s sent by %s from %s", last.Hash, from, auth.From)
+		}
+		auth.Nonce = new(big.Int).SetUint64(p.Nonce)
+		auth.GasFeeCap = feeCap
+		auth.GasTipCap = tipCap
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.logger().Info("Replaced payment", "replacement", ptx.tx.Hash().String())
+
+	p.addAttempt(ptx)
+	return p.save()
+}
+
+// verifyEpochsPaid checks that a payment that landed moved the EpochsPaid of
+// the agent's account past the value it had before the payment
+func verifyEpochsPaid(ctx context.Context, agent common.Address, before string, tx string) error {
+	prev, ok := new(big.Int).SetString(before, 10)
+	if !ok {
+		return fmt.Errorf("invalid epochs paid %s", before)
+	}
+	account, err := PoolsSDK.Query().InfPoolGetAccount(ctx, agent, nil)
+	if err != nil {
+		return err
+	}
+	if account.EpochsPaid.Cmp(prev) <= 0 {
+		return fmt.Errorf("payment %s landed but epochs paid did not move from %s", tx, prev)
+	}
+	slog.Info("Epochs paid moved", logKeyAgent, agent.String(), logKeyTx, tx, "from", prev.String(), "to", account.EpochsPaid.String())
+	return nil
+}
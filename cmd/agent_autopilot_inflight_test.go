@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/glif/v2/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBumpFee(t *testing.T) {
+	assert.Equal(t, big.NewInt(126), bumpFee(big.NewInt(100), 25))
+	// rounds up so that the bump is never below the percentage
+	assert.Equal(t, big.NewInt(5), bumpFee(big.NewInt(3), 25))
+	assert.Equal(t, big.NewInt(1), bumpFee(big.NewInt(0), 25))
+}
+
+func TestInflightPersistence(t *testing.T) {
+	prev := cfgDir
+	cfgDir = t.TempDir()
+	defer func() { cfgDir = prev }()
+
+	p, err := loadInflight()
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     7,
+		GasFeeCap: big.NewInt(200),
+		GasTipCap: big.NewInt(100),
+	})
+	ptx := &paymentTx{
+		tx:   tx,
+		from: common.HexToAddress("0x01"),
+		evt:  &events.AgentPay{AgentID: "0x02", Amount: "1000", PayType: "to-current"},
+	}
+	_, err = newInflight(ptx, big.NewInt(42))
+	assert.NoError(t, err)
+
+	p, err = loadInflight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), p.Nonce)
+	assert.Equal(t, "42", p.EpochsPaid)
+	assert.Len(t, p.Attempts, 1)
+	assert.Equal(t, tx.Hash().String(), p.Attempts[0].Hash)
+	assert.Equal(t, "200", p.Attempts[0].GasFeeCap)
+	assert.Equal(t, "1000", p.Attempts[0].Event.Amount)
+
+	assert.NoError(t, clearInflight())
+	p, err = loadInflight()
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+}
@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
 
 	"github.com/glifio/go-pools/constants"
@@ -22,17 +22,17 @@ var agentAutopilotInfoCmd = &cobra.Command{
 
 		agent, err := getAgentAddressWithFlags(cmd)
 		if err != nil {
-			log.Println(err)
+			slog.Error(err.Error())
 		}
 
 		account, err := PoolsSDK.Query().InfPoolGetAccount(ctx, agent, nil)
 		if err != nil {
-			log.Println(err)
+			slog.Error(err.Error())
 		}
 
 		chainHeadHeight, err := PoolsSDK.Query().ChainHeight(cmd.Context())
 		if err != nil {
-			log.Println(err)
+			slog.Error(err.Error())
 		}
 
 		// calculate epoch frequency
@@ -43,17 +43,42 @@ var agentAutopilotInfoCmd = &cobra.Command{
 		epochFreqInt64, _ := epochFreq.Int64()
 		epochFreqInt := big.NewInt(epochFreqInt64)
 
-		if dueEpoch.Cmp(epochFreqInt) >= 0 {
-			fmt.Println("based on the configured frequenc, a payment is due now")
-		} else {
+		out := &autopilotInfoOutput{
+			Agent:         agent.String(),
+			FrequencyDays: frequency,
+			ChainHeight:   chainHeadHeight.String(),
+			EpochsPaid:    account.EpochsPaid.String(),
+			PaymentDue:    dueEpoch.Cmp(epochFreqInt) >= 0,
+		}
+
+		if !out.PaymentDue {
 			dueIn := new(big.Int).Sub(epochFreqInt, dueEpoch)
 			dueInFloat := new(big.Float).SetInt(dueIn)
-			dueInTime := new(big.Float).Quo(dueInFloat, big.NewFloat(constants.EpochsInMinute))
-			fmt.Printf("Next payment is due in: %0.1f mintues\n", dueInTime)
+			dueInTime, _ := new(big.Float).Quo(dueInFloat, big.NewFloat(constants.EpochsInMinute)).Float64()
+			out.DueInEpochs = dueIn.String()
+			out.DueInMinutes = dueInTime
 		}
+
+		printOutput(out, func() {
+			if out.PaymentDue {
+				fmt.Println("based on the configured frequenc, a payment is due now")
+			} else {
+				fmt.Printf("Next payment is due in: %0.1f mintues\n", out.DueInMinutes)
+			}
+		})
 	},
 }
 
+type autopilotInfoOutput struct {
+	Agent         string  `json:"agent"`
+	FrequencyDays float64 `json:"frequency_days"`
+	ChainHeight   string  `json:"chain_height"`
+	EpochsPaid    string  `json:"epochs_paid"`
+	PaymentDue    bool    `json:"payment_due"`
+	DueInEpochs   string  `json:"due_in_epochs,omitempty"`
+	DueInMinutes  float64 `json:"due_in_minutes,omitempty"`
+}
+
 func init() {
 	agentAutopilotCmd.AddCommand(agentAutopilotInfoCmd)
 	agentAutopilotInfoCmd.Flags().String("agent-addr", "", "Agent address")
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/glifio/go-pools/util"
+)
+
+// Autopilot loop stages that errors are reported under
+const (
+	stageConfig      = "config"
+	stageAgent       = "agent"
+	stageAccount     = "account"
+	stageChainHeight = "chain_height"
+	stageEcon        = "econ"
+	stagePull        = "pull"
+	stagePay         = "pay"
+	stageGas         = "gas"
+)
+
+// autopilotMetrics tracks the state of the autopilot loop and exposes it in the
+// Prometheus text exposition format
+type autopilotMetrics struct {
+	lk sync.Mutex
+
+	started           time.Time
+	iterations        uint64
+	checking          bool
+	lastLoop          time.Time
+	lastAlive         time.Time
+	nextCheck         time.Time
+	paymentDue        bool
+	lastPaymentEpoch  *big.Int
+	lastPaymentTime   time.Time
+	lastPaymentAmount *big.Int
+	paid              *big.Int
+	pulled            *big.Int
+	errors            map[string]uint64
+	lastError         *autopilotError
+
+	liquidAssets *big.Int
+	principal    *big.Int
+	interestOwed *big.Int
+	ltvPercent   float64
+	dtePercent   float64
+}
+
+func newAutopilotMetrics() *autopilotMetrics {
+	return &autopilotMetrics{
+		started: clock.Now(),
+		paid:    big.NewInt(0),
+		pulled:  big.NewInt(0),
+		errors:  map[string]uint64{},
+	}
+}
+
+// recordError counts an error that happened in the given loop stage
+func (m *autopilotMetrics) recordError(stage string, err error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.errors[stage]++
+	m.lastError = &autopilotError{Stage: stage, Error: err.Error(), Time: clock.Now()}
+}
+
+func (m *autopilotMetrics) recordPaymentDue(due bool) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.paymentDue = due
+}
+
+func (m *autopilotMetrics) recordPayment(epoch *big.Int, amount *big.Int) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.lastPaymentEpoch = new(big.Int).Set(epoch)
+	m.lastPaymentTime = clock.Now()
+	m.lastPaymentAmount = new(big.Int).Set(amount)
+	m.paid.Add(m.paid, amount)
+}
+
+func (m *autopilotMetrics) recordPull(amount *big.Int) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.pulled.Add(m.pulled, amount)
+}
+
+func (m *autopilotMetrics) recordEcon(econ *agentEconInfo) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.liquidAssets = filAmountToAtto(econ.LiquidFIL)
+	m.principal = filAmountToAtto(econ.Principal)
+	m.interestOwed = filAmountToAtto(econ.InterestOwed)
+	m.ltvPercent = econ.LTVPercent
+	m.dtePercent = econ.DTEPercent
+}
+
+// loopStarted marks the start of a loop iteration
+func (m *autopilotMetrics) loopStarted() {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.checking = true
+}
+
+// loopCompleted marks the end of a loop iteration, next is the time at which
+// the loop will check for payments again
+func (m *autopilotMetrics) loopCompleted(next time.Time) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.iterations++
+	m.checking = false
+	m.lastLoop = clock.Now()
+	m.lastAlive = m.lastLoop
+	m.nextCheck = next
+}
+
+// loopSkipped records that the check due now was skipped while autopilot is
+// paused, next is the time at which the loop is due again
+func (m *autopilotMetrics) loopSkipped(next time.Time) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.lastAlive = clock.Now()
+	m.nextCheck = next
+}
+
+// status returns a snapshot of the loop state for the control socket
+func (m *autopilotMetrics) status() autopilotStatus {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	st := autopilotStatus{
+		Started:    m.started,
+		Iterations: m.iterations,
+		LastCheck:  m.lastLoop,
+		NextCheck:  m.nextCheck,
+		PaymentDue: m.paymentDue,
+	}
+	if m.lastError != nil {
+		lastError := *m.lastError
+		st.LastError = &lastError
+	}
+	if m.lastPaymentAmount != nil {
+		st.LastPayment = &autopilotPayment{
+			Amount: fmt.Sprintf("%0.09f", util.ToFIL(m.lastPaymentAmount)),
+			Epoch:  m.lastPaymentEpoch.String(),
+			Time:   m.lastPaymentTime,
+		}
+	}
+	if m.checking {
+		st.State = autopilotChecking
+	}
+	return st
+}
+
+// healthy returns an error when the loop has not completed, or skipped a
+// check while paused, within maxAge
+func (m *autopilotMetrics) healthy(now time.Time, maxAge time.Duration) error {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	last := m.lastAlive
+	if last.IsZero() {
+		last = m.started
+	}
+	if age := now.Sub(last); age > maxAge {
+		return fmt.Errorf("autopilot loop has not completed in %s (limit %s)", age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+func (m *autopilotMetrics) writeTo(w io.Writer) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	writeMetric(w, "glif_autopilot_loop_iterations_total", "counter", "Number of completed autopilot loop iterations.", float64(m.iterations))
+	writeMetric(w, "glif_autopilot_last_loop_timestamp_seconds", "gauge", "Unix time of the last completed autopilot loop iteration.", unixSeconds(m.lastLoop))
+	writeMetric(w, "glif_autopilot_next_check_timestamp_seconds", "gauge", "Unix time at which autopilot checks for payments again.", unixSeconds(m.nextCheck))
+	writeMetric(w, "glif_autopilot_payment_due", "gauge", "Whether a payment was due at the last check.", boolToFloat(m.paymentDue))
+
+	var lastPaymentEpoch float64
+	if m.lastPaymentEpoch != nil {
+		lastPaymentEpoch, _ = new(big.Float).SetInt(m.lastPaymentEpoch).Float64()
+	}
+	writeMetric(w, "glif_autopilot_last_payment_epoch", "gauge", "Chain epoch of the last successful payment.", lastPaymentEpoch)
+	writeMetric(w, "glif_autopilot_last_payment_timestamp_seconds", "gauge", "Unix time of the last successful payment.", unixSeconds(m.lastPaymentTime))
+	writeMetric(w, "glif_autopilot_paid_fil_total", "counter", "Amount of FIL paid to the pool.", attoToFloatFIL(m.paid))
+	writeMetric(w, "glif_autopilot_pulled_fil_total", "counter", "Amount of FIL pulled from miners.", attoToFloatFIL(m.pulled))
+
+	fmt.Fprintf(w, "# HELP glif_autopilot_errors_total Number of errors by autopilot loop stage.\n")
+	fmt.Fprintf(w, "# TYPE glif_autopilot_errors_total counter\n")
+	stages := make([]string, 0, len(m.errors))
+	for stage := range m.errors {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	for _, stage := range stages {
+		fmt.Fprintf(w, "glif_autopilot_errors_total{stage=%q} %d\n", stage, m.errors[stage])
+	}
+
+	if m.principal != nil {
+		writeMetric(w, "glif_autopilot_agent_liquid_assets_fil", "gauge", "Liquid FIL held by the agent.", attoToFloatFIL(m.liquidAssets))
+		writeMetric(w, "glif_autopilot_agent_principal_fil", "gauge", "Principal borrowed by the agent.", attoToFloatFIL(m.principal))
+		writeMetric(w, "glif_autopilot_agent_interest_owed_fil", "gauge", "Interest currently owed by the agent.", attoToFloatFIL(m.interestOwed))
+		writeMetric(w, "glif_autopilot_agent_ltv_ratio", "gauge", "Debt-to-liquidation-value ratio of the agent.", m.ltvPercent/100)
+		writeMetric(w, "glif_autopilot_agent_dte_ratio", "gauge", "Debt-to-equity ratio of the agent.", m.dtePercent/100)
+	}
+}
+
+func writeMetric(w io.Writer, name, typ, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+	fmt.Fprintf(w, "%s %v\n", name, value)
+}
+
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixMilli()) / 1000
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func attoToFloatFIL(atto *big.Int) float64 {
+	if atto == nil {
+		return 0
+	}
+	f, _ := util.ToFIL(atto).Float64()
+	return f
+}
+
+func filAmountToAtto(amt FILAmount) *big.Int {
+	atto, ok := new(big.Int).SetString(amt.Atto, 10)
+	if !ok {
+		return big.NewInt(0)
+	}
+	return atto
+}
+
+// serveAutopilotMetrics serves /metrics and /healthz on the listen address.
+// /healthz fails when the loop has not completed within healthIntervals loop
+// intervals.
+func serveAutopilotMetrics(listen string, m *autopilotMetrics, interval time.Duration, healthIntervals int) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.writeTo(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := m.healthy(clock.Now(), time.Duration(healthIntervals)*interval); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	go func() {
+		slog.Info("Serving autopilot metrics", "listen", listen)
+		if err := http.ListenAndServe(listen, mux); err != nil {
+			slog.Error("autopilot metrics server stopped", "error", err)
+		}
+	}()
+}
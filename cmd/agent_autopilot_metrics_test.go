@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	clk "github.com/raulk/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutopilotMetricsHealthy(t *testing.T) {
+	m := newAutopilotMetrics()
+	now := m.started
+
+	assert.NoError(t, m.healthy(now.Add(time.Minute), time.Hour))
+	assert.Error(t, m.healthy(now.Add(2*time.Hour), time.Hour))
+
+	m.loopCompleted(now.Add(3 * time.Hour))
+	assert.NoError(t, m.healthy(m.lastLoop.Add(time.Minute), time.Hour))
+
+	// a paused autopilot skips its checks and stays healthy
+	lastLoop := m.lastLoop
+	mock := clk.NewMock()
+	mock.Set(lastLoop.Add(2 * time.Hour))
+	clock = mock
+	defer func() { clock = clk.New() }()
+	m.loopSkipped(clock.Now().Add(time.Hour))
+	assert.NoError(t, m.healthy(clock.Now().Add(time.Minute), time.Hour))
+	assert.Equal(t, lastLoop, m.status().LastCheck)
+}
+
+func TestAutopilotMetricsWriteTo(t *testing.T) {
+	m := newAutopilotMetrics()
+	m.recordError(stagePay, errors.New("pay failed"))
+	m.recordError(stagePay, errors.New("pay failed"))
+	m.recordError(stageAccount, errors.New("rpc down"))
+	m.recordPull(big.NewInt(3e18))
+	m.recordPayment(big.NewInt(1000), big.NewInt(1e18))
+	m.loopCompleted(time.Now())
+
+	var buf bytes.Buffer
+	m.writeTo(&buf)
+	out := buf.String()
+
+	for _, line := range []string{
+		"glif_autopilot_loop_iterations_total 1",
+		"glif_autopilot_last_payment_epoch 1000",
+		"glif_autopilot_paid_fil_total 1",
+		"glif_autopilot_pulled_fil_total 3",
+		`glif_autopilot_errors_total{stage="account"} 1`,
+		`glif_autopilot_errors_total{stage="pay"} 2`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+
+	// agent gauges are only exposed once the econ state has been fetched
+	assert.False(t, strings.Contains(out, "glif_autopilot_agent_principal_fil"))
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var agentAutopilotPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the payment checks of the running autopilot",
+	Long:  "Pause the payment checks of the running autopilot, e.g. during maintenance of the lotus node. Checks that fall due while paused are skipped until autopilot is resumed.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		autopilotControlAction(cmd, "/pause")
+	},
+}
+
+func init() {
+	agentAutopilotCmd.AddCommand(agentAutopilotPauseCmd)
+}
@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// autopilotProfileEnv is set on the autopilot of a profile, so that it runs the
+// agent of its config directory rather than the profiles of its config
+const autopilotProfileEnv = "GLIF_AUTOPILOT_PROFILE"
+
+// passphraseEnvs are the passphrases of the supervising autopilot, which are
+// never passed on to the autopilot of a profile
+var passphraseEnvs = []string{"GLIF_PASSPHRASE", "GLIF_OWNER_PASSPHRASE", "GLIF_OPERATOR_PASSPHRASE"}
+
+// autopilotProfile is an agent managed by autopilot, with its own config
+// directory holding its wallet, agent, autopilot config and journal
+type autopilotProfile struct {
+	Name           string `mapstructure:"name" json:"name"`
+	ConfigDir      string `mapstructure:"config-dir" json:"config_dir"`
+	From           string `mapstructure:"from" json:"from,omitempty"`
+	Logfile        string `mapstructure:"logfile" json:"logfile,omitempty"`
+	PassphraseEnv  string `mapstructure:"passphrase-env" json:"passphrase_env,omitempty"`
+	PassphraseFile string `mapstructure:"passphrase-file" json:"passphrase_file,omitempty"`
+}
+
+// autopilotProfileResult is the outcome of the cycle of one profile with --once
+type autopilotProfileResult struct {
+	Profile  string                `json:"profile"`
+	ExitCode int                   `json:"exit_code"`
+	Error    string                `json:"error,omitempty"`
+	Result   *autopilotCycleResult `json:"result,omitempty"`
+}
+
+// autopilotProfiles returns the profiles of the [[autopilot.profiles]] config
+// sections, or none when this autopilot runs a profile itself
+func autopilotProfiles() ([]autopilotProfile, error) {
+	if os.Getenv(autopilotProfileEnv) != "" {
+		return nil, nil
+	}
+
+	var profiles []autopilotProfile
+	if err := viper.UnmarshalKey("autopilot.profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("invalid autopilot.profiles: %w", err)
+	}
+	for i := range profiles {
+		// relative config directories are relative to the config directory
+		// of the supervising autopilot
+		if dir := profiles[i].ConfigDir; dir != "" && !filepath.IsAbs(dir) {
+			profiles[i].ConfigDir = filepath.Join(cfgDir, dir)
+		}
+	}
+	if err := validateAutopilotProfiles(profiles, cfgDir); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// validateAutopilotProfiles checks that every profile is named and has its own
+// config directory, since two autopilots cannot share one
+func validateAutopilotProfiles(profiles []autopilotProfile, ownDir string) error {
+	names := map[string]bool{}
+	dirs := map[string]string{}
+	for i, p := range profiles {
+		if p.Name == "" {
+			return fmt.Errorf("autopilot profile %d has no name", i+1)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("autopilot profile %s is defined twice", p.Name)
+		}
+		names[p.Name] = true
+
+		if p.ConfigDir == "" {
+			return fmt.Errorf("autopilot profile %s has no config-dir", p.Name)
+		}
+		dir := filepath.Clean(p.ConfigDir)
+		if dir == filepath.Clean(ownDir) {
+			return fmt.Errorf("autopilot profile %s cannot use the config directory of the supervising autopilot", p.Name)
+		}
+		if other, ok := dirs[dir]; ok {
+			return fmt.Errorf("autopilot profiles %s and %s use the same config directory %s", other, p.Name, dir)
+		}
+		dirs[dir] = p.Name
+
+		if p.PassphraseEnv != "" && p.PassphraseFile != "" {
+			return fmt.Errorf("autopilot profile %s sets both passphrase-env and passphrase-file", p.Name)
+		}
+	}
+	return nil
+}
+
+// passphrase reads the passphrase of the profile's keys from its source. It is
+// empty when the profile has no passphrase source.
+func (p autopilotProfile) passphrase() (string, error) {
+	switch {
+	case p.PassphraseEnv != "":
+		passphrase, ok := os.LookupEnv(p.PassphraseEnv)
+		if !ok {
+			return "", fmt.Errorf("passphrase variable %s of autopilot profile %s is not set", p.PassphraseEnv, p.Name)
+		}
+		return passphrase, nil
+	case p.PassphraseFile != "":
+		b, err := os.ReadFile(p.PassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase of autopilot profile %s: %w", p.Name, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	return "", nil
+}
+
+// environ is the environment of the profile's autopilot. The passphrases of
+// the supervising autopilot and of the other profiles are removed from base,
+// and the profile's passphrase is passed for both its owner and operator keys.
+func (p autopilotProfile) environ(base []string, profiles []autopilotProfile, passphrase string) []string {
+	hidden := map[string]bool{"GLIF_CONFIG_DIR": true, autopilotProfileEnv: true}
+	for _, name := range passphraseEnvs {
+		hidden[name] = true
+	}
+	for _, other := range profiles {
+		if other.PassphraseEnv != "" {
+			hidden[other.PassphraseEnv] = true
+		}
+	}
+
+	env := make([]string, 0, len(base)+4)
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if !hidden[name] {
+			env = append(env, kv)
+		}
+	}
+	env = append(env, "GLIF_CONFIG_DIR="+p.ConfigDir, autopilotProfileEnv+"="+p.Name)
+	if p.PassphraseEnv != "" || p.PassphraseFile != "" {
+		env = append(env, "GLIF_OWNER_PASSPHRASE="+passphrase, "GLIF_OPERATOR_PASSPHRASE="+passphrase)
+	}
+	return env
+}
+
+// args are the arguments of the profile's autopilot, which inherits the flags
+// of the supervising autopilot that apply to every agent
+func (p autopilotProfile) args(cmd *cobra.Command, once bool) []string {
+	args := []string{"agent", "autopilot", "--config-dir", p.ConfigDir}
+	if p.From != "" {
+		args = append(args, "--from", p.From)
+	}
+	if p.Logfile != "" {
+		args = append(args, "--log-file", p.Logfile)
+	}
+	args = append(args, "--log-level", logLevel.String(), "--log-format", logFormat)
+	if once {
+		args = append(args, "--once", "--output", "json")
+	}
+	for _, name := range []string{"dry-run", "debug", "max-fee", "max-priority-fee"} {
+		if f := cmd.Flag(name); f != nil && f.Changed {
+			args = append(args, "--"+name+"="+f.Value.String())
+		}
+	}
+	return args
+}
+
+// command prepares the autopilot process of the profile, whose log lines go to
+// the log of this autopilot unless it logs to its own file
+func (p autopilotProfile) command(cmd *cobra.Command, profiles []autopilotProfile, once bool) (*exec.Cmd, error) {
+	passphrase, err := p.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	c := exec.Command(exe, p.args(cmd, once)...)
+	c.Env = p.environ(os.Environ(), profiles, passphrase)
+	c.Stdout = os.Stdout
+	c.Stderr = newProfileLogWriter()
+	return c, nil
+}
+
+// newProfileLogWriter copies the lines of a profile's autopilot to the log
+// output of this autopilot. The lines are already formatted and carry the
+// profile field, they are written whole so that the lines of concurrent
+// profiles do not interleave.
+func newProfileLogWriter() io.WriteCloser {
+	r, w := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			logOutput.Write(append(scanner.Bytes(), '\n'))
+		}
+		r.CloseWithError(scanner.Err())
+	}()
+	return w
+}
+
+// runAutopilotProfiles runs an autopilot per profile until autopilot is shut
+// down. An autopilot that exits is restarted, without affecting the others.
+func runAutopilotProfiles(cmd *cobra.Command, profiles []autopilotProfile) {
+	pidLock, err := lockAutopilot()
+	if err != nil {
+		logFatal(err)
+	}
+	defer pidLock.Unlock()
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		slog.Info("Shutting down...")
+		cancel()
+	}()
+
+	slog.Info("Starting autopilot...", "profiles", len(profiles))
+
+	var wg sync.WaitGroup
+	for _, p := range profiles {
+		wg.Add(1)
+		go func(p autopilotProfile) {
+			defer wg.Done()
+			superviseAutopilotProfile(ctx, cmd, p, profiles)
+		}(p)
+	}
+	wg.Wait()
+	Exit(0)
+}
+
+// superviseAutopilotProfile keeps the autopilot of a profile running, waiting
+// longer between restarts while it keeps exiting soon after starting
+func superviseAutopilotProfile(ctx context.Context, cmd *cobra.Command, p autopilotProfile, profiles []autopilotProfile) {
+	const minBackoff = 10 * time.Second
+	backoff := minBackoff
+
+	for {
+		started := time.Now()
+		err := runAutopilotProfile(ctx, cmd, p, profiles)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) > time.Minute {
+			backoff = minBackoff
+		}
+		slog.Warn("Autopilot of profile exited, restarting it", logKeyProfile, p.Name, "error", err, "backoff", backoff.String())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > autopilotInterval() {
+			backoff = autopilotInterval()
+		}
+	}
+}
+
+// runAutopilotProfile runs the autopilot of a profile until it exits, or until
+// ctx is done, on which it is asked to shut down
+func runAutopilotProfile(ctx context.Context, cmd *cobra.Command, p autopilotProfile, profiles []autopilotProfile) error {
+	c, err := p.command(cmd, profiles, false)
+	if err != nil {
+		return err
+	}
+	defer c.Stderr.(io.Closer).Close()
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+	slog.Info("Started autopilot of profile", logKeyProfile, p.Name, "pid", c.Process.Pid, "config_dir", p.ConfigDir)
+
+	done := make(chan error, 1)
+	go func() { done <- c.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// autopilot shuts down once its current check completed
+		c.Process.Signal(syscall.SIGTERM)
+		return <-done
+	}
+}
+
+// autopilotProfilesOnce runs a single cycle for every profile concurrently,
+// prints their summaries and exits with the code of their combined outcome
+func autopilotProfilesOnce(cmd *cobra.Command, profiles []autopilotProfile) {
+	if outputFormat() == OutputTable {
+		outputFlag = string(OutputJSON)
+	}
+
+	pidLock, err := lockAutopilot()
+	if err != nil {
+		logFatal(err)
+	}
+	defer pidLock.Unlock()
+
+	results := make([]autopilotProfileResult, len(profiles))
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		wg.Add(1)
+		go func(i int, p autopilotProfile) {
+			defer wg.Done()
+			results[i] = runAutopilotProfileOnce(cmd, p, profiles)
+		}(i, p)
+	}
+	wg.Wait()
+
+	printOutput(results, nil)
+	Exit(autopilotProfilesExitCode(results))
+}
+
+func runAutopilotProfileOnce(cmd *cobra.Command, p autopilotProfile, profiles []autopilotProfile) autopilotProfileResult {
+	res := autopilotProfileResult{Profile: p.Name, ExitCode: 1}
+
+	c, err := p.command(cmd, profiles, true)
+	if err != nil {
+		slog.Error("Autopilot of profile failed", logKeyProfile, p.Name, "error", err)
+		res.Error = err.Error()
+		return res
+	}
+	defer c.Stderr.(io.Closer).Close()
+
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+	err = c.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		res.ExitCode = 0
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	default:
+		slog.Error("Autopilot of profile failed", logKeyProfile, p.Name, "error", err)
+		res.Error = err.Error()
+		return res
+	}
+
+	var cycle autopilotCycleResult
+	if err := json.Unmarshal(stdout.Bytes(), &cycle); err != nil {
+		res.Error = fmt.Sprintf("autopilot of profile %s exited with code %d without a summary", p.Name, res.ExitCode)
+		return res
+	}
+	res.Result = &cycle
+	res.Error = cycle.Error
+	return res
+}
+
+// autopilotProfilesExitCode combines the exit codes of the profiles' cycles.
+// The first failure in the order of the profiles wins, otherwise the highest
+// code, so that a payment of any profile is reported.
+func autopilotProfilesExitCode(results []autopilotProfileResult) int {
+	code := 0
+	for _, r := range results {
+		if r.ExitCode == 1 || r.ExitCode >= autopilotExitCodes[stageConfig] {
+			return r.ExitCode
+		}
+		if r.ExitCode > code {
+			code = r.ExitCode
+		}
+	}
+	return code
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAutopilotProfiles(t *testing.T) {
+	valid := []autopilotProfile{
+		{Name: "a", ConfigDir: "/glif/a"},
+		{Name: "b", ConfigDir: "/glif/b", PassphraseEnv: "B_PASSPHRASE"},
+	}
+	assert.NoError(t, validateAutopilotProfiles(valid, "/glif"))
+
+	tests := map[string][]autopilotProfile{
+		"no name":           {{ConfigDir: "/glif/a"}},
+		"duplicate name":    {{Name: "a", ConfigDir: "/glif/a"}, {Name: "a", ConfigDir: "/glif/b"}},
+		"no config dir":     {{Name: "a"}},
+		"shared config dir": {{Name: "a", ConfigDir: "/glif/a"}, {Name: "b", ConfigDir: "/glif/a/"}},
+		"own config dir":    {{Name: "a", ConfigDir: "/glif"}},
+		"two passphrases":   {{Name: "a", ConfigDir: "/glif/a", PassphraseEnv: "A", PassphraseFile: "/a"}},
+	}
+	for name, profiles := range tests {
+		assert.Error(t, validateAutopilotProfiles(profiles, "/glif"), name)
+	}
+}
+
+func TestAutopilotProfileEnviron(t *testing.T) {
+	profiles := []autopilotProfile{
+		{Name: "a", ConfigDir: "/glif/a", PassphraseEnv: "A_PASSPHRASE"},
+		{Name: "b", ConfigDir: "/glif/b", PassphraseEnv: "B_PASSPHRASE"},
+		{Name: "c", ConfigDir: "/glif/c"},
+	}
+	base := []string{
+		"PATH=/bin",
+		"GLIF_CONFIG_DIR=/glif",
+		"GLIF_OPERATOR_PASSPHRASE=supervisor",
+		"A_PASSPHRASE=secret-a",
+		"B_PASSPHRASE=secret-b",
+	}
+
+	// the profile only sees its own passphrase, under the names glif reads
+	assert.Equal(t, []string{
+		"PATH=/bin",
+		"GLIF_CONFIG_DIR=/glif/a",
+		autopilotProfileEnv + "=a",
+		"GLIF_OWNER_PASSPHRASE=secret-a",
+		"GLIF_OPERATOR_PASSPHRASE=secret-a",
+	}, profiles[0].environ(base, profiles, "secret-a"))
+
+	// a profile without a passphrase source gets no passphrase at all
+	assert.Equal(t, []string{
+		"PATH=/bin",
+		"GLIF_CONFIG_DIR=/glif/c",
+		autopilotProfileEnv + "=c",
+	}, profiles[2].environ(base, profiles, ""))
+}
+
+func TestAutopilotProfilesExitCode(t *testing.T) {
+	results := func(codes ...int) []autopilotProfileResult {
+		var r []autopilotProfileResult
+		for _, c := range codes {
+			r = append(r, autopilotProfileResult{ExitCode: c})
+		}
+		return r
+	}
+
+	assert.Equal(t, 0, autopilotProfilesExitCode(nil))
+	assert.Equal(t, 0, autopilotProfilesExitCode(results(0, 0)))
+	assert.Equal(t, 3, autopilotProfilesExitCode(results(2, 0, 3)))
+	assert.Equal(t, 14, autopilotProfilesExitCode(results(2, 14, 15)))
+	assert.Equal(t, 1, autopilotProfilesExitCode(results(6, 1, 3)))
+}
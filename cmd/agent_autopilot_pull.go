@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+	ltypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/spf13/viper"
+)
+
+// pullMiner is a miner autopilot may pull from, with its available balance
+// and the reserve that is left on it
+type pullMiner struct {
+	Miner     address.Address
+	Available *big.Int
+	Reserve   *big.Int
+}
+
+// pullable is the part of the available balance above the reserve
+func (m pullMiner) pullable() *big.Int {
+	p := new(big.Int).Sub(m.Available, m.Reserve)
+	if p.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return p
+}
+
+// minerPull is an amount autopilot pulls from a miner
+type minerPull struct {
+	Miner  address.Address
+	Amount *big.Int
+}
+
+// autopilotPullMiners returns the miners autopilot may pull from, with their
+// available balance and reserve. These are the miners of autopilot.pullfunds.miners,
+// or of the legacy autopilot.pullfunds.miner, or all miners of the agent when
+// neither is set.
+func autopilotPullMiners(ctx context.Context, agent common.Address) ([]pullMiner, error) {
+	names := viper.GetStringSlice("autopilot.pullfunds.miners")
+	if len(names) == 0 && viper.GetString("autopilot.pullfunds.miner") != "" {
+		names = []string{viper.GetString("autopilot.pullfunds.miner")}
+	}
+
+	var miners []address.Address
+	if len(names) == 0 {
+		list, err := PoolsSDK.Query().AgentMiners(ctx, agent, nil)
+		if err != nil {
+			return nil, err
+		}
+		miners = list
+	}
+	for _, name := range names {
+		miner, err := ToMinerID(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid miner %s in autopilot.pullfunds: %w", name, err)
+		}
+		miners = append(miners, miner)
+	}
+	if len(miners) == 0 {
+		return nil, fmt.Errorf("agent %s has no miners to pull funds from", agent)
+	}
+
+	defaultReserve, err := parseReserve(viper.GetString("autopilot.pullfunds.reserve"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid autopilot.pullfunds.reserve: %w", err)
+	}
+	reserves := viper.GetStringMapString("autopilot.pullfunds.reserves")
+
+	lapi, closer, err := PoolsSDK.Extern().ConnectLotusClient()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	balances := make([]pullMiner, 0, len(miners))
+	for _, miner := range miners {
+		available, err := lapi.StateMinerAvailableBalance(ctx, miner, ltypes.EmptyTSK)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get available balance of miner %s: %w", miner, err)
+		}
+
+		if available.Int == nil {
+			available = ltypes.NewInt(0)
+		}
+
+		reserve := defaultReserve
+		if r, ok := reserves[strings.ToLower(miner.String())]; ok {
+			if reserve, err = parseReserve(r); err != nil {
+				return nil, fmt.Errorf("invalid autopilot.pullfunds.reserves of miner %s: %w", miner, err)
+			}
+		}
+
+		balances = append(balances, pullMiner{
+			Miner:     miner,
+			Available: available.Int,
+			Reserve:   reserve,
+		})
+	}
+	return balances, nil
+}
+
+// planPulls splits the target amount across the miners, pulling from the
+// miners with the most FIL above their reserve first so that the payment
+// needs as few transactions as possible. Miners that cannot cover the target
+// together are pulled up to their reserve, it is an error if that does not
+// cover the minimum.
+func planPulls(target, minimum *big.Int, miners []pullMiner) ([]minerPull, error) {
+	sorted := make([]pullMiner, len(miners))
+	copy(sorted, miners)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].pullable().Cmp(sorted[j].pullable()) > 0
+	})
+
+	var pulls []minerPull
+	left := new(big.Int).Set(target)
+	for _, m := range sorted {
+		if left.Sign() <= 0 {
+			break
+		}
+		amount := m.pullable()
+		if amount.Sign() == 0 {
+			continue
+		}
+		if amount.Cmp(left) > 0 {
+			amount = new(big.Int).Set(left)
+		}
+		pulls = append(pulls, minerPull{Miner: m.Miner, Amount: amount})
+		left.Sub(left, amount)
+	}
+
+	total := new(big.Int).Sub(target, left)
+	if total.Cmp(minimum) < 0 {
+		return nil, fmt.Errorf("miners can only cover %s FIL above their reserves, the payment needs %s FIL", formatFIL(total), formatFIL(minimum))
+	}
+	return pulls, nil
+}
+
+// logPullPlan logs the balance of every miner and the pulls autopilot chose
+func logPullPlan(logger *slog.Logger, target *big.Int, miners []pullMiner, pulls []minerPull) {
+	amounts := map[address.Address]*big.Int{}
+	for _, p := range pulls {
+		amounts[p.Miner] = p.Amount
+	}
+
+	logger.Info("Pull plan", logKeyStage, stagePull, logAmount(target))
+	for _, m := range miners {
+		amount, ok := amounts[m.Miner]
+		if !ok {
+			amount = big.NewInt(0)
+		}
+		logger.Info("Pull plan of miner", logKeyStage, stagePull, logKeyMiner, m.Miner.String(),
+			"available_atto", m.Available.String(), "reserve_atto", m.Reserve.String(), logAmount(amount))
+	}
+}
+
+func parseReserve(s string) (*big.Int, error) {
+	if s == "" {
+		return big.NewInt(0), nil
+	}
+	return parseFILAmount(s)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPullMiners(t *testing.T) []pullMiner {
+	miner := func(id uint64) address.Address {
+		a, err := address.NewIDAddress(id)
+		assert.NoError(t, err)
+		return a
+	}
+	return []pullMiner{
+		{Miner: miner(1001), Available: fil(10), Reserve: fil(5)},
+		{Miner: miner(1002), Available: fil(50), Reserve: fil(10)},
+		{Miner: miner(1003), Available: fil(2), Reserve: fil(5)},
+	}
+}
+
+func TestPlanPulls(t *testing.T) {
+	miners := testPullMiners(t)
+
+	// the miner with the most FIL above its reserve covers the target alone
+	pulls, err := planPulls(fil(30), fil(10), miners)
+	assert.NoError(t, err)
+	assert.Equal(t, []minerPull{{Miner: miners[1].Miner, Amount: fil(30)}}, pulls)
+
+	// the target is split across miners
+	pulls, err = planPulls(fil(42), fil(10), miners)
+	assert.NoError(t, err)
+	assert.Equal(t, []minerPull{
+		{Miner: miners[1].Miner, Amount: fil(40)},
+		{Miner: miners[0].Miner, Amount: fil(2)},
+	}, pulls)
+
+	// miners are pulled down to their reserves when they cannot cover the
+	// target but cover the minimum
+	pulls, err = planPulls(fil(100), fil(45), miners)
+	assert.NoError(t, err)
+	assert.Len(t, pulls, 2)
+	assert.Equal(t, fil(5), pulls[1].Amount)
+
+	// reserves are never pulled to cover the minimum
+	_, err = planPulls(fil(100), fil(46), miners)
+	assert.Error(t, err)
+}
+
+func TestPlanPullsNoMiners(t *testing.T) {
+	_, err := planPulls(fil(1), big.NewInt(1), nil)
+	assert.Error(t, err)
+}
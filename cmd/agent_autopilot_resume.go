@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var agentAutopilotResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the payment checks of a paused autopilot",
+	Long:  "Resume the payment checks of a paused autopilot. The next check runs at its scheduled time, use run-now to check right away.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		autopilotControlAction(cmd, "/resume")
+	},
+}
+
+func init() {
+	agentAutopilotCmd.AddCommand(agentAutopilotResumeCmd)
+}
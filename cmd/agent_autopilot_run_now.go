@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var agentAutopilotRunNowCmd = &cobra.Command{
+	Use:   "run-now",
+	Short: "Make the running autopilot check for payments now",
+	Long:  "Make the running autopilot check for payments now instead of at its next scheduled check, even when it is paused.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		autopilotControlAction(cmd, "/run-now")
+	},
+}
+
+func init() {
+	agentAutopilotCmd.AddCommand(agentAutopilotRunNowCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var agentAutopilotStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of the running autopilot",
+	Long:  "Show the state of the running autopilot: whether it is checking, sleeping or paused, when it checks for payments next, and its last error and payment.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var st autopilotStatus
+		if err := autopilotRequest(cmd.Context(), http.MethodGet, "/status", &st); err != nil {
+			logFatal(err)
+		}
+
+		printOutput(st, func() {
+			fmt.Printf("State: %s (pid %d)\n", st.State, st.PID)
+			fmt.Printf("Running since: %s\n", formatStatusTime(st.Started))
+			fmt.Printf("Payment checks: %d\n", st.Iterations)
+			fmt.Printf("Last check: %s\n", formatStatusTime(st.LastCheck))
+			fmt.Printf("Next check: %s\n", formatStatusTime(st.NextCheck))
+			fmt.Printf("Payment due: %t\n", st.PaymentDue)
+			if st.LastPayment != nil {
+				fmt.Printf("Last payment: %s FIL at epoch %s (%s)\n", st.LastPayment.Amount, st.LastPayment.Epoch, formatStatusTime(st.LastPayment.Time))
+			} else {
+				fmt.Println("Last payment: none")
+			}
+			if st.LastError != nil {
+				fmt.Printf("Last error: [%s] %s (%s)\n", st.LastError.Stage, st.LastError.Error, formatStatusTime(st.LastError.Time))
+			} else {
+				fmt.Println("Last error: none")
+			}
+		})
+	},
+}
+
+func formatStatusTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func init() {
+	agentAutopilotCmd.AddCommand(agentAutopilotStatusCmd)
+}
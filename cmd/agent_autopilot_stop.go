@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var agentAutopilotStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Shut down the running autopilot",
+	Long:  "Shut down the running autopilot once its current payment check, if any, completed.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		autopilotControlAction(cmd, "/stop")
+	},
+}
+
+func init() {
+	agentAutopilotCmd.AddCommand(agentAutopilotStopCmd)
+}
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
+	"time"
+
+	"github.com/glifio/glif/v2/events"
+	"github.com/glifio/glif/v2/fakesdk"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPaymentDue(t *testing.T) {
@@ -47,3 +53,164 @@ func Test_paymentDue(t *testing.T) {
 		})
 	}
 }
+
+func Test_autopilotCycleExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		res  autopilotCycleResult
+		want int
+	}{
+		{"nothing due", autopilotCycleResult{Outcome: outcomeNothingDue}, 0},
+		{"paid", autopilotCycleResult{Outcome: outcomePaid}, 2},
+		{"pulled and paid", autopilotCycleResult{Outcome: outcomePulledAndPaid}, 3},
+		{"config", autopilotCycleResult{Outcome: outcomeFailed, Stage: stageConfig}, 10},
+		{"pull", autopilotCycleResult{Outcome: outcomeFailed, Stage: stagePull}, 14},
+		{"pay", autopilotCycleResult{Outcome: outcomeFailed, Stage: stagePay}, 15},
+		{"unknown stage", autopilotCycleResult{Outcome: outcomeFailed, Stage: "unknown"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.res.exitCode(); got != tt.want {
+				t.Errorf("exitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// runAutopilotOnce runs `glif agent autopilot --once` and decodes its summary
+func runAutopilotOnce(t *testing.T, env *testEnv, args ...string) (int, autopilotCycleResult) {
+	res := env.run(append([]string{"agent", "autopilot", "--once"}, args...)...)
+	var summary autopilotCycleResult
+	assert.NoError(t, json.Unmarshal([]byte(res.stdout), &summary), res.stdout)
+	return res.code, summary
+}
+
+func TestAutopilotOnce(t *testing.T) {
+	t.Run("nothing due", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.agent.Account.EpochsPaid = new(big.Int).Sub(env.sdk.Height, big.NewInt(2880))
+
+		code, summary := runAutopilotOnce(t, env)
+		assert.Equal(t, 0, code)
+		assert.Equal(t, outcomeNothingDue, summary.Outcome)
+		assert.Empty(t, env.sdk.Txs)
+	})
+
+	t.Run("paid", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		code, summary := runAutopilotOnce(t, env)
+		assert.Equal(t, 2, code)
+		assert.Equal(t, outcomePaid, summary.Outcome)
+		assert.Equal(t, fil(1).String(), summary.Paid.Atto)
+		assert.Len(t, env.sdk.Sent("AgentPay"), 1)
+		assert.Empty(t, env.sdk.Sent("AgentPullFunds"))
+		assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "pay"))
+	})
+
+	t.Run("pulled and paid", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.agent.LiquidAssets = big.NewInt(0)
+
+		code, summary := runAutopilotOnce(t, env)
+		assert.Equal(t, 3, code)
+		assert.Equal(t, outcomePulledAndPaid, summary.Outcome)
+		// the payment times the pull-amount-factor
+		if pulls := env.sdk.Sent("AgentPullFunds"); assert.Len(t, pulls, 1) {
+			assert.Equal(t, env.miner.ID, pulls[0].Miner)
+			assert.Equal(t, fil(3), pulls[0].Amount)
+		}
+		assert.Equal(t, fil(7), env.miner.Available)
+		assert.Equal(t, fil(2), env.agent.LiquidAssets)
+		assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "pull"))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		code, summary := runAutopilotOnce(t, env, "--dry-run")
+		assert.Equal(t, 4, code)
+		assert.Equal(t, outcomeDryRun, summary.Outcome)
+		assert.Empty(t, env.sdk.Txs)
+	})
+
+	t.Run("payment fails", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.sdk.Errors["AgentPay"] = fakesdk.ErrNotImplemented
+
+		code, summary := runAutopilotOnce(t, env)
+		assert.Equal(t, 15, code)
+		assert.Equal(t, stagePay, summary.Stage)
+		assert.Equal(t, []string{events.StatusFailed}, env.journalStatuses("agent", "pay"))
+	})
+}
+
+func TestAutopilotReplacesStuckPayment(t *testing.T) {
+	env := newTestEnv(t)
+	env.sdk.HoldTxs = true
+	// the payment does not land before the stuck timeout
+	env.sdk.OnWait = func(*fakesdk.Tx) { env.clock.Add(30 * time.Minute) }
+
+	code, summary := runAutopilotOnce(t, env)
+	assert.Equal(t, 6, code)
+	assert.Equal(t, outcomePending, summary.Outcome)
+
+	// the next cycle replaces it with the same nonce and bumped fees
+	code, _ = runAutopilotOnce(t, env)
+	assert.Equal(t, 6, code)
+	txs := env.sdk.Sent("AgentPay")
+	if !assert.Len(t, txs, 2) {
+		return
+	}
+	assert.True(t, txs[0].Replaced)
+	assert.Equal(t, txs[0].Tx.Nonce(), txs[1].Tx.Nonce())
+	assert.Equal(t, 1, txs[1].Tx.GasFeeCap().Cmp(txs[0].Tx.GasFeeCap()))
+
+	// once the replacement landed, the cycle after settles it and finds
+	// nothing due
+	env.sdk.HoldTxs = false
+	env.sdk.Mine()
+	code, summary = runAutopilotOnce(t, env)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, outcomeNothingDue, summary.Outcome)
+	assert.Len(t, env.sdk.Sent("AgentPay"), 2)
+	assert.Equal(t, []string{
+		events.StatusSubmitted, events.StatusSubmitted, events.StatusFailed, events.StatusConfirmed,
+	}, env.journalStatuses("agent", "pay"))
+
+	// the attempts are journaled as one payment
+	ids := map[string]bool{}
+	for _, evt := range env.journalEvents() {
+		if evt.EventType.Event == "pay" {
+			id, _ := evt.Data.(map[string]interface{})["correlation_id"].(string)
+			ids[id] = true
+		}
+	}
+	assert.Len(t, ids, 1)
+	assert.False(t, ids[""])
+}
+
+func TestAutopilotSettlesPaymentWithLateReceipt(t *testing.T) {
+	env := newTestEnv(t)
+	env.sdk.HoldTxs = true
+	env.sdk.OnWait = func(*fakesdk.Tx) { env.clock.Add(30 * time.Minute) }
+
+	code, _ := runAutopilotOnce(t, env)
+	assert.Equal(t, 6, code)
+
+	// the payment landed, moving the nonce, but the node does not find its
+	// receipt until the nonce was read
+	env.sdk.HoldTxs = false
+	env.sdk.Mine()
+	env.sdk.UnindexedReceipts = 2
+	code, summary := runAutopilotOnce(t, env)
+	assert.Equal(t, 6, code)
+	assert.Equal(t, outcomePending, summary.Outcome)
+
+	env.sdk.UnindexedReceipts = 1
+	code, summary = runAutopilotOnce(t, env)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, outcomeNothingDue, summary.Outcome)
+	assert.Len(t, env.sdk.Sent("AgentPay"), 1)
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "pay"))
+}
@@ -48,25 +48,27 @@ var borrowCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		borrowevt := journal.RegisterEventType("agent", "borrow")
 		evt := &events.AgentBorrow{
 			AgentID: agentAddr.String(),
 			PoolID:  poolID.String(),
 			Amount:  amount.String(),
 		}
+		txj := newTxJournal("agent", "borrow", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(borrowevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentBorrow(cmd.Context(), auth, agentAddr, poolID, amount, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
+		}
+		if unsignedOutFlag != "" {
+			s.Stop()
+			writeUnsignedTx(txj, tx, auth.From, fmt.Sprintf("Borrow %0.08f FIL from the %s into agent %s", denoms.ToFIL(amount), poolName, agentAddr))
+			return
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -78,5 +80,6 @@ var borrowCmd = &cobra.Command{
 
 func init() {
 	agentCmd.AddCommand(borrowCmd)
+	addUnsignedOutFlag(borrowCmd)
 	borrowCmd.Flags().String("pool-name", "infinity-pool", "name of the pool to borrow from")
 }
@@ -6,15 +6,13 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"math/big"
 	"os"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/briandowns/spinner"
-	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/glifio/glif/v2/events"
 	"github.com/glifio/glif/v2/util"
-	walletutils "github.com/glifio/go-wallet-utils"
 	"github.com/spf13/cobra"
 )
 
@@ -26,10 +24,6 @@ var createCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		as := util.AccountsStore()
 		agentStore := util.AgentStore()
-		ks := util.KeyStore()
-		backends := []accounts.Backend{}
-		backends = append(backends, ks)
-		manager := accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: false}, backends...)
 
 		// Check if an agent already exists
 		addressStr, err := as.Get("address")
@@ -52,17 +46,22 @@ var createCmd = &cobra.Command{
 		requestAddr, _, err := as.GetAddrs(string(util.RequestKey))
 		checkExists(err)
 
-		account := accounts.Account{Address: ownerAddr}
-		passphrase, envSet := os.LookupEnv("GLIF_OWNER_PASSPHRASE")
-		if !envSet {
-			prompt := &survey.Password{
-				Message: "Owner key passphrase",
+		auth := unsignedTransactor(ownerAddr)
+		if !dryRunFlag {
+			s, err := accountSigner(ownerAddr, func() (string, error) {
+				passphrase, envSet := os.LookupEnv("GLIF_OWNER_PASSPHRASE")
+				if !envSet {
+					prompt := &survey.Password{
+						Message: "Owner key passphrase",
+					}
+					survey.AskOne(prompt, &passphrase)
+				}
+				return passphrase, nil
+			})
+			if err != nil {
+				logFatal(err)
 			}
-			survey.AskOne(prompt, &passphrase)
-		}
-		wallet, err := manager.Find(account)
-		if err != nil {
-			logFatal(err)
+			auth = signerTransactor(ownerAddr, s)
 		}
 
 		if util.IsZeroAddress(ownerAddr) || util.IsZeroAddress(operatorAddr) || util.IsZeroAddress(requestAddr) {
@@ -75,10 +74,20 @@ var createCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		auth, err := walletutils.NewEthWalletTransactor(wallet, &account, passphrase, big.NewInt(chainID))
-		if err != nil {
+		if err := applyFeeCaps(cmd.Context(), auth); err != nil {
 			logFatal(err)
 		}
+		if err := lockTxs(); err != nil {
+			logFatal(err)
+		}
+
+		evt := &events.AgentCreate{
+			Owner:     ownerAddr.String(),
+			Operator:  operatorAddr.String(),
+			Requester: requestAddr.String(),
+		}
+		txj := newTxJournal("agent", "create", evt)
+		defer journal.Close()
 
 		// submit the agent create transaction
 		tx, err := PoolsSDK.Act().AgentCreate(
@@ -89,8 +98,9 @@ var createCmd = &cobra.Command{
 			requestAddr,
 		)
 		if err != nil {
-			logFatalf("pools sdk: agent create: %s", err)
+			logFatalf("pools sdk: agent create: %s", txj.failed(err))
 		}
+		txj.submitted(tx)
 
 		s.Stop()
 
@@ -99,7 +109,7 @@ var createCmd = &cobra.Command{
 
 		s.Start()
 		// transaction landed on chain or errored
-		receipt, err := PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		receipt, err := txj.waitReceipt(cmd.Context(), tx)
 		if err != nil {
 			logFatalf("pools sdk: query: state wait receipt: %s", err)
 		}
@@ -107,8 +117,12 @@ var createCmd = &cobra.Command{
 		// grab the ID and the address of the agent from the receipt's logs
 		addr, id, err := PoolsSDK.Query().AgentAddrIDFromRcpt(cmd.Context(), receipt)
 		if err != nil {
+			txj.confirmed(receipt)
 			logFatalf("pools sdk: query: agent addr id from receipt: %s", err)
 		}
+		evt.AgentID = addr.String()
+		evt.ID = id.String()
+		txj.confirmed(receipt)
 
 		s.Stop()
 
@@ -49,26 +49,23 @@ var exitCmd = &cobra.Command{
 		payAmount := new(big.Int).Add(amountOwed, account.Principal)
 		payAmount = addOnePercent(payAmount)
 
-		exitevt := journal.RegisterEventType("agent", "exit")
 		evt := &events.AgentExit{
 			AgentID: agentAddr.String(),
 			PoolID:  poolID.String(),
 			Amount:  payAmount.String(),
 		}
+		txj := newTxJournal("agent", "exit", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(exitevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentPay(ctx, auth, agentAddr, poolID, payAmount, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -2,28 +2,173 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/glifio/glif/v2/events"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
 var historyCmd = &cobra.Command{
 	Use:   "history",
 	Short: "View actions that are in the audit log",
+	Long: `View actions that are in the audit log, across all rolled journal files.
+
+Times passed to --since and --until can be RFC 3339 timestamps, dates (2006-01-02),
+or durations relative to now, e.g. 36h or 7d.
+
+With --from-chain, the history of the agent is rebuilt from the FEVM logs of the
+protocol contracts instead, e.g. when the local journal was lost. This covers the
+creation of the agent, borrows, payments, write-offs, miners added and removed and
+faulty sector reports; the agent contract does not emit logs, so pulls, pushes,
+withdrawals and ownership changes cannot be recovered. Scanned epochs are indexed
+locally, so only new epochs are fetched by later runs. Use --backfill to also record
+the recovered events that are missing from the journal.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		// read journal json file
-		// parse njson into chronologically ordered slice
-		// make sure that the top level data structure makes filtering by event type is simple for future --filter feature
-		evts, err := journal.ReadEvents()
+		q, err := historyQuery(cmd, time.Now())
 		if err != nil {
 			logFatal(err)
 		}
 
-		for _, e := range evts {
-			fmt.Println(e)
+		fromChain, _ := cmd.Flags().GetBool("from-chain")
+		backfill, _ := cmd.Flags().GetBool("backfill")
+		reindex, _ := cmd.Flags().GetBool("reindex")
+		if (backfill || reindex) && !fromChain {
+			logFatal("--backfill and --reindex require --from-chain")
+		}
+
+		queryEvents := journal.QueryEvents
+		if fromChain {
+			agentAddr, err := getAgentAddressWithFlags(cmd)
+			if err != nil {
+				logFatal(err)
+			}
+
+			ix, err := scanChainHistory(cmd.Context(), agentAddr, reindex)
+			if err != nil {
+				logFatal(err)
+			}
+			queryEvents = ix.QueryEvents
+
+			if backfill {
+				defer journal.Close()
+				n, err := backfillJournal(ix)
+				if err != nil {
+					logFatal(err)
+				}
+				fmt.Fprintf(os.Stderr, "Backfilled %d events into the journal\n", n)
+			}
+		}
+
+		entries := []historyEntry{}
+		err = queryEvents(q, func(e jnal.Event) error {
+			entries = append(entries, historyEntry{
+				Timestamp: e.Timestamp,
+				System:    e.System,
+				Event:     e.Event,
+				Data:      events.Decode(e).Data,
+			})
+			return nil
+		})
+		if err != nil {
+			logFatal(err)
 		}
+
+		printOutput(entries, func() {
+			if len(entries) == 0 {
+				fmt.Println("No events found")
+				return
+			}
+
+			tbl := table.New("Time", "Event", "Details")
+			for _, e := range entries {
+				tbl.AddRow(e.Timestamp.Local().Format(time.DateTime), e.System+":"+e.Event, events.Format(e.Data, chainID))
+			}
+			tbl.Print()
+		})
 	},
 }
 
+type historyEntry struct {
+	Timestamp time.Time   `json:"timestamp"`
+	System    string      `json:"system"`
+	Event     string      `json:"event"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+func historyQuery(cmd *cobra.Command, now time.Time) (jnal.Query, error) {
+	flags := cmd.Flags()
+
+	q := jnal.Query{}
+	q.System, _ = flags.GetString("system")
+	q.Event, _ = flags.GetString("event")
+	q.ErrorsOnly, _ = flags.GetBool("errors-only")
+	q.Tx, _ = flags.GetString("tx")
+	q.Limit, _ = flags.GetInt("limit")
+
+	// allow --event system:event as a shorthand
+	if sys, evt, ok := strings.Cut(q.Event, ":"); ok {
+		q.System, q.Event = sys, evt
+	}
+
+	if since, _ := flags.GetString("since"); since != "" {
+		t, err := parseTimeFlag(since, now)
+		if err != nil {
+			return jnal.Query{}, fmt.Errorf("invalid --since: %w", err)
+		}
+		q.Since = t
+	}
+
+	if until, _ := flags.GetString("until"); until != "" {
+		t, err := parseTimeFlag(until, now)
+		if err != nil {
+			return jnal.Query{}, fmt.Errorf("invalid --until: %w", err)
+		}
+		q.Until = t
+	}
+
+	return q, nil
+}
+
+// parseTimeFlag parses an RFC 3339 timestamp, a date, or a duration relative
+// to now such as 36h or 7d
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.Add(-time.Duration(n) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time or duration", s)
+}
+
 func init() {
 	agentCmd.AddCommand(historyCmd)
+	historyCmd.Flags().String("system", "", "only show events of this system, e.g. agent or wallet")
+	historyCmd.Flags().String("event", "", "only show events of this type, e.g. pay or agent:borrow")
+	historyCmd.Flags().String("since", "", "only show events recorded at or after this time")
+	historyCmd.Flags().String("until", "", "only show events recorded at or before this time")
+	historyCmd.Flags().Bool("errors-only", false, "only show events that recorded an error")
+	historyCmd.Flags().String("tx", "", "only show events of this transaction hash")
+	historyCmd.Flags().Int("limit", 0, "only show the most recent events, 0 shows all events")
+	historyCmd.Flags().Bool("from-chain", false, "rebuild the history of the agent from chain instead of the journal")
+	historyCmd.Flags().Bool("backfill", false, "record the events rebuilt from chain that are missing from the journal")
+	historyCmd.Flags().Bool("reindex", false, "scan the chain again from the start instead of resuming from the local index")
+	historyCmd.Flags().String("agent-addr", "", "Agent address, defaults to the agent of this machine")
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/glifio/glif/v2/events"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/journal/chainindex"
+	"github.com/glifio/go-pools/constants"
+	"github.com/glifio/go-pools/deploy"
+)
+
+// chainIndexPath is where the events of the agent reconstructed from chain are
+// indexed, per chain since the same agent address can exist on several chains
+func chainIndexPath(agentAddr common.Address) string {
+	return filepath.Join(cfgDir, "chain-index", fmt.Sprintf("%d-%s.json", chainID, strings.ToLower(agentAddr.Hex())))
+}
+
+// protocolDeployEpoch is the first epoch a scan of the chain starts from
+func protocolDeployEpoch() uint64 {
+	switch chainID {
+	case constants.MainnetChainID:
+		return deploy.ProtocolDeployEpoch.Uint64()
+	case constants.CalibnetChainID:
+		return deploy.TProtocolDeployEpoch.Uint64()
+	default:
+		return 0
+	}
+}
+
+// scanChainHistory brings the chain index of the agent up to the chain head,
+// fetching the logs of the blocks that were not scanned yet
+func scanChainHistory(ctx context.Context, agentAddr common.Address, reindex bool) (*chainindex.Index, error) {
+	ix, err := chainindex.Open(chainIndexPath(agentAddr))
+	if err != nil {
+		return nil, err
+	}
+	if reindex {
+		ix.Reset()
+	}
+
+	agentID, err := PoolsSDK.Query().AgentID(ctx, agentAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := events.NewChainLogs(events.ChainContracts{
+		InfinityPool:  PoolsSDK.Query().InfinityPool(),
+		MinerRegistry: PoolsSDK.Query().MinerRegistry(),
+		AgentFactory:  PoolsSDK.Query().AgentFactory(),
+		AgentPolice:   PoolsSDK.Query().AgentPolice(),
+	}, agentAddr, agentID, strconv.FormatUint(uint64(InfinityPool), 10))
+	if err != nil {
+		return nil, err
+	}
+
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		return nil, err
+	}
+	defer eapi.Close()
+
+	head, err := eapi.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// blocks within finality can still be reorged, they are scanned again
+	// next time
+	var final uint64
+	if head > uint64(policy.ChainFinality) {
+		final = head - uint64(policy.ChainFinality)
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+	s.Start()
+	defer s.Stop()
+
+	times := map[uint64]time.Time{}
+	chunk := constants.CHUNKSIZE.Uint64()
+	start := ix.Next(protocolDeployEpoch())
+
+	for from := start; from <= head; from += chunk {
+		to := from + chunk - 1
+		if to > head {
+			to = head
+		}
+		s.Suffix = fmt.Sprintf(" scanning epochs %d to %d of %d", from, to, head)
+
+		found, err := eapi.FilterLogs(ctx, logs.FilterQuery(from, to))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch logs of epochs %d to %d: %w", from, to, err)
+		}
+
+		var entries []chainindex.Entry
+		for _, l := range found {
+			e, ok, err := logs.Decode(l)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+
+			if _, ok := times[l.BlockNumber]; !ok {
+				header, err := eapi.HeaderByNumber(ctx, new(big.Int).SetUint64(l.BlockNumber))
+				if err != nil {
+					return nil, err
+				}
+				times[l.BlockNumber] = time.Unix(int64(header.Time), 0)
+			}
+			e.Timestamp = times[l.BlockNumber]
+
+			entries = append(entries, chainindex.Entry{Block: l.BlockNumber, Event: e})
+		}
+
+		ix.Add(entries, to, final)
+		if err := ix.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ix, nil
+}
+
+// backfillJournal records the indexed events that are missing from the
+// journal, at the time of the block they were emitted in.
+func backfillJournal(ix *chainindex.Index) (int, error) {
+	// a transaction can emit several events of the same type, e.g. when
+	// adding miners, so the successful actions are counted rather than flagged
+	journaled := map[string]int{}
+	err := journal.QueryEvents(jnal.Query{}, func(e jnal.Event) error {
+		tx := jnal.EventTx(e)
+		if tx == "" || jnal.EventError(e) != "" {
+			return nil
+		}
+		switch jnal.EventStatus(e) {
+		case "", events.StatusConfirmed:
+			journaled[backfillKey(e.System, e.Event, tx)]++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, entry := range ix.Entries {
+		e := events.Decode(entry.Event)
+		key := backfillKey(e.System, e.Event, jnal.EventTx(e))
+		if journaled[key] > 0 {
+			journaled[key]--
+			continue
+		}
+
+		data := e.Data
+		journal.RecordEventAt(journal.RegisterEventType(e.System, e.Event), e.Timestamp, func() interface{} { return data })
+		n++
+	}
+	return n, nil
+}
+
+func backfillKey(system, event, tx string) string {
+	return system + ":" + event + ":" + strings.ToLower(tx)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/glifio/glif/v2/events"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/journal/chainindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillRecordsBlockTime(t *testing.T) {
+	env := newTestEnv(t)
+	paid := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// a payment rebuilt from chain by an earlier scan, up to the head
+	prev := cfgDir
+	cfgDir = env.dir
+	ix, err := chainindex.Open(chainIndexPath(env.agent.Address))
+	cfgDir = prev
+	require.NoError(t, err)
+	head := env.sdk.Height.Uint64()
+	ix.Add([]chainindex.Entry{{Block: 100, Event: jnal.Event{
+		EventType: jnal.EventType{System: "agent", Event: "pay"},
+		Timestamp: paid,
+		Data:      &events.AgentPay{Amount: "1", PayType: "custom"},
+	}}}, head, head)
+	require.NoError(t, ix.Save())
+
+	res := env.run("agent", "history", "--from-chain", "--backfill")
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stderr, "Backfilled 1 events")
+
+	// the journal has it at the time of its block
+	var entries []historyEntry
+	res = env.run("agent", "history", "--until", "2024-01-01", "-o", "json")
+	assert.Equal(t, 0, res.code)
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &entries))
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "pay", entries[0].Event)
+		assert.True(t, paid.Equal(entries[0].Timestamp))
+	}
+
+	res = env.run("agent", "history", "--since", "2024-01-01", "-o", "json")
+	assert.Equal(t, 0, res.code)
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &entries))
+	assert.Empty(t, entries)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/glifio/glif/v2/events"
 	"github.com/glifio/glif/v2/util"
 	"github.com/spf13/cobra"
 )
@@ -56,13 +57,23 @@ var agentImportCmd = &cobra.Command{
 			logFatal(err)
 		}
 
+		importevt := journal.RegisterEventType("agent", "import")
+		evt := &events.AgentImport{
+			AgentID: agentAddr.String(),
+			ID:      id.String(),
+		}
+		defer journal.Close()
+		defer journal.RecordEvent(importevt, func() interface{} { return evt })
+
 		err = agentStore.Set("id", id.String())
 		if err != nil {
+			evt.Error = err.Error()
 			logFatal(err)
 		}
 
 		err = agentStore.Set("address", agentAddr.String())
 		if err != nil {
+			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -10,7 +10,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/lotus/api"
@@ -33,7 +32,7 @@ var agentInfoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Get the info associated with your Agent",
 	Run: func(cmd *cobra.Command, args []string) {
-		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+		s := newSpinner()
 		s.Start()
 		defer s.Stop()
 
@@ -58,30 +57,58 @@ var agentInfoCmd = &cobra.Command{
 			logFatal(err)
 		}
 
-		agentID, _, _, _, err := basicInfo(cmd.Context(), agentAddr, agentAddrDel, lapi, s)
+		basic, err := basicInfo(cmd.Context(), agentAddr, agentAddrDel, lapi)
 		if err != nil {
 			logFatal(err)
 		}
 
-		agentData, ats, err := econInfo(cmd.Context(), agentAddr, agentID, lapi, s)
+		econData, err := econInfo(cmd.Context(), agentAddr, basic.agentID)
 		if err != nil {
 			logFatal(err)
 		}
 
-		err = agentHealth(cmd.Context(), agentAddr, agentData, ats, s)
+		health, err := agentHealth(cmd.Context(), agentAddr, econData.agentData, econData.ats)
 		if err != nil {
 			logFatal(err)
 		}
+
+		s.Stop()
+
+		printOutput(&agentInfoOutput{
+			Basic:  basic,
+			Econ:   econData,
+			Health: health,
+		}, func() {
+			printBasicInfo(basic)
+			printEconInfo(econData)
+			printAgentHealth(health)
+		})
 	},
 }
 
-func basicInfo(ctx context.Context, agent common.Address, agentDel address.Address, lapi *api.FullNodeStruct, s *spinner.Spinner) (
-	agentID *big.Int,
-	agentFILIDAddr address.Address,
-	agVersion uint8,
-	ntwVersion uint8,
-	err error,
-) {
+type agentInfoOutput struct {
+	Basic  *agentBasicInfo  `json:"basic"`
+	Econ   *agentEconInfo   `json:"econ"`
+	Health *agentHealthInfo `json:"health"`
+}
+
+type agentBasicInfo struct {
+	Address        string   `json:"address"`
+	DelegatedAddr  string   `json:"delegated_address"`
+	IDAddr         string   `json:"id_address"`
+	GlifID         string   `json:"glif_id"`
+	Owner          string   `json:"owner"`
+	Operator       string   `json:"operator"`
+	Requester      string   `json:"requester"`
+	Miners         []string `json:"miners"`
+	Version        uint8    `json:"version"`
+	NetworkVersion uint8    `json:"network_version"`
+	UpgradeNeeded  bool     `json:"upgrade_needed"`
+
+	agentID *big.Int
+}
+
+func basicInfo(ctx context.Context, agent common.Address, agentDel address.Address, lapi *api.FullNodeStruct) (*agentBasicInfo, error) {
 	query := PoolsSDK.Query()
 
 	tasks := []util.TaskFunc{
@@ -114,26 +141,39 @@ func basicInfo(ctx context.Context, agent common.Address, agentDel address.Addre
 	}
 	results, err := util.Multiread(tasks)
 	if err != nil {
-		return common.Big0, address.Undef, 0, 0, err
+		return nil, err
 	}
 
-	agentID = results[0].(*big.Int)
-	agentFILIDAddr = results[1].(address.Address)
+	agentID := results[0].(*big.Int)
+	agentFILIDAddr := results[1].(address.Address)
 	versionResults := results[2].([]interface{})
-	agVersion = versionResults[0].(uint8)
-	ntwVersion = versionResults[1].(uint8)
+	agVersion := versionResults[0].(uint8)
+	ntwVersion := versionResults[1].(uint8)
 	owner := results[3].(common.Address)
 	operator := results[4].(common.Address)
 	requester := results[5].(common.Address)
 	agentMiners := results[6].([]address.Address)
 
-	goodVersion := agVersion == ntwVersion
-
-	s.Stop()
+	return &agentBasicInfo{
+		Address:        agent.String(),
+		DelegatedAddr:  agentDel.String(),
+		IDAddr:         agentFILIDAddr.String(),
+		GlifID:         agentID.String(),
+		Owner:          owner.String(),
+		Operator:       operator.String(),
+		Requester:      requester.String(),
+		Miners:         AddressesToStrings(agentMiners),
+		Version:        agVersion,
+		NetworkVersion: ntwVersion,
+		UpgradeNeeded:  agVersion != ntwVersion,
+		agentID:        agentID,
+	}, nil
+}
 
-	versionCopy := fmt.Sprintf("%v ✅", agVersion)
-	if !goodVersion {
-		versionCopy = fmt.Sprintf("Please upgrade Agent ❌. Your version: %v, latest: %v", agVersion, ntwVersion)
+func printBasicInfo(info *agentBasicInfo) {
+	versionCopy := fmt.Sprintf("%v ✅", info.Version)
+	if info.UpgradeNeeded {
+		versionCopy = fmt.Sprintf("Please upgrade Agent ❌. Your version: %v, latest: %v", info.Version, info.NetworkVersion)
 	}
 
 	basicInfoKeys := []string{
@@ -149,37 +189,59 @@ func basicInfo(ctx context.Context, agent common.Address, agentDel address.Addre
 	}
 
 	basicInfoValues := []string{
-		agent.String(),
-		agentDel.String(),
-		agentFILIDAddr.String(),
-		agentID.String(),
-		owner.String(),
-		operator.String(),
-		requester.String(),
-		fmt.Sprintf("%v", len(agentMiners)),
+		info.Address,
+		info.DelegatedAddr,
+		info.IDAddr,
+		info.GlifID,
+		info.Owner,
+		info.Operator,
+		info.Requester,
+		fmt.Sprintf("%v", len(info.Miners)),
 		versionCopy,
 	}
 
 	generateHeader("BASIC INFO")
 	printTable(basicInfoKeys, basicInfoValues)
+}
 
-	s.Start()
-
-	return agentID, agentFILIDAddr, agVersion, ntwVersion, nil
+type agentEconInfo struct {
+	BorrowNow              FILAmount `json:"borrow_now"`
+	MaxBorrow              FILAmount `json:"max_borrow"`
+	LiquidationValue       FILAmount `json:"liquidation_value"`
+	RecoveryRatePercent    float64   `json:"recovery_rate_percent"`
+	Level                  string    `json:"level"`
+	Quota                  FILAmount `json:"quota"`
+	Principal              FILAmount `json:"principal"`
+	InterestOwed           FILAmount `json:"interest_owed"`
+	BorrowAPRPercent       float64   `json:"borrow_apr_percent"`
+	WeeklyPayment          FILAmount `json:"weekly_payment"`
+	LiquidFIL              FILAmount `json:"liquid_fil"`
+	TotalFIL               FILAmount `json:"total_fil"`
+	Equity                 FILAmount `json:"equity"`
+	ExpectedWeeklyEarnings FILAmount `json:"expected_weekly_earnings"`
+	LTVPercent             float64   `json:"ltv_percent"`
+	MaxLTVPercent          float64   `json:"max_ltv_percent"`
+	DTEPercent             float64   `json:"dte_percent"`
+	MaxDTEPercent          float64   `json:"max_dte_percent"`
+	DTIPercent             float64   `json:"dti_percent"`
+	MaxDTIPercent          float64   `json:"max_dti_percent"`
+
+	agentData *vc.AgentData
+	ats       terminate.PreviewAgentTerminationSummary
 }
 
-func econInfo(ctx context.Context, agent common.Address, agentID *big.Int, lapi *api.FullNodeStruct, s *spinner.Spinner) (*vc.AgentData, terminate.PreviewAgentTerminationSummary, error) {
+func econInfo(ctx context.Context, agent common.Address, agentID *big.Int) (*agentEconInfo, error) {
 	query := PoolsSDK.Query()
 
 	adoCloser, err := PoolsSDK.Extern().ConnectAdoClient(ctx)
 	if err != nil {
-		return nil, terminate.PreviewAgentTerminationSummary{}, err
+		return nil, err
 	}
 	defer adoCloser()
 
 	agentData, err := rpc.ADOClient.AgentData(context.Background(), agent)
 	if err != nil {
-		return nil, terminate.PreviewAgentTerminationSummary{}, err
+		return nil, err
 	}
 
 	tasks := []util.TaskFunc{
@@ -210,7 +272,7 @@ func econInfo(ctx context.Context, agent common.Address, agentID *big.Int, lapi
 
 	results, err := util.Multiread(tasks)
 	if err != nil {
-		return nil, terminate.PreviewAgentTerminationSummary{}, err
+		return nil, err
 	}
 	assets := results[0].(*big.Int)
 	borrowNow := results[1].(*big.Int)
@@ -239,58 +301,90 @@ func econInfo(ctx context.Context, agent common.Address, agentID *big.Int, lapi
 
 	nullCred, err := vc.NullishVerifiableCredential(*agentData)
 	if err != nil {
-		return nil, terminate.PreviewAgentTerminationSummary{}, err
+		return nil, err
 	}
 
 	rate, err := query.InfPoolGetRate(ctx, *nullCred)
 	if err != nil {
-		return nil, terminate.PreviewAgentTerminationSummary{}, err
+		return nil, err
 	}
 
-	wpr := new(big.Float).Mul(new(big.Float).SetInt(rate), big.NewFloat(constants.EpochsInWeek))
-
 	apr := new(big.Float).Mul(new(big.Float).SetInt(rate), big.NewFloat(constants.EpochsInYear))
 	apr.Quo(apr, big.NewFloat(1e34))
+	aprPercent, _ := apr.Float64()
 
 	weeklyEarnings := new(big.Int).Mul(agentData.ExpectedDailyRewards, big.NewInt(7))
 
-	weeklyPmt := new(big.Float).Mul(new(big.Float).SetInt(agentData.Principal), wpr)
-	weeklyPmt.Quo(weeklyPmt, big.NewFloat(1e54))
+	// principal * rate per epoch * epochs in a week, the rate is scaled by 1e36
+	weeklyPmt := new(big.Int).Mul(agentData.Principal, rate)
+	weeklyPmt.Mul(weeklyPmt, big.NewInt(constants.EpochsInWeek))
+	weeklyPmt.Div(weeklyPmt, new(big.Int).Exp(big.NewInt(10), big.NewInt(36), nil))
 
 	equity := new(big.Int).Sub(agentData.AgentValue, agentData.Principal)
 	dte := econ.DebtToEquityRatio(agentData.Principal, equity)
+	dtePercent, _ := new(big.Float).Mul(dte, big.NewFloat(100)).Float64()
 
-	dailyFees := rate.Mul(rate, big.NewInt(constants.EpochsInDay))
+	dailyFees := new(big.Int).Mul(rate, big.NewInt(constants.EpochsInDay))
 	dailyFees.Mul(dailyFees, agentData.Principal)
 	dailyFees.Div(dailyFees, constants.WAD)
 
-	s.Stop()
+	dti := big.NewInt(0)
+	if agentData.ExpectedDailyRewards.Sign() > 0 {
+		dti = new(big.Int).Div(dailyFees, agentData.ExpectedDailyRewards)
+	}
 
+	ltv := ats.LTV(agentData.Principal)
+
+	return &agentEconInfo{
+		BorrowNow:              NewFILAmount(borrowNow),
+		MaxBorrow:              NewFILAmount(borrowMax),
+		LiquidationValue:       NewFILAmount(liquidationValue),
+		RecoveryRatePercent:    bigIntAttoToPercentFloat64(recoveryRate),
+		Level:                  lvl.String(),
+		Quota:                  NewFILAmount(util.ToAtto(big.NewFloat(cap))),
+		Principal:              NewFILAmount(agentData.Principal),
+		InterestOwed:           NewFILAmount(amountOwed),
+		BorrowAPRPercent:       aprPercent,
+		WeeklyPayment:          NewFILAmount(weeklyPmt),
+		LiquidFIL:              NewFILAmount(assets),
+		TotalFIL:               NewFILAmount(agentData.AgentValue),
+		Equity:                 NewFILAmount(equity),
+		ExpectedWeeklyEarnings: NewFILAmount(weeklyEarnings),
+		LTVPercent:             bigIntAttoToPercentFloat64(ltv),
+		MaxLTVPercent:          bigIntAttoToPercentFloat64(constants.MAX_LTV),
+		DTEPercent:             dtePercent,
+		MaxDTEPercent:          bigIntAttoToPercentFloat64(constants.MAX_DTE),
+		DTIPercent:             bigIntAttoToPercentFloat64(dti),
+		MaxDTIPercent:          bigIntAttoToPercentFloat64(constants.MAX_DTI),
+		agentData:              agentData,
+		ats:                    ats,
+	}, nil
+}
+
+func printEconInfo(info *agentEconInfo) {
 	generateHeader("ECON INFO")
 
 	printTable([]string{
 		"Borrow now",
 		"Max borrow",
-		// "Agent's max withdraw",
 	}, []string{
-		fmt.Sprintf("%0.09f FIL", util.ToFIL(borrowNow)),
-		fmt.Sprintf("%0.09f FIL", util.ToFIL(borrowMax)),
-		// fmt.Sprintf("%0.09f FIL", util.ToFIL(maxWithdraw)),
+		fmt.Sprintf("%0.09f FIL", filAmountToFloat(info.BorrowNow)),
+		fmt.Sprintf("%0.09f FIL", filAmountToFloat(info.MaxBorrow)),
 	})
 
 	printTable([]string{
 		"Liquidation value",
 		"Recovery rate",
 	}, []string{
-		fmt.Sprintf("\033[1m%0.09f FIL\033[0m", util.ToFIL(liquidationValue)),
-		fmt.Sprintf("%0.03f%%", bigIntAttoToPercent(ats.RecoveryRate())),
+		fmt.Sprintf("\033[1m%0.09f FIL\033[0m", filAmountToFloat(info.LiquidationValue)),
+		fmt.Sprintf("%0.03f%%", info.RecoveryRatePercent),
 	})
 
-	if lvl.Cmp(big.NewInt(0)) == 0 && chainID == constants.MainnetChainID {
+	if info.Level == "0" && chainID == constants.MainnetChainID {
 		fmt.Println()
 		fmt.Println(chalk.Bold.TextStyle("Please open up a request for quota on GitHub: https://tinyurl.com/glif-entry-request"))
 	}
-	if agentData.Principal.Cmp(big.NewInt(0)) == 0 {
+	if info.agentData.Principal.Cmp(big.NewInt(0)) == 0 {
 		nothingBorrowedKeys := []string{
 			"Total borrowed",
 			"Agent's liquid FIL",
@@ -302,67 +396,74 @@ func econInfo(ctx context.Context, agent common.Address, agentID *big.Int, lapi
 
 		nothingBorrowedValues := []string{
 			"0 FIL",
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(assets)),
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(agentData.AgentValue)),
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(equity)),
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(weeklyEarnings)),
-			fmt.Sprintf("%.03f FIL", cap),
+			fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.LiquidFIL)),
+			fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.TotalFIL)),
+			fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.Equity)),
+			fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.ExpectedWeeklyEarnings)),
+			fmt.Sprintf("%.03f FIL", filAmountToFloat(info.Quota)),
 		}
 		printTable(nothingBorrowedKeys, nothingBorrowedValues)
-	} else {
-		somethingBorrowedKeys := []string{
-			"Total borrowed",
-			"You current owe",
-			"Current borrow APR",
-			"Your weekly payment",
-			"Quota",
-		}
-
-		somethingBorrowedValues := []string{
-			fmt.Sprintf("%0.09f FIL", util.ToFIL(agentData.Principal)),
-			fmt.Sprintf("%0.09f FIL", util.ToFIL(amountOwed)),
-			fmt.Sprintf("%.03f%%", apr),
-			fmt.Sprintf("%0.09f FIL", weeklyPmt),
-			fmt.Sprintf("%.03f FIL", cap),
-		}
-		printTable(somethingBorrowedKeys, somethingBorrowedValues)
-
-		dti := new(big.Int).Div(dailyFees, agentData.ExpectedDailyRewards)
-
-		ltv := ats.LTV(agentData.Principal)
-
-		coreEconKeys := []string{
-			"Liquid FIL",
-			"Total FIL",
-			"Equity",
-			"Expected weekly earnings",
-			"Debt-to-liquidation-value (LTV)",
-			"Debt-to-equity (DTE)",
-			"Debt-to-income (DTI)",
-		}
+		return
+	}
 
-		coreEconValues := []string{
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(assets)),
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(agentData.AgentValue)),
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(equity)),
-			fmt.Sprintf("%0.08f FIL", util.ToFIL(weeklyEarnings)),
-			fmt.Sprintf("%0.03f%% (must stay below %0.00f%%)", bigIntAttoToPercent(ltv), bigIntAttoToPercent(constants.MAX_LTV)),
-			fmt.Sprintf("%0.03f%% (must stay below %0.00f%%)", dte.Mul(dte, big.NewFloat(100)), bigIntAttoToPercent(constants.MAX_DTE)),
-			fmt.Sprintf("%0.03f%% (must stay below %0.00f%%)", bigIntAttoToPercent(dti), bigIntAttoToPercent(constants.MAX_DTI)),
-		}
+	somethingBorrowedKeys := []string{
+		"Total borrowed",
+		"You current owe",
+		"Current borrow APR",
+		"Your weekly payment",
+		"Quota",
+	}
 
-		printTable(coreEconKeys, coreEconValues)
+	somethingBorrowedValues := []string{
+		fmt.Sprintf("%0.09f FIL", filAmountToFloat(info.Principal)),
+		fmt.Sprintf("%0.09f FIL", filAmountToFloat(info.InterestOwed)),
+		fmt.Sprintf("%.03f%%", info.BorrowAPRPercent),
+		fmt.Sprintf("%0.09f FIL", filAmountToFloat(info.WeeklyPayment)),
+		fmt.Sprintf("%.03f FIL", filAmountToFloat(info.Quota)),
+	}
+	printTable(somethingBorrowedKeys, somethingBorrowedValues)
+
+	coreEconKeys := []string{
+		"Liquid FIL",
+		"Total FIL",
+		"Equity",
+		"Expected weekly earnings",
+		"Debt-to-liquidation-value (LTV)",
+		"Debt-to-equity (DTE)",
+		"Debt-to-income (DTI)",
 	}
 
-	s.Start()
+	coreEconValues := []string{
+		fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.LiquidFIL)),
+		fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.TotalFIL)),
+		fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.Equity)),
+		fmt.Sprintf("%0.08f FIL", filAmountToFloat(info.ExpectedWeeklyEarnings)),
+		fmt.Sprintf("%0.03f%% (must stay below %0.00f%%)", info.LTVPercent, info.MaxLTVPercent),
+		fmt.Sprintf("%0.03f%% (must stay below %0.00f%%)", info.DTEPercent, info.MaxDTEPercent),
+		fmt.Sprintf("%0.03f%% (must stay below %0.00f%%)", info.DTIPercent, info.MaxDTIPercent),
+	}
 
-	return agentData, ats, nil
+	printTable(coreEconKeys, coreEconValues)
 }
 
 func bigIntAttoToPercent(atto *big.Int) *big.Float {
 	return new(big.Float).Mul(util.ToFIL(atto), big.NewFloat(100))
 }
 
+func bigIntAttoToPercentFloat64(atto *big.Int) float64 {
+	f, _ := bigIntAttoToPercent(atto).Float64()
+	return f
+}
+
+func filAmountToFloat(amt FILAmount) float64 {
+	f, _ := new(big.Float).SetString(amt.FIL)
+	if f == nil {
+		return 0
+	}
+	v, _ := f.Float64()
+	return v
+}
+
 func printTable(keys []string, values []string) {
 	// here we hacky get the same width for all separate tables in the info command by making the first row have a long width
 	tbl := table.New("                                    ", "")
@@ -374,7 +475,32 @@ func printTable(keys []string, values []string) {
 	tbl.Print()
 }
 
-func agentHealth(ctx context.Context, agent common.Address, agentData *vc.AgentData, ats terminate.PreviewAgentTerminationSummary, s *spinner.Spinner) error {
+type agentHealthInfo struct {
+	Healthy                  bool      `json:"healthy"`
+	Administrator            string    `json:"administrator"`
+	Defaulted                bool      `json:"defaulted"`
+	OwesPayment              bool      `json:"owes_payment"`
+	LatePayment              bool      `json:"late_payment"`
+	OverLTV                  bool      `json:"over_ltv"`
+	EpochsPaid               string    `json:"epochs_paid"`
+	PaymentDeadlineEpoch     string    `json:"payment_deadline_epoch"`
+	PaymentDeadline          time.Time `json:"payment_deadline"`
+	DefaultEpoch             string    `json:"default_epoch"`
+	DefaultDeadline          time.Time `json:"default_deadline"`
+	HasFaultySectors         bool      `json:"has_faulty_sectors"`
+	FaultySectorStartEpoch   string    `json:"faulty_sector_start_epoch"`
+	FaultySectorRatioPercent float64   `json:"faulty_sector_ratio_percent"`
+	FaultySectorLimitPercent float64   `json:"faulty_sector_limit_percent"`
+	OverFaultySectorLimit    bool      `json:"over_faulty_sector_limit"`
+	LiableForFaultDefault    bool      `json:"liable_for_fault_default"`
+	EpochsBeforeFaultDefault string    `json:"epochs_before_fault_default,omitempty"`
+
+	epochsPaidTime time.Time
+}
+
+// agentHealth collects the on-chain state that determines whether the agent is
+// in good standing with the pool
+func agentHealth(ctx context.Context, agent common.Address, agentData *vc.AgentData, ats terminate.PreviewAgentTerminationSummary) (*agentHealthInfo, error) {
 	query := PoolsSDK.Query()
 
 	tasks := []util.TaskFunc{
@@ -398,7 +524,7 @@ func agentHealth(ctx context.Context, agent common.Address, agentData *vc.AgentD
 
 	results, err := util.Multiread(tasks)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	agentAdmin := results[0].(common.Address)
@@ -411,14 +537,6 @@ func agentHealth(ctx context.Context, agent common.Address, agentData *vc.AgentD
 
 	weekOneDeadline := new(big.Int).Add(defaultEpoch, big.NewInt(constants.EpochsInWeek*2))
 
-	weekOneDeadlineTime := util.EpochHeightToTimestamp(weekOneDeadline, query.ChainID())
-	defaultEpochTime := util.EpochHeightToTimestamp(defaultEpoch, query.ChainID())
-	epochsPaidTime := util.EpochHeightToTimestamp(account.EpochsPaid, query.ChainID())
-
-	s.Stop()
-
-	generateHeader("HEALTH")
-	fmt.Println()
 	// check to see we're still in good standing wrt making our weekly payment
 	owesPmt := account.Principal.Cmp(big.NewInt(0)) > 0
 	badPmtStatus := owesPmt && account.EpochsPaid.Cmp(weekOneDeadline) < 1
@@ -437,70 +555,99 @@ func agentHealth(ctx context.Context, agent common.Address, agentData *vc.AgentD
 	}
 
 	// convert faults into percentage for logging
-	faultRatio = faultRatio.Mul(faultRatio, big.NewFloat(100))
+	faultRatioPercent, _ := new(big.Float).Mul(faultRatio, big.NewFloat(100)).Float64()
 	// convert limit into percentage for logging
-	limit := new(big.Float).Mul(constants.FAULTY_SECTOR_TOLERANCE, big.NewFloat(100))
+	limitPercent, _ := new(big.Float).Mul(constants.FAULTY_SECTOR_TOLERANCE, big.NewFloat(100)).Float64()
+
+	health := &agentHealthInfo{
+		Healthy:                  !badPmtStatus && !badFaultStatus && !pendingBadFaultStatus && !overLTV,
+		Administrator:            agentAdmin.String(),
+		Defaulted:                defaulted,
+		OwesPayment:              owesPmt,
+		LatePayment:              badPmtStatus,
+		OverLTV:                  overLTV,
+		EpochsPaid:               account.EpochsPaid.String(),
+		PaymentDeadlineEpoch:     weekOneDeadline.String(),
+		PaymentDeadline:          util.EpochHeightToTimestamp(weekOneDeadline, query.ChainID()),
+		DefaultEpoch:             defaultEpoch.String(),
+		DefaultDeadline:          util.EpochHeightToTimestamp(defaultEpoch, query.ChainID()),
+		HasFaultySectors:         badFaultStatus,
+		FaultySectorStartEpoch:   faultySectorStart.String(),
+		FaultySectorRatioPercent: faultRatioPercent,
+		FaultySectorLimitPercent: limitPercent,
+		OverFaultySectorLimit:    pendingBadFaultStatus,
+		epochsPaidTime:           util.EpochHeightToTimestamp(account.EpochsPaid, query.ChainID()),
+	}
+
+	if badFaultStatus {
+		chainHeight, err := query.ChainHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		consecutiveFaultEpochTolerance, err := query.MaxConsecutiveFaultEpochs(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		consecutiveFaultEpochs := new(big.Int).Sub(chainHeight, faultySectorStart)
+
+		health.LiableForFaultDefault = consecutiveFaultEpochs.Cmp(consecutiveFaultEpochTolerance) >= 0
+		if !health.LiableForFaultDefault {
+			health.EpochsBeforeFaultDefault = new(big.Int).Sub(consecutiveFaultEpochTolerance, consecutiveFaultEpochs).String()
+		}
+	}
 
-	if !badPmtStatus && !badFaultStatus && !pendingBadFaultStatus && !overLTV {
+	return health, nil
+}
+
+func printAgentHealth(health *agentHealthInfo) {
+	generateHeader("HEALTH")
+	fmt.Println()
+
+	if health.Healthy {
 		fmt.Printf("Status healthy 🟢\n")
-		if owesPmt {
-			fmt.Printf("Your account owes its weekly payment (`to-current`) within the next: %s (by epoch # %s)\n", formatSinceDuration(weekOneDeadlineTime, epochsPaidTime), weekOneDeadline)
+		if health.OwesPayment {
+			fmt.Printf("Your account owes its weekly payment (`to-current`) within the next: %s (by epoch # %s)\n", formatSinceDuration(health.PaymentDeadline, health.epochsPaidTime), health.PaymentDeadlineEpoch)
 		}
 	} else {
 		fmt.Println(chalk.Bold.TextStyle("Status unhealthy 🔴"))
 	}
 
-	if overLTV {
+	if health.OverLTV {
 		fmt.Printf("WARNING: Your Agent is over the LTV limit of %0.00f%%\n", bigIntAttoToPercent(constants.MAX_LTV))
 		fmt.Printf("Your Agent must pay down its debt or increase its collateral to avoid liquidation\n")
 		fmt.Printf("Contact the GLIF team as soon as possible\n")
 	}
 
-	if badPmtStatus {
+	if health.LatePayment {
 		fmt.Println("You are late on your weekly payment")
-		fmt.Printf("Your account *must* make a payment to-current within the next: %s (by epoch # %s)\n", formatSinceDuration(defaultEpochTime, epochsPaidTime), defaultEpoch)
+		fmt.Printf("Your account *must* make a payment to-current within the next: %s (by epoch # %s)\n", formatSinceDuration(health.DefaultDeadline, health.epochsPaidTime), health.DefaultEpoch)
 	}
 
 	// since we have to report faulty sectors when the Agent is overLTV, we only display this message if the Agent is not overLTV AND has faulty sectors
-	if badFaultStatus && !overLTV {
-		chainHeight, err := query.ChainHeight(ctx)
-		if err != nil {
-			return err
-		}
-
-		consecutiveFaultEpochTolerance, err := query.MaxConsecutiveFaultEpochs(ctx)
-		if err != nil {
-			return err
-		}
-
-		consecutiveFaultEpochs := new(big.Int).Sub(chainHeight, faultySectorStart)
-
-		liableForFaultySectorDefault := consecutiveFaultEpochs.Cmp(consecutiveFaultEpochTolerance) >= 0
-
-		if liableForFaultySectorDefault {
+	if health.HasFaultySectors && !health.OverLTV {
+		if health.LiableForFaultDefault {
 			fmt.Printf("You are at risk of liquidation due to consecutive faulty sectors - recover your sectors as soon as possible\n")
-			fmt.Printf("Faulty sector start epoch: %v\n", faultySectorStart)
+			fmt.Printf("Faulty sector start epoch: %v\n", health.FaultySectorStartEpoch)
 		} else {
-			epochsBeforeZeroTolerance := new(big.Int).Sub(consecutiveFaultEpochTolerance, consecutiveFaultEpochs)
 			fmt.Printf("WARNING: You are approaching risk of liquidation due to consecutive faulty sectors\n")
-			fmt.Printf("With %v more consecutive epochs of faulty sectors, you will be at risk of liquidation\n", epochsBeforeZeroTolerance)
+			fmt.Printf("With %v more consecutive epochs of faulty sectors, you will be at risk of liquidation\n", health.EpochsBeforeFaultDefault)
 		}
-	} else if pendingBadFaultStatus {
+	} else if health.OverFaultySectorLimit {
 		fmt.Printf("WARNING: Your Agent has one or more miners with faulty sectors - recover your sectors as soon as possible\n")
-		fmt.Printf("Faulty sector ratio: %.02f%%\n", faultRatio)
-		fmt.Printf("Faulty sector ratio limit: %v%%\n", limit.String())
+		fmt.Printf("Faulty sector ratio: %.02f%%\n", health.FaultySectorRatioPercent)
+		fmt.Printf("Faulty sector ratio limit: %v%%\n", health.FaultySectorLimitPercent)
 	}
 
 	printTable([]string{
 		"Agent's administrator",
 		"Agent in default",
 	}, []string{
-		agentAdmin.String(),
-		fmt.Sprintf("%t", defaulted),
+		health.Administrator,
+		fmt.Sprintf("%t", health.Defaulted),
 	})
 	fmt.Println()
-
-	return nil
 }
 
 func formatSinceDuration(t1 time.Time, t2 time.Time) string {
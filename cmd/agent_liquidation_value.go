@@ -7,9 +7,7 @@ import (
 	"fmt"
 	"log"
 	"math/big"
-	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/glifio/go-pools/terminate"
 	"github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
@@ -26,8 +24,10 @@ var liquidationValueCmd = &cobra.Command{
 			logFatal(err)
 		}
 
-		log.Printf("Fetching liquidation value for %s", util.TruncateAddr(agentAddr.String()))
-		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+		if !structuredOutput() {
+			log.Printf("Fetching liquidation value for %s", util.TruncateAddr(agentAddr.String()))
+		}
+		s := newSpinner()
 		s.Start()
 		defer s.Stop()
 
@@ -39,16 +39,15 @@ var liquidationValueCmd = &cobra.Command{
 
 		ats := agentCollateralStats.Summarize()
 
-		minersKeys := []string{
-			"Miner liquidation values",
-		}
-
-		minersValues := []string{
-			"",
+		out := &liquidationValueOutput{
+			Agent:               agentAddr.String(),
+			LiquidationValue:    NewFILAmount(ats.LiquidationValue()),
+			RecoveryRatePercent: bigIntAttoToPercentFloat64(ats.RecoveryRate()),
+			LiquidFIL:           NewFILAmount(ats.AgentAvailableBal),
+			Miners:              []minerLiquidationValue{},
 		}
 
 		for _, minerCollateral := range agentCollateralStats.MinersTerminationStats {
-			minersKeys = append(minersKeys, fmt.Sprintf("%s", minerCollateral.Address))
 			// here we instantiate a PreviewAgentTerminationSummary type to reuse its liquidation value and recovery rate funcs
 			ts := terminate.PreviewAgentTerminationSummary{
 				TerminationPenalty: minerCollateral.TerminationPenalty,
@@ -58,32 +57,65 @@ var liquidationValueCmd = &cobra.Command{
 				AgentAvailableBal:  big.NewInt(0),
 			}
 
-			minersValues = append(minersValues, fmt.Sprintf("%0.04f FIL (%0.02f%%)", util.ToFIL(ts.LiquidationValue()), bigIntAttoToPercent(ts.RecoveryRate())))
+			out.Miners = append(out.Miners, minerLiquidationValue{
+				Miner:               minerCollateral.Address.String(),
+				LiquidationValue:    NewFILAmount(ts.LiquidationValue()),
+				RecoveryRatePercent: bigIntAttoToPercentFloat64(ts.RecoveryRate()),
+			})
 		}
 
-		agentCollateralStatsKeys := []string{
-			"Agent liquidation value",
-		}
+		printOutput(out, func() {
+			minersKeys := []string{
+				"Miner liquidation values",
+			}
 
-		agentCollateralStatsVals := []string{
-			fmt.Sprintf("%0.03f FIL (%0.02f%% recovery)", util.ToFIL(ats.LiquidationValue()), bigIntAttoToPercent(ats.RecoveryRate())),
-		}
+			minersValues := []string{
+				"",
+			}
 
-		agentLiquidFILKey := []string{
-			"Agent's liquid FIL",
-		}
+			for _, m := range out.Miners {
+				minersKeys = append(minersKeys, m.Miner)
+				minersValues = append(minersValues, fmt.Sprintf("%0.04f FIL (%0.02f%%)", filAmountToFloat(m.LiquidationValue), m.RecoveryRatePercent))
+			}
 
-		agentLiquidFILValue := []string{
-			fmt.Sprintf("%0.04f FIL", util.ToFIL(ats.AgentAvailableBal)),
-		}
+			agentCollateralStatsKeys := []string{
+				"Agent liquidation value",
+			}
 
-		printTable(agentCollateralStatsKeys, agentCollateralStatsVals)
-		printTable(agentLiquidFILKey, agentLiquidFILValue)
-		printTable(minersKeys, minersValues)
-		fmt.Println()
+			agentCollateralStatsVals := []string{
+				fmt.Sprintf("%0.03f FIL (%0.02f%% recovery)", filAmountToFloat(out.LiquidationValue), out.RecoveryRatePercent),
+			}
+
+			agentLiquidFILKey := []string{
+				"Agent's liquid FIL",
+			}
+
+			agentLiquidFILValue := []string{
+				fmt.Sprintf("%0.04f FIL", filAmountToFloat(out.LiquidFIL)),
+			}
+
+			printTable(agentCollateralStatsKeys, agentCollateralStatsVals)
+			printTable(agentLiquidFILKey, agentLiquidFILValue)
+			printTable(minersKeys, minersValues)
+			fmt.Println()
+		})
 	},
 }
 
+type liquidationValueOutput struct {
+	Agent               string                  `json:"agent"`
+	LiquidationValue    FILAmount               `json:"liquidation_value"`
+	RecoveryRatePercent float64                 `json:"recovery_rate_percent"`
+	LiquidFIL           FILAmount               `json:"liquid_fil"`
+	Miners              []minerLiquidationValue `json:"miners"`
+}
+
+type minerLiquidationValue struct {
+	Miner               string    `json:"miner"`
+	LiquidationValue    FILAmount `json:"liquidation_value"`
+	RecoveryRatePercent float64   `json:"recovery_rate_percent"`
+}
+
 func init() {
 	agentCmd.AddCommand(liquidationValueCmd)
 	liquidationValueCmd.Flags().String("agent-addr", "", "Agent address")
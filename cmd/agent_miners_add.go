@@ -5,7 +5,7 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -54,19 +54,18 @@ var addCmd = &cobra.Command{
 			logFatalf("Miner %s has a different owner (%s) and beneficiary (%s). Please reset the miner's beneficiary to match the owner before adding", minerAddr, mi.Owner, mi.Beneficiary)
 		}
 
-		log.Printf("Adding miner %s to agent %s", minerAddr, agentAddr)
+		slog.Info("Adding miner to agent", logKeyAgent, agentAddr.String(), logKeyMiner, minerAddr.String())
 
 		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 		s.Start()
 		defer s.Stop()
 
-		addminerevt := journal.RegisterEventType("agent", "addminer")
 		evt := &events.AgentAddMiner{
 			AgentID: agentAddr.String(),
 			MinerID: minerAddr.String(),
 		}
+		txj := newTxJournal("agent", "addminer", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(addminerevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentAddMiner(
 			cmd.Context(),
@@ -76,15 +75,18 @@ var addCmd = &cobra.Command{
 			requesterKey,
 		)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
+		}
+		if unsignedOutFlag != "" {
+			s.Stop()
+			writeUnsignedTx(txj, tx, auth.From, fmt.Sprintf("Add miner %s to agent %s", minerAddr, agentAddr))
+			return
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -96,5 +98,6 @@ var addCmd = &cobra.Command{
 
 func init() {
 	minersCmd.AddCommand(addCmd)
+	addUnsignedOutFlag(addCmd)
 	addCmd.Flags().BoolVar(&addPreview, "preview", false, "preview the financial outcome of an add miner action")
 }
@@ -9,7 +9,6 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/builtin"
-	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/actors"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
@@ -67,15 +66,19 @@ var changeOwnerCmd = &cobra.Command{
 			logFatal(err)
 		}
 
-		changeownerevt := journal.RegisterEventType("miner", "changeowner")
+		// the message is signed and pushed by the node, which cannot simulate it
+		if dryRunFlag {
+			logFatal("--dry-run is not supported for Filecoin messages")
+		}
+
 		evt := &events.AgentMinerChangeOwner{
 			AgentID:  agentAddr.String(),
 			MinerID:  minerAddr.String(),
 			OldOwner: mi.Owner.String(),
 			NewOwner: delegated.String(),
 		}
+		txj := newTxJournal("miner", "changeowner", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(changeownerevt, func() interface{} { return evt })
 
 		smsg, err := lapi.MpoolPushMessage(cmd.Context(), &types.Message{
 			From:   mi.Owner,
@@ -85,21 +88,15 @@ var changeOwnerCmd = &cobra.Command{
 			Params: sp,
 		}, nil)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
 
-		fmt.Println("Message CID:", smsg.Cid())
+		txj.pushed(smsg.Cid())
 
-		wait, err := lapi.StateWaitMsg(cmd.Context(), smsg.Cid(), build.MessageConfidence, 900, true)
-		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
-		}
+		fmt.Println("Message CID:", smsg.Cid())
 
-		// check it executed successfully
-		if wait.Receipt.ExitCode != 0 {
-			evt.Error = err.Error()
+		// fails if the message did not execute successfully
+		if _, err := txj.waitMsg(cmd.Context(), lapi, smsg.Cid()); err != nil {
 			logFatal(err)
 		}
 
@@ -5,7 +5,7 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -30,52 +30,52 @@ var changeWorkerCmd = &cobra.Command{
 		if err != nil {
 			logFatal(err)
 		}
-		log.Println(minerAddr)
 
 		workerAddr, err := ToMinerID(cmd.Context(), args[1])
 		if err != nil {
-			log.Print("Error parsing worker address")
+			slog.Error("Error parsing worker address")
 			logFatal(err)
 		}
-		log.Println(workerAddr)
 
 		var controlAddrs []address.Address
 		for _, arg := range args[2:] {
 			controlAddr, err := ToMinerID(cmd.Context(), arg)
 			if err != nil {
-				log.Print("Error parsing control address")
+				slog.Error("Error parsing control address")
 				logFatal(err)
 			}
 			controlAddrs = append(controlAddrs, controlAddr)
 		}
 
-		log.Printf("Changing worker address for miner %s to %s\n", minerAddr, workerAddr)
+		slog.Info("Changing worker address", logKeyMiner, minerAddr.String(), "worker", workerAddr.String())
 
 		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 		s.Start()
 		defer s.Stop()
 
-		changeworkerevt := journal.RegisterEventType("miner", "changeworker")
 		evt := &events.AgentMinerChangeWorker{
 			AgentID:    agentAddr.String(),
 			MinerID:    minerAddr.String(),
 			NewWorker:  workerAddr.String(),
 			NewControl: AddressesToStrings(controlAddrs),
 		}
+		txj := newTxJournal("miner", "changeworker", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(changeworkerevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentChangeMinerWorker(cmd.Context(), auth, agentAddr, minerAddr, workerAddr, controlAddrs)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatalf("tx error: %s", err)
+			logFatalf("tx error: %s", txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		if unsignedOutFlag != "" {
+			s.Stop()
+			writeUnsignedTx(txj, tx, auth.From, fmt.Sprintf("Change the worker of miner %s of agent %s to %s", minerAddr, agentAddr, workerAddr))
+			return
+		}
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -87,4 +87,5 @@ var changeWorkerCmd = &cobra.Command{
 
 func init() {
 	minersCmd.AddCommand(changeWorkerCmd)
+	addUnsignedOutFlag(changeWorkerCmd)
 }
@@ -5,7 +5,7 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -31,31 +31,28 @@ var confirmWorker = &cobra.Command{
 			logFatal(err)
 		}
 
-		log.Printf("Confirming worker address change for miner %s", minerAddr)
+		slog.Info("Confirming worker address change", logKeyMiner, minerAddr.String())
 
 		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 		s.Start()
 		defer s.Stop()
 
-		confirmworkerevt := journal.RegisterEventType("miner", "confirmworker")
 		evt := &events.AgentMinerConfirmWorker{
 			AgentID: agentAddr.String(),
 			MinerID: minerAddr.String(),
 		}
+		txj := newTxJournal("miner", "confirmworker", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(confirmworkerevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentConfirmMinerWorkerChange(cmd.Context(), auth, agentAddr, minerAddr)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
 )
 
@@ -31,14 +30,12 @@ var minersListCmd = &cobra.Command{
 			logFatal(err)
 		}
 
-		if len(list) == 0 {
-			fmt.Printf("Agent has no miners\n")
-			return
-		}
-
 		totalBal := big.NewInt(0)
+		out := &minersListOutput{
+			Agent:  agentAddr.String(),
+			Miners: []minerBalance{},
+		}
 
-		fmt.Printf("\033[1m%s\033[0m", "Agent's miners:\n")
 		for _, miner := range list {
 			bal, err := lapi.WalletBalance(cmd.Context(), miner)
 			if err != nil {
@@ -46,12 +43,39 @@ var minersListCmd = &cobra.Command{
 			}
 
 			totalBal = new(big.Int).Add(totalBal, bal.Int)
-			fmt.Printf("Miner %s - %0.09f FIL\n", miner, util.ToFIL(bal.Int))
+			out.Miners = append(out.Miners, minerBalance{
+				Miner:   miner.String(),
+				Balance: NewFILAmount(bal.Int),
+			})
 		}
-		fmt.Printf("\nTotal balance: %0.09f\n", util.ToFIL(totalBal))
+		out.TotalBalance = NewFILAmount(totalBal)
+
+		printOutput(out, func() {
+			if len(list) == 0 {
+				fmt.Printf("Agent has no miners\n")
+				return
+			}
+
+			fmt.Printf("\033[1m%s\033[0m", "Agent's miners:\n")
+			for _, m := range out.Miners {
+				fmt.Printf("Miner %s - %0.09f FIL\n", m.Miner, filAmountToFloat(m.Balance))
+			}
+			fmt.Printf("\nTotal balance: %0.09f\n", filAmountToFloat(out.TotalBalance))
+		})
 	},
 }
 
+type minersListOutput struct {
+	Agent        string         `json:"agent"`
+	Miners       []minerBalance `json:"miners"`
+	TotalBalance FILAmount      `json:"total_balance"`
+}
+
+type minerBalance struct {
+	Miner   string    `json:"miner"`
+	Balance FILAmount `json:"balance"`
+}
+
 func init() {
 	minersCmd.AddCommand(minersListCmd)
 	minersListCmd.Flags().String("agent-addr", "", "Agent address")
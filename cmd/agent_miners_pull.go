@@ -41,26 +41,23 @@ var pullFundsCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		pullevt := journal.RegisterEventType("agent", "pull")
 		evt := &events.AgentMinerPull{
 			AgentID: agentAddr.String(),
 			MinerID: minerAddr.String(),
 			Amount:  amount.String(),
 		}
+		txj := newTxJournal("agent", "pull", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(pullevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentPullFunds(ctx, auth, agentAddr, amount, minerAddr, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
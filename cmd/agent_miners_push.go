@@ -38,26 +38,23 @@ var pushFundsCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		pushevt := journal.RegisterEventType("agent", "push")
 		evt := &events.AgentMinerPush{
 			AgentID: agentAddr.String(),
 			MinerID: minerAddr.String(),
 			Amount:  amount.String(),
 		}
+		txj := newTxJournal("agent", "push", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(pushevt, func() interface{} { return evt })
 
 		tx, err := PoolsSDK.Act().AgentPushFunds(ctx, auth, agentAddr, amount, minerAddr, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -9,7 +9,6 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/builtin"
-	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/actors"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/glifio/glif/v2/events"
@@ -51,13 +50,17 @@ var reclaimMinerCmd = &cobra.Command{
 			logFatal(err)
 		}
 
-		reclaimevt := journal.RegisterEventType("agent", "reclaim")
+		// the message is signed and pushed by the node, which cannot simulate it
+		if dryRunFlag {
+			logFatal("--dry-run is not supported for Filecoin messages")
+		}
+
 		evt := &events.AgentMinerReclaim{
 			MinerID:  minerAddr.String(),
 			NewOwner: newOwnerAddr.String(),
 		}
+		txj := newTxJournal("agent", "reclaim", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(reclaimevt, func() interface{} { return evt })
 
 		smsg, err := lapi.MpoolPushMessage(cmd.Context(), &types.Message{
 			From:   senderAddr,
@@ -67,21 +70,15 @@ var reclaimMinerCmd = &cobra.Command{
 			Params: sp,
 		}, nil)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
 
-		fmt.Println("Message CID:", smsg.Cid())
+		txj.pushed(smsg.Cid())
 
-		wait, err := lapi.StateWaitMsg(cmd.Context(), smsg.Cid(), build.MessageConfidence, 900, true)
-		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
-		}
+		fmt.Println("Message CID:", smsg.Cid())
 
-		// check it executed successfully
-		if wait.Receipt.ExitCode != 0 {
-			evt.Error = err.Error()
+		// fails if the message did not execute successfully
+		if _, err := txj.waitMsg(cmd.Context(), lapi, smsg.Cid()); err != nil {
 			logFatal(err)
 		}
 
@@ -52,28 +52,30 @@ var rmCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		removeevt := journal.RegisterEventType("agent", "removeminer")
 		evt := &events.AgentMinerRemove{
 			AgentID:  agentAddr.String(),
 			MinerID:  minerAddr.String(),
 			NewOwner: newMinerOwnerAddr.String(),
 		}
+		txj := newTxJournal("agent", "removeminer", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(removeevt, func() interface{} { return evt })
 
 		fmt.Printf("Removing miner %s from agent %s by changing its owner address to %s\n", minerAddr, agentAddr, newMinerOwnerAddr)
 
 		tx, err := PoolsSDK.Act().AgentRemoveMiner(cmd.Context(), auth, agentAddr, minerAddr, newMinerOwnerAddr, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
+		}
+		if unsignedOutFlag != "" {
+			s.Stop()
+			writeUnsignedTx(txj, tx, auth.From, fmt.Sprintf("Remove miner %s from agent %s, passing %s as the new owner", minerAddr, agentAddr, newMinerOwnerAddr))
+			return
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -85,5 +87,6 @@ var rmCmd = &cobra.Command{
 
 func init() {
 	minersCmd.AddCommand(rmCmd)
+	addUnsignedOutFlag(rmCmd)
 	rmCmd.Flags().BoolVar(&removePreview, "preview", false, "preview the financial outcome of a remove miner action")
 }
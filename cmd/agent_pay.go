@@ -7,9 +7,10 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"time"
 
-	"github.com/briandowns/spinner"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/glifio/glif/v2/events"
 	"github.com/spf13/cobra"
 )
@@ -20,18 +21,29 @@ const (
 	Principal PaymentType = iota
 	ToCurrent
 	Custom
+	// strategies that compute the amount from the agent's state, they are
+	// configured in the autopilot.strategy section
+	EarningsShare
+	TargetRatio
+	AboveReserve
 )
 
 var toString = map[PaymentType]string{
-	Principal: "principal",
-	ToCurrent: "to-current",
-	Custom:    "custom",
+	Principal:     "principal",
+	ToCurrent:     "to-current",
+	Custom:        "custom",
+	EarningsShare: "earnings-share",
+	TargetRatio:   "target-ratio",
+	AboveReserve:  "above-reserve",
 }
 
 var toPaymentType = map[string]PaymentType{
-	"principal":  Principal,
-	"to-current": ToCurrent,
-	"custom":     Custom,
+	"principal":      Principal,
+	"to-current":     ToCurrent,
+	"custom":         Custom,
+	"earnings-share": EarningsShare,
+	"target-ratio":   TargetRatio,
+	"above-reserve":  AboveReserve,
 }
 
 func (p PaymentType) String() string {
@@ -55,14 +67,52 @@ func init() {
 }
 
 func pay(cmd *cobra.Command, args []string, paymentType PaymentType) (*big.Int, error) {
-	ctx := cmd.Context()
-	from := cmd.Flag("from").Value.String()
-	agentAddr, auth, _, requesterKey, err := commonOwnerOrOperatorSetup(ctx, from)
+	payAmt, err := payAmount(cmd.Context(), cmd, args, paymentType)
+	if err != nil {
+		return nil, err
+	}
+	return sendPayment(cmd, payAmt, paymentType)
+}
+
+// sendPayment pays payAmt to the pool selected by the pool-name flag and waits
+// for the transaction to land on chain
+func sendPayment(cmd *cobra.Command, payAmt *big.Int, paymentType PaymentType) (*big.Int, error) {
+	ptx, err := submitPayment(cmd, cmd.Flag("from").Value.String(), payAmt, paymentType, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSpinner()
+	s.Start()
+	defer s.Stop()
+
+	// transaction landed on chain or errored
+	_, err = ptx.txj.wait(cmd.Context(), ptx.tx)
 	if err != nil {
 		return nil, err
 	}
 
-	payAmt, err := payAmount(ctx, cmd, args, paymentType)
+	s.Stop()
+
+	return payAmt, nil
+}
+
+// paymentTx is a payment whose transaction was sent
+type paymentTx struct {
+	tx   *types.Transaction
+	from common.Address
+	evt  *events.AgentPay
+	txj  *txJournal
+}
+
+// submitPayment sends a payment of payAmt from the from account without
+// waiting for it to land. adjust can change the options of the transaction,
+// e.g. to replace a pending transaction with the same nonce, and the payment is
+// then journaled under the correlationID of the payment it replaces. An empty
+// correlationID starts a new one.
+func submitPayment(cmd *cobra.Command, from string, payAmt *big.Int, paymentType PaymentType, correlationID string, adjust func(*bind.TransactOpts) error) (*paymentTx, error) {
+	ctx := cmd.Context()
+	agentAddr, auth, _, requesterKey, err := commonOwnerOrOperatorSetup(ctx, from)
 	if err != nil {
 		return nil, err
 	}
@@ -74,48 +124,58 @@ func pay(cmd *cobra.Command, args []string, paymentType PaymentType) (*big.Int,
 		return nil, err
 	}
 
-	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-	s.Start()
-	defer s.Stop()
-
-	payevt := journal.RegisterEventType("agent", "pay")
 	evt := &events.AgentPay{
 		AgentID: agentAddr.String(),
 		PoolID:  poolID.String(),
 		Amount:  payAmt.String(),
 		PayType: paymentType.String(),
 	}
-	defer journal.Close()
-	defer journal.RecordEvent(payevt, func() interface{} { return evt })
+	txj := newTxJournal("agent", "pay", evt)
+	if correlationID != "" {
+		evt.SetCorrelationID(correlationID)
+	}
 
-	tx, err := PoolsSDK.Act().AgentPay(ctx, auth, agentAddr, poolID, payAmt, requesterKey)
-	if err != nil {
-		evt.Error = err.Error()
-		return nil, err
+	if adjust != nil {
+		if err := adjust(auth); err != nil {
+			return nil, txj.failed(err)
+		}
 	}
-	evt.Tx = tx.Hash().String()
 
-	// transaction landed on chain or errored
-	_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+	tx, err := PoolsSDK.Act().AgentPay(ctx, auth, agentAddr, poolID, payAmt, requesterKey)
 	if err != nil {
-		evt.Error = err.Error()
-		return nil, err
+		return nil, txj.failed(err)
 	}
+	txj.submitted(tx)
 
-	s.Stop()
+	return &paymentTx{tx: tx, from: auth.From, evt: evt, txj: txj}, nil
+}
 
-	return payAmt, nil
+// paymentPlan is the amount a payment type chose, and why
+type paymentPlan struct {
+	Type        PaymentType
+	Amount      *big.Int
+	Explanation string
 }
 
 // payAmount takes a string amount of FIL as the first value in args and
 // returns a *big.Int in attoFIL based on the paymentType specified
 func payAmount(ctx context.Context, cmd *cobra.Command, args []string, paymentType PaymentType) (*big.Int, error) {
+	plan, err := planPayment(ctx, cmd, args, paymentType)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Amount, nil
+}
+
+// planPayment computes the amount of the payment and explains how it was
+// chosen
+func planPayment(ctx context.Context, cmd *cobra.Command, args []string, paymentType PaymentType) (*paymentPlan, error) {
 	agentAddr, err := getAgentAddressWithFlags(cmd)
 	if err != nil {
 		return nil, err
 	}
 
-	var payAmt *big.Int
+	plan := &paymentPlan{Type: paymentType}
 
 	switch paymentType {
 	case Principal:
@@ -129,24 +189,29 @@ func payAmount(ctx context.Context, cmd *cobra.Command, args []string, paymentTy
 			return nil, err
 		}
 
-		payAmt = new(big.Int).Add(amount, amountOwed)
+		plan.Amount = new(big.Int).Add(amount, amountOwed)
+		plan.Explanation = fmt.Sprintf("interest owed of %s FIL plus %s FIL of principal", formatFIL(amountOwed), formatFIL(amount))
 	case ToCurrent:
 		amountOwed, err := PoolsSDK.Query().AgentInterestOwed(ctx, agentAddr, nil)
 		if err != nil {
 			return nil, err
 		}
 
-		payAmt = amountOwed
+		plan.Amount = amountOwed
+		plan.Explanation = fmt.Sprintf("interest owed of %s FIL", formatFIL(amountOwed))
 	case Custom:
 		amount, err := parseFILAmount(args[0])
 		if err != nil {
 			return nil, err
 		}
 
-		payAmt = amount
+		plan.Amount = amount
+		plan.Explanation = fmt.Sprintf("custom amount of %s FIL", formatFIL(amount))
+	case EarningsShare, TargetRatio, AboveReserve:
+		return planStrategyPayment(ctx, agentAddr, paymentType)
 	default:
 		return nil, fmt.Errorf("invalid payment type: %s", paymentType)
 	}
 
-	return payAmt, nil
+	return plan, nil
 }
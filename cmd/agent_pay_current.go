@@ -34,6 +34,7 @@ var payToCurrentCmd = &cobra.Command{
 			return
 		}
 
+		defer journal.Close()
 		payAmt, err := pay(cmd, args, ToCurrent)
 		if err != nil {
 			logFatal(err)
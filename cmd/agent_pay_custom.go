@@ -23,6 +23,7 @@ var payCustomCmd = &cobra.Command{
 			previewAction(cmd, args, constants.MethodPay)
 			return
 		}
+		defer journal.Close()
 		payAmt, err := pay(cmd, args, Custom)
 		if err != nil {
 			logFatal(err)
@@ -40,6 +40,7 @@ var payPrincipalCmd = &cobra.Command{
 			previewAction(cmd, args, constants.MethodPay)
 			return
 		}
+		defer journal.Close()
 		payAmt, err := pay(cmd, args, Principal)
 		if err != nil {
 			logFatal(err)
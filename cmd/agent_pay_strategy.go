@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/glifio/go-pools/constants"
+	"github.com/glifio/go-pools/util"
+	"github.com/spf13/viper"
+)
+
+// strategyInputs is the state of the agent the payment strategies compute
+// their amount from, all amounts are in attoFIL
+type strategyInputs struct {
+	InterestOwed         *big.Int
+	Principal            *big.Int
+	LiquidFIL            *big.Int
+	AgentValue           *big.Int
+	LiquidationValue     *big.Int
+	ExpectedDailyRewards *big.Int
+	// RecoveryRate is the share of the agent's available balance that counts
+	// towards its liquidation value, scaled by 1e18
+	RecoveryRate *big.Int
+}
+
+// planStrategyPayment reads the agent's state and the autopilot.strategy config
+// and computes the payment of the strategy
+func planStrategyPayment(ctx context.Context, agent common.Address, paymentType PaymentType) (*paymentPlan, error) {
+	agentID, err := PoolsSDK.Query().AgentID(ctx, agent)
+	if err != nil {
+		return nil, err
+	}
+
+	econ, err := econInfo(ctx, agent, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	in := strategyInputs{
+		InterestOwed:         filAmountToAtto(econ.InterestOwed),
+		Principal:            econ.agentData.Principal,
+		LiquidFIL:            filAmountToAtto(econ.LiquidFIL),
+		AgentValue:           econ.agentData.AgentValue,
+		LiquidationValue:     econ.ats.LiquidationValue(),
+		ExpectedDailyRewards: econ.agentData.ExpectedDailyRewards,
+		RecoveryRate:         econ.ats.RecoveryRate(),
+	}
+
+	switch paymentType {
+	case EarningsShare:
+		percent := viper.GetFloat64("autopilot.strategy.earnings-percent")
+		if percent <= 0 || percent > 100 {
+			return nil, fmt.Errorf("autopilot.strategy.earnings-percent must be between 0 and 100, got %v", percent)
+		}
+		return planEarningsShare(in, percent), nil
+	case TargetRatio:
+		ltv := viper.GetFloat64("autopilot.strategy.target-ltv-percent")
+		dte := viper.GetFloat64("autopilot.strategy.target-dte-percent")
+		if ltv < 0 || ltv >= 100 || dte < 0 {
+			return nil, fmt.Errorf("autopilot.strategy.target-ltv-percent must be between 0 and 100 and target-dte-percent must be positive")
+		}
+		if ltv == 0 && dte == 0 {
+			return nil, fmt.Errorf("the target-ratio payment type needs autopilot.strategy.target-ltv-percent or target-dte-percent")
+		}
+		return planTargetRatio(in, ltv, dte), nil
+	case AboveReserve:
+		reserve, err := parseFILAmount(viper.GetString("autopilot.strategy.reserve"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid autopilot.strategy.reserve: %w", err)
+		}
+		return planAboveReserve(in, reserve), nil
+	default:
+		return nil, fmt.Errorf("%s is not a payment strategy", paymentType)
+	}
+}
+
+// planEarningsShare pays the interest owed plus a share of the agent's expected
+// weekly earnings towards the principal
+func planEarningsShare(in strategyInputs, percent float64) *paymentPlan {
+	weekly := new(big.Int).Mul(in.ExpectedDailyRewards, big.NewInt(7))
+	share := new(big.Int).Mul(weekly, percentToWad(percent))
+	share.Div(share, constants.WAD)
+
+	amount := new(big.Int).Add(in.InterestOwed, share)
+	explanation := fmt.Sprintf(
+		"interest owed of %s FIL plus %v%% of the expected weekly earnings of %s FIL (%s FIL)",
+		formatFIL(in.InterestOwed), percent, formatFIL(weekly), formatFIL(share),
+	)
+	return capPayment(EarningsShare, in, amount, explanation)
+}
+
+// planTargetRatio pays the smallest amount that brings the LTV and DTE of the
+// agent under their targets, a zero target is ignored. The interest owed is
+// paid first, only the rest of the payment reduces the principal.
+func planTargetRatio(in strategyInputs, ltvPercent, dtePercent float64) *paymentPlan {
+	amount := new(big.Int).Set(in.InterestOwed)
+	reasons := []string{fmt.Sprintf("interest owed of %s FIL", formatFIL(in.InterestOwed))}
+	debt := new(big.Int).Add(in.Principal, in.InterestOwed)
+
+	if ltvPercent > 0 && in.LiquidationValue.Sign() <= 0 {
+		reasons = append(reasons, "LTV is not targeted as the agent has no liquidation value")
+	}
+	if ltvPercent > 0 && in.LiquidationValue.Sign() > 0 {
+		// the payment leaves the agent's balance, which lowers the liquidation
+		// value by the payment discounted by the recovery rate:
+		// (principal + interest - p) / (lv - p * rr) <= target
+		// p >= (principal + interest - target * lv) / (1 - target * rr)
+		target := percentToWad(ltvPercent)
+		num := new(big.Int).Mul(target, in.LiquidationValue)
+		num.Div(num, constants.WAD)
+		num.Sub(debt, num)
+
+		den := new(big.Int).Mul(target, in.RecoveryRate)
+		den.Div(den, constants.WAD)
+		den.Sub(constants.WAD, den)
+
+		ltv := fmt.Sprintf("%0.02f%%", bigIntAttoToPercentFloat64(wadRatio(in.Principal, in.LiquidationValue)))
+		switch {
+		case num.Sign() <= 0:
+			reasons = append(reasons, fmt.Sprintf("LTV of %s is under the target of %v%%", ltv, ltvPercent))
+		case den.Sign() <= 0:
+			amount = maxBig(amount, debt)
+			reasons = append(reasons, fmt.Sprintf("LTV of %s can only reach the target of %v%% by paying off the debt", ltv, ltvPercent))
+		default:
+			p := ceilDiv(new(big.Int).Mul(num, constants.WAD), den)
+			amount = maxBig(amount, p)
+			reasons = append(reasons, fmt.Sprintf("%s FIL brings the LTV from %s to the target of %v%%", formatFIL(p), ltv, ltvPercent))
+		}
+	}
+
+	if dtePercent > 0 {
+		// the payment leaves the agent's value and reduces the principal by
+		// the same amount past the interest, so the equity only drops by the
+		// interest: (principal + interest - p) / (equity - interest) <= target
+		target := percentToWad(dtePercent)
+		equity := new(big.Int).Sub(in.AgentValue, in.Principal)
+		equityAfter := new(big.Int).Sub(equity, in.InterestOwed)
+
+		dte := fmt.Sprintf("%0.02f%%", bigIntAttoToPercentFloat64(wadRatio(in.Principal, equity)))
+		if equityAfter.Sign() <= 0 {
+			amount = maxBig(amount, debt)
+			reasons = append(reasons, fmt.Sprintf("DTE of %s can only reach the target of %v%% by paying off the debt", dte, dtePercent))
+		} else {
+			allowed := new(big.Int).Mul(target, equityAfter)
+			allowed.Div(allowed, constants.WAD)
+			p := new(big.Int).Sub(debt, allowed)
+			if p.Cmp(in.InterestOwed) <= 0 {
+				reasons = append(reasons, fmt.Sprintf("DTE of %s is under the target of %v%%", dte, dtePercent))
+			} else {
+				amount = maxBig(amount, p)
+				reasons = append(reasons, fmt.Sprintf("%s FIL brings the DTE from %s to the target of %v%%", formatFIL(p), dte, dtePercent))
+			}
+		}
+	}
+
+	return capPayment(TargetRatio, in, amount, strings.Join(reasons, "; "))
+}
+
+// planAboveReserve pays the agent's liquid FIL above the reserve, and at least
+// the interest owed so that the agent stays current
+func planAboveReserve(in strategyInputs, reserve *big.Int) *paymentPlan {
+	above := new(big.Int).Sub(in.LiquidFIL, reserve)
+	if above.Cmp(in.InterestOwed) < 0 {
+		explanation := fmt.Sprintf(
+			"liquid FIL of %s FIL leaves less than the interest owed of %s FIL above the reserve of %s FIL, paying the interest owed",
+			formatFIL(in.LiquidFIL), formatFIL(in.InterestOwed), formatFIL(reserve),
+		)
+		return capPayment(AboveReserve, in, new(big.Int).Set(in.InterestOwed), explanation)
+	}
+
+	explanation := fmt.Sprintf(
+		"%s FIL of the liquid FIL of %s FIL is above the reserve of %s FIL",
+		formatFIL(above), formatFIL(in.LiquidFIL), formatFIL(reserve),
+	)
+	return capPayment(AboveReserve, in, above, explanation)
+}
+
+// capPayment limits the payment to the agent's debt, the pool refunds anything
+// paid on top of it
+func capPayment(paymentType PaymentType, in strategyInputs, amount *big.Int, explanation string) *paymentPlan {
+	debt := new(big.Int).Add(in.Principal, in.InterestOwed)
+	if amount.Cmp(debt) > 0 {
+		amount = debt
+		explanation = fmt.Sprintf("%s, capped at the debt of %s FIL", explanation, formatFIL(debt))
+	}
+	return &paymentPlan{
+		Type:        paymentType,
+		Amount:      amount,
+		Explanation: explanation,
+	}
+}
+
+// percentToWad converts a percentage to a ratio scaled by 1e18
+func percentToWad(percent float64) *big.Int {
+	wad, _ := new(big.Float).Mul(big.NewFloat(percent), big.NewFloat(1e16)).Int(nil)
+	return wad
+}
+
+// wadRatio returns a / b scaled by 1e18, or 0 when b is not positive
+func wadRatio(a, b *big.Int) *big.Int {
+	if b.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	r := new(big.Int).Mul(a, constants.WAD)
+	return r.Div(r, b)
+}
+
+func ceilDiv(a, b *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(a, b, new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func formatFIL(atto *big.Int) string {
+	return fmt.Sprintf("%0.09f", util.ToFIL(atto))
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/glifio/go-pools/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func fil(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), constants.WAD)
+}
+
+func testStrategyInputs() strategyInputs {
+	return strategyInputs{
+		InterestOwed:         fil(1),
+		Principal:            fil(100),
+		LiquidFIL:            fil(50),
+		AgentValue:           fil(301),
+		LiquidationValue:     fil(200),
+		ExpectedDailyRewards: fil(10),
+		RecoveryRate:         new(big.Int).Set(constants.WAD),
+	}
+}
+
+func TestPlanEarningsShare(t *testing.T) {
+	in := testStrategyInputs()
+
+	plan := planEarningsShare(in, 50)
+	assert.Equal(t, EarningsShare, plan.Type)
+	// 1 FIL of interest plus half of 70 FIL of weekly earnings
+	assert.Equal(t, fil(36), plan.Amount)
+	assert.Contains(t, plan.Explanation, "50% of the expected weekly earnings of 70.000000000 FIL")
+
+	in.ExpectedDailyRewards = fil(100)
+	plan = planEarningsShare(in, 100)
+	assert.Equal(t, fil(101), plan.Amount)
+	assert.Contains(t, plan.Explanation, "capped at the debt of 101.000000000 FIL")
+}
+
+func TestPlanAboveReserve(t *testing.T) {
+	in := testStrategyInputs()
+
+	plan := planAboveReserve(in, fil(20))
+	assert.Equal(t, fil(30), plan.Amount)
+
+	plan = planAboveReserve(in, fil(50))
+	assert.Equal(t, fil(1), plan.Amount)
+	assert.Contains(t, plan.Explanation, "paying the interest owed")
+}
+
+func TestPlanTargetRatio(t *testing.T) {
+	in := testStrategyInputs()
+	in.InterestOwed = big.NewInt(0)
+
+	// LTV of 50%, the payment lowers the liquidation value as much as the debt
+	plan := planTargetRatio(in, 40, 0)
+	debt := new(big.Int).Sub(in.Principal, plan.Amount)
+	lv := new(big.Int).Sub(in.LiquidationValue, plan.Amount)
+	assert.LessOrEqual(t, wadRatio(debt, lv).Cmp(percentToWad(40)), 0)
+	assert.Contains(t, plan.Explanation, "brings the LTV from 50.00% to the target of 40%")
+
+	// DTE of 100 / 200, paying 50 FIL brings it to 50 / 200
+	in.AgentValue = fil(300)
+	plan = planTargetRatio(in, 0, 25)
+	assert.Equal(t, fil(50), plan.Amount)
+	assert.Contains(t, plan.Explanation, "brings the DTE from 50.00% to the target of 25%")
+
+	// already under both targets, only the interest is paid
+	in.InterestOwed = fil(1)
+	plan = planTargetRatio(in, 60, 75)
+	assert.Equal(t, fil(1), plan.Amount)
+	assert.Contains(t, plan.Explanation, "LTV of 50.00% is under the target of 60%")
+	assert.Contains(t, plan.Explanation, "DTE of 50.00% is under the target of 75%")
+}
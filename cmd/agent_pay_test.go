@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/glifio/glif/v2/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayToCurrent(t *testing.T) {
+	env := newTestEnv(t)
+	epochsPaid := env.agent.Account.EpochsPaid
+
+	res := env.run("agent", "pay", "to-current")
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "Successfully paid 1 FIL")
+
+	txs := env.sdk.Sent("AgentPay")
+	if assert.Len(t, txs, 1) {
+		assert.Equal(t, env.operator, txs[0].From)
+		assert.Equal(t, fil(1), txs[0].Amount)
+	}
+	assert.Equal(t, int64(0), env.agent.InterestOwed.Int64())
+	assert.Equal(t, 1, env.agent.Account.EpochsPaid.Cmp(epochsPaid))
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "pay"))
+}
+
+func TestPayToCurrentFallsBackToOwner(t *testing.T) {
+	env := newTestEnv(t)
+	env.sdk.SetBalance(env.operator, big.NewInt(0))
+
+	res := env.run("agent", "pay", "to-current")
+	assert.Equal(t, 0, res.code)
+	if txs := env.sdk.Sent("AgentPay"); assert.Len(t, txs, 1) {
+		assert.Equal(t, env.owner, txs[0].From)
+	}
+}
+
+func TestPayToCurrentReverted(t *testing.T) {
+	env := newTestEnv(t)
+	env.sdk.Reverts["AgentPay"] = errors.New("InsufficientLiquidity")
+
+	res := env.run("agent", "pay", "to-current")
+	assert.Equal(t, 1, res.code)
+	assert.Len(t, env.sdk.Sent("AgentPay"), 1)
+	assert.Equal(t, fil(1), env.agent.InterestOwed)
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusFailed}, env.journalStatuses("agent", "pay"))
+}
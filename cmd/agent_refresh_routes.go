@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/glifio/glif/v2/events"
 	"github.com/spf13/cobra"
 )
 
@@ -24,12 +25,19 @@ var refreshRoutesCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
+		evt := &events.AgentRefreshRoutes{
+			AgentID: agentAddr.String(),
+		}
+		txj := newTxJournal("agent", "refreshroutes", evt)
+		defer journal.Close()
+
 		tx, err := PoolsSDK.Act().AgentRefreshRoutes(ctx, auth, agentAddr)
 		if err != nil {
-			logFatalf("Failed to refresh routes %s", err)
+			logFatalf("Failed to refresh routes %s", txj.failed(err))
 		}
+		txj.submitted(tx)
 
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
 			logFatalf("Failed to refresh routes %s", err)
 		}
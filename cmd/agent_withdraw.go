@@ -37,27 +37,29 @@ var withdrawCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		withdrawevt := journal.RegisterEventType("agent", "withdraw")
 		evt := &events.AgentWithdraw{
 			AgentID: agentAddr.String(),
 			Amount:  amount.String(),
 			To:      receiver.String(),
 		}
+		txj := newTxJournal("agent", "withdraw", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(withdrawevt, func() interface{} { return evt })
 
 		fmt.Printf("Withdrawing %s FIL from your Agent", args[0])
 
 		tx, err := PoolsSDK.Act().AgentWithdraw(cmd.Context(), auth, agentAddr, receiver, amount, requesterKey)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
+		}
+		if unsignedOutFlag != "" {
+			s.Stop()
+			writeUnsignedTx(txj, tx, auth.From, fmt.Sprintf("Withdraw %s FIL from agent %s to %s", args[0], agentAddr, receiver))
+			return
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -69,4 +71,5 @@ var withdrawCmd = &cobra.Command{
 
 func init() {
 	agentCmd.AddCommand(withdrawCmd)
+	addUnsignedOutFlag(withdrawCmd)
 }
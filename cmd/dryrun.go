@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/glif/v2/events"
+)
+
+// dryRunFlag makes commands simulate their transaction against the current
+// head instead of sending it, and autopilot explain the payments that are due
+var dryRunFlag bool
+
+// dryRunOutput is the outcome of a simulated transaction
+type dryRunOutput struct {
+	From  string    `json:"from"`
+	To    string    `json:"to,omitempty"`
+	Nonce uint64    `json:"nonce"`
+	Value FILAmount `json:"value"`
+
+	// Result is the data returned by the call, hex encoded
+	Result       string `json:"result,omitempty"`
+	Reverted     bool   `json:"reverted"`
+	RevertReason string `json:"revert_reason,omitempty"`
+
+	GasEstimate uint64 `json:"gas_estimate,omitempty"`
+	BaseFee     string `json:"base_fee,omitempty"`
+	// EstimatedFee is the fee at the current base fee, MaxFee the most the
+	// transaction can cost with its fee cap
+	EstimatedFee *FILAmount `json:"estimated_fee,omitempty"`
+	MaxFee       *FILAmount `json:"max_fee,omitempty"`
+
+	// Event is the journal entry that sending the transaction would record
+	Event dryRunEvent `json:"event"`
+}
+
+type dryRunEvent struct {
+	System string         `json:"system"`
+	Event  string         `json:"event"`
+	Data   events.TxEvent `json:"data"`
+}
+
+// dryRun simulates the transaction of the action with eth_call and
+// eth_estimateGas at the current head, prints the outcome along with the
+// journal entry the action would record, and exits without sending it. The
+// exit code is 1 when the transaction would revert.
+func (j *txJournal) dryRun(tx *types.Transaction) {
+	ctx := context.Background()
+	out := &dryRunOutput{
+		Nonce: tx.Nonce(),
+		Value: NewFILAmount(tx.Value()),
+	}
+	from, err := txSender(tx)
+	if err != nil {
+		logFatal(err)
+	}
+	out.From = from.String()
+	if tx.To() != nil {
+		out.To = tx.To().String()
+	}
+
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		logFatal(err)
+	}
+	defer eapi.Close()
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	result, callErr := eapi.CallContract(ctx, msg, nil)
+	callErr = decodeRevert(callErr)
+	if callErr != nil {
+		out.Reverted, out.RevertReason = true, callErr.Error()
+	} else if len(result) > 0 {
+		out.Result = hexutil.Encode(result)
+	}
+
+	if !out.Reverted {
+		gas, err := eapi.EstimateGas(ctx, msg)
+		if err != nil {
+			logFatal(err)
+		}
+		out.GasEstimate = gas
+
+		head, err := eapi.HeaderByNumber(ctx, nil)
+		if err != nil {
+			logFatal(err)
+		}
+		if head.BaseFee != nil {
+			out.BaseFee = head.BaseFee.String()
+			price := new(big.Int).Add(head.BaseFee, tx.GasTipCap())
+			if price.Cmp(tx.GasFeeCap()) > 0 {
+				price = tx.GasFeeCap()
+			}
+			fee := NewFILAmount(new(big.Int).Mul(price, new(big.Int).SetUint64(gas)))
+			out.EstimatedFee = &fee
+		}
+		maxFee := NewFILAmount(new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(gas)))
+		out.MaxFee = &maxFee
+	}
+
+	if out.Reverted {
+		j.evt.SetFailed(callErr, nil, out.RevertReason)
+	} else {
+		j.evt.SetSubmitted("")
+	}
+	out.Event = dryRunEvent{System: j.evtType.System, Event: j.evtType.Event, Data: j.evt}
+
+	printOutput(out, func() {
+		printDryRun(out)
+	})
+	if out.Reverted {
+		Exit(1)
+	}
+	Exit(0)
+}
+
+// dryRunFailed prints the journal entry of an action that failed before its
+// transaction could be built, e.g. because gas estimation reverted
+func (j *txJournal) dryRunFailed(err error) {
+	j.evt.SetFailed(err, nil, "")
+	out := &dryRunEvent{System: j.evtType.System, Event: j.evtType.Event, Data: j.evt}
+	printOutput(out, func() {
+		fmt.Printf("Dry run, the transaction would fail: %s\n", err)
+		printDryRunEvent(out)
+	})
+}
+
+func printDryRun(out *dryRunOutput) {
+	if out.Reverted {
+		fmt.Printf("Dry run, the transaction would revert: %s\n", out.RevertReason)
+	} else {
+		fmt.Println("Dry run, the transaction would succeed")
+	}
+	fmt.Printf("  From:          %s\n", out.From)
+	if out.To != "" {
+		fmt.Printf("  To:            %s\n", out.To)
+	}
+	fmt.Printf("  Nonce:         %d\n", out.Nonce)
+	fmt.Printf("  Value:         %0.09f FIL\n", filAmountToFloat(out.Value))
+	if out.Result != "" {
+		fmt.Printf("  Result:        %s\n", out.Result)
+	}
+	if out.GasEstimate != 0 {
+		fmt.Printf("  Gas estimate:  %d\n", out.GasEstimate)
+	}
+	if out.BaseFee != "" {
+		fmt.Printf("  Base fee:      %s attoFIL\n", out.BaseFee)
+	}
+	if out.EstimatedFee != nil {
+		fmt.Printf("  Estimated fee: %0.09f FIL\n", filAmountToFloat(*out.EstimatedFee))
+	}
+	if out.MaxFee != nil {
+		fmt.Printf("  Max fee:       %0.09f FIL\n", filAmountToFloat(*out.MaxFee))
+	}
+	printDryRunEvent(&out.Event)
+}
+
+func printDryRunEvent(e *dryRunEvent) {
+	fmt.Printf("Journal entry: %s/%s  %s\n", e.System, e.Event, events.Format(e.Data, chainID))
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "simulate transactions against the current head and print their outcome and fees, without sending them")
+}
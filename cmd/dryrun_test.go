@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBorrowDryRun(t *testing.T) {
+	env := newTestEnv(t)
+	// the owner key is not unlocked to simulate the transaction
+	t.Setenv("GLIF_OWNER_PASSPHRASE", "wrong")
+
+	res := env.run("agent", "borrow", "10", "--dry-run")
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "would succeed")
+	assert.Contains(t, res.stdout, "Gas estimate:  1000000")
+	assert.Contains(t, res.stdout, "agent/borrow")
+	assert.Empty(t, env.sdk.Sent("AgentBorrow"))
+	assert.Equal(t, fil(100), env.agent.Account.Principal)
+	assert.Empty(t, env.journalEvents())
+}
+
+func TestWithdrawDryRunReverts(t *testing.T) {
+	env := newTestEnv(t)
+	env.sdk.Reverts["AgentWithdraw"] = errors.New("InsufficientCollateral")
+
+	res := env.run("agent", "withdraw", "1", "owner", "--dry-run")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stdout, "would revert: execution reverted: InsufficientCollateral")
+	assert.Contains(t, res.stdout, "agent/withdraw")
+	assert.Empty(t, env.sdk.Sent("AgentWithdraw"))
+	assert.Empty(t, env.journalEvents())
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/spf13/viper"
+)
+
+var (
+	maxFeeFlag         string
+	maxPriorityFeeFlag string
+)
+
+// gasPriceUnits are the units gas prices can be given in, by their power of
+// ten in attoFIL
+var gasPriceUnits = map[string]int64{
+	"":         0,
+	"afil":     0,
+	"attofil":  0,
+	"ffil":     3,
+	"femtofil": 3,
+	"pfil":     6,
+	"picofil":  6,
+	"nfil":     9,
+	"nanofil":  9,
+	"ufil":     12,
+	"microfil": 12,
+	"mfil":     15,
+	"millifil": 15,
+	"fil":      18,
+}
+
+// parseGasPrice parses a price per unit of gas in attoFIL, or in the unit
+// that follows the number, e.g. "1.5 nanoFIL"
+func parseGasPrice(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	unit := strings.TrimLeft(s, "0123456789.")
+	number := strings.TrimSpace(s[:len(s)-len(unit)])
+
+	exp, ok := gasPriceUnits[strings.ToLower(strings.TrimSpace(unit))]
+	if !ok {
+		return nil, fmt.Errorf("invalid gas price %q, unknown unit %q", s, strings.TrimSpace(unit))
+	}
+
+	r, ok := new(big.Rat).SetString(number)
+	if !ok || number == "" {
+		return nil, fmt.Errorf("invalid gas price %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("invalid gas price %q, it is not a whole number of attoFIL", s)
+	}
+	return r.Num(), nil
+}
+
+// gasFeeCaps returns the fee caps of transactions, from the --max-fee and
+// --max-priority-fee flags or the gas section of the config. A nil cap lets
+// the node suggest the fee.
+func gasFeeCaps() (maxFee *big.Int, maxPriorityFee *big.Int, err error) {
+	fee := maxFeeFlag
+	if fee == "" {
+		fee = viper.GetString("gas.max-fee")
+	}
+	priorityFee := maxPriorityFeeFlag
+	if priorityFee == "" {
+		priorityFee = viper.GetString("gas.max-priority-fee")
+	}
+
+	if fee != "" {
+		if maxFee, err = parseGasPrice(fee); err != nil {
+			return nil, nil, fmt.Errorf("invalid max fee: %w", err)
+		}
+	}
+	if priorityFee != "" {
+		if maxPriorityFee, err = parseGasPrice(priorityFee); err != nil {
+			return nil, nil, fmt.Errorf("invalid max priority fee: %w", err)
+		}
+	}
+	if maxFee != nil && maxPriorityFee != nil && maxPriorityFee.Cmp(maxFee) > 0 {
+		return nil, nil, fmt.Errorf("max priority fee %s is above the max fee %s", maxPriorityFee, maxFee)
+	}
+	return maxFee, maxPriorityFee, nil
+}
+
+// applyFeeCaps caps the fees of the transactions sent with auth to the
+// configured max fees. Fees the node suggests below the caps are left to the
+// node, only fees above them are lowered to the caps.
+func applyFeeCaps(ctx context.Context, auth *bind.TransactOpts) error {
+	maxFee, maxPriorityFee, err := gasFeeCaps()
+	if err != nil {
+		return err
+	}
+	if maxFee == nil && maxPriorityFee == nil {
+		return nil
+	}
+
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		return err
+	}
+	defer eapi.Close()
+
+	tip, err := eapi.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the suggested priority fee: %w", err)
+	}
+	head, err := eapi.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get the base fee: %w", err)
+	}
+	auth.GasFeeCap, auth.GasTipCap = cappedFees(tip, head.BaseFee, maxFee, maxPriorityFee)
+	return nil
+}
+
+// cappedFees returns the fee cap and priority fee of a transaction whose
+// suggested priority fee is tip, at baseFee, or nil for the fees that are
+// below their cap and left to the node to suggest
+func cappedFees(tip, baseFee, maxFee, maxPriorityFee *big.Int) (feeCap *big.Int, tipCap *big.Int) {
+	if maxPriorityFee != nil && tip.Cmp(maxPriorityFee) > 0 {
+		tip, tipCap = maxPriorityFee, maxPriorityFee
+	}
+	if maxFee == nil {
+		return nil, tipCap
+	}
+	if tip.Cmp(maxFee) > 0 {
+		tip = maxFee
+	}
+	suggested := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	if suggested.Cmp(maxFee) > 0 {
+		// the node only suggests the priority fee once the fee cap is set
+		return maxFee, tip
+	}
+	return nil, tipCap
+}
+
+// bumpFees raises the fees of a pending transaction by percent, for a
+// transaction that replaces it. Bumped fees above the configured max fee are an
+// error, as a replacement with a lower fee cap is rejected by the mpool.
+func bumpFees(feeCap, tipCap *big.Int, percent int64) (*big.Int, *big.Int, error) {
+	feeCap, tipCap = bumpFee(feeCap, percent), bumpFee(tipCap, percent)
+
+	maxFee, _, err := gasFeeCaps()
+	if err != nil {
+		return nil, nil, err
+	}
+	if maxFee != nil {
+		if tipCap.Cmp(maxFee) > 0 {
+			return nil, nil, fmt.Errorf("bumped priority fee %s is above the max fee %s", tipCap, maxFee)
+		}
+		if feeCap.Cmp(maxFee) > 0 {
+			return nil, nil, fmt.Errorf("bumped fee cap %s is above the max fee %s", feeCap, maxFee)
+		}
+	}
+	return feeCap, tipCap, nil
+}
+
+// bumpFee raises fee by percent, rounding up
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped = ceilDiv(bumped, big.NewInt(100))
+	return bumped.Add(bumped, big.NewInt(1))
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&maxFeeFlag, "max-fee", "", "maximum fee per unit of gas of transactions, in attoFIL or with a unit, e.g. '2 nanoFIL'")
+	rootCmd.PersistentFlags().StringVar(&maxPriorityFeeFlag, "max-priority-fee", "", "maximum priority fee per unit of gas of transactions, in attoFIL or with a unit")
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGasPrice(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *big.Int
+	}{
+		{"100", big.NewInt(100)},
+		{"100 attoFIL", big.NewInt(100)},
+		{"2 nanoFIL", big.NewInt(2e9)},
+		{"1.5nfil", big.NewInt(1.5e9)},
+		{"0.001 FIL", big.NewInt(1e15)},
+	}
+	for _, tt := range tests {
+		got, err := parseGasPrice(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, got, tt.input)
+	}
+
+	for _, input := range []string{"", "nanoFIL", "2 gwei", "0.5 attoFIL", "-1"} {
+		_, err := parseGasPrice(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestDeferForGas(t *testing.T) {
+	limit := big.NewInt(1000)
+
+	assert.False(t, deferForGas(big.NewInt(1000), limit, 10000, 2880).Deferred)
+	assert.True(t, deferForGas(big.NewInt(1001), limit, 10000, 2880).Deferred)
+	// the deadline is within the margin, the payment is made regardless
+	assert.False(t, deferForGas(big.NewInt(1001), limit, 2880, 2880).Deferred)
+	assert.False(t, deferForGas(big.NewInt(1001), limit, -5, 2880).Deferred)
+}
+
+func TestBumpFees(t *testing.T) {
+	t.Cleanup(func() { maxFeeFlag = "" })
+
+	maxFeeFlag = "1000"
+	feeCap, tipCap, err := bumpFees(big.NewInt(700), big.NewInt(100), 25)
+	if assert.NoError(t, err) {
+		assert.Equal(t, big.NewInt(876), feeCap)
+		assert.Equal(t, big.NewInt(126), tipCap)
+	}
+
+	// the original fee cap is near the max fee, lowering the bumped one to it
+	// would not replace the pending transaction
+	_, _, err = bumpFees(big.NewInt(900), big.NewInt(100), 25)
+	assert.ErrorContains(t, err, "bumped fee cap 1126 is above the max fee 1000")
+
+	_, _, err = bumpFees(big.NewInt(2000), big.NewInt(900), 25)
+	assert.ErrorContains(t, err, "bumped priority fee")
+}
+
+func TestCappedFees(t *testing.T) {
+	baseFee := big.NewInt(100)
+
+	// suggestions below the caps are left to the node
+	feeCap, tipCap := cappedFees(big.NewInt(10), baseFee, big.NewInt(1000), big.NewInt(50))
+	assert.Nil(t, feeCap)
+	assert.Nil(t, tipCap)
+
+	feeCap, tipCap = cappedFees(big.NewInt(80), baseFee, nil, big.NewInt(50))
+	assert.Nil(t, feeCap)
+	assert.Equal(t, big.NewInt(50), tipCap)
+
+	// 2 * base fee + tip is above the max fee
+	feeCap, tipCap = cappedFees(big.NewInt(10), baseFee, big.NewInt(150), nil)
+	assert.Equal(t, big.NewInt(150), feeCap)
+	assert.Equal(t, big.NewInt(10), tipCap)
+
+	feeCap, tipCap = cappedFees(big.NewInt(500), baseFee, big.NewInt(150), nil)
+	assert.Equal(t, big.NewInt(150), feeCap)
+	assert.Equal(t, big.NewInt(150), tipCap)
+}
+
+func TestFeeCapsAboveSuggestion(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := "0x00000000000000000000000000000000000000aa"
+
+	res := env.run("agent", "withdraw", "1", receiver, "--max-fee", "1 nanoFIL", "--max-priority-fee", "2000")
+	assert.Equal(t, 0, res.code)
+	if txs := env.sdk.Sent("AgentWithdraw"); assert.Len(t, txs, 1) {
+		assert.Equal(t, big.NewInt(1000), txs[0].Tx.GasTipCap())
+		assert.Equal(t, big.NewInt(1200), txs[0].Tx.GasFeeCap())
+	}
+
+	res = env.run("agent", "withdraw", "1", receiver, "--max-fee", "150", "--max-priority-fee", "20")
+	assert.Equal(t, 0, res.code)
+	if txs := env.sdk.Sent("AgentWithdraw"); assert.Len(t, txs, 2) {
+		assert.Equal(t, big.NewInt(20), txs[1].Tx.GasTipCap())
+		assert.Equal(t, big.NewInt(150), txs[1].Tx.GasFeeCap())
+	}
+}
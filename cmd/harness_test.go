@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+	"github.com/glifio/glif/v2/fakesdk"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/journal/fsjournal"
+	"github.com/glifio/glif/v2/util"
+	clk "github.com/raulk/clock"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// testConfig is the config of the commands run by the harness, tests change it
+// with writeConfig
+const testConfig = `
+[autopilot]
+payment-type = 'to-current'
+amount = 0
+frequency = 5
+[autopilot.pullfunds]
+enabled = true
+pull-amount-factor = 3
+miners = []
+reserve = 0
+[autopilot.gas]
+max-base-fee = ''
+stuck-timeout = '30m'
+fee-bump-percent = 25
+
+[journal]
+compress = false
+`
+
+const testPassphrase = "glif-test"
+
+// testEnv runs glif commands end to end against a fake SDK, in a config
+// directory with a wallet and an agent
+type testEnv struct {
+	t     *testing.T
+	dir   string
+	sdk   *fakesdk.SDK
+	clock *clk.Mock
+
+	agent     *fakesdk.Agent
+	miner     *fakesdk.Miner
+	owner     common.Address
+	operator  common.Address
+	requester common.Address
+}
+
+// runResult is the outcome of a command run by the harness
+type runResult struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+// newTestEnv sets up an agent with a miner, whose operator is funded and whose
+// interest payment is due. The global state of the package is restored when
+// the test completes.
+func newTestEnv(t *testing.T) *testEnv {
+	dir := t.TempDir()
+	e := &testEnv{t: t, dir: dir, sdk: fakesdk.New(), clock: clk.NewMock()}
+
+	// keys are encrypted with light scrypt parameters to keep tests fast, the
+	// keystore of glif decrypts them all the same
+	ks := keystore.NewKeyStore(filepath.Join(dir, "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+	newKey := func(passphrase string) common.Address {
+		acc, err := ks.NewAccount(passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return acc.Address
+	}
+	e.owner = newKey(testPassphrase)
+	e.operator = newKey(testPassphrase)
+	e.requester = newKey("")
+
+	if err := util.NewAccountsStore(filepath.Join(dir, "accounts.toml")); err != nil {
+		t.Fatal(err)
+	}
+	for key, addr := range map[util.KeyType]common.Address{
+		util.OwnerKey:    e.owner,
+		util.OperatorKey: e.operator,
+		util.RequestKey:  e.requester,
+	} {
+		if err := util.AccountsStore().Set(string(key), addr.Hex()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	agentAddr := common.HexToAddress("0x00000000000000000000000000000000000a6e47")
+	if err := util.NewAgentStore(filepath.Join(dir, "agent.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.AgentStore().Set("address", agentAddr.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	e.writeConfig(testConfig)
+
+	minerAddr, _ := address.NewIDAddress(1000)
+	e.miner = e.sdk.AddMiner(&fakesdk.Miner{
+		ID:        minerAddr,
+		Available: fil(10),
+	})
+
+	e.sdk.ChainID = big.NewInt(chainID)
+	e.sdk.Height = big.NewInt(1000000)
+	e.agent = e.sdk.AddAgent(&fakesdk.Agent{
+		Address:      agentAddr,
+		ID:           big.NewInt(1),
+		Owner:        e.owner,
+		Operator:     e.operator,
+		Requester:    e.requester,
+		Miners:       []address.Address{minerAddr},
+		LiquidAssets: fil(5),
+		InterestOwed: fil(1),
+	})
+	e.agent.Account.Principal = fil(100)
+	// six days behind, so that a payment is due every 5 days
+	e.agent.Account.EpochsPaid = big.NewInt(1000000 - 6*2880)
+	e.sdk.SetBalance(e.operator, fil(1))
+
+	t.Setenv("GLIF_CONFIG_DIR", dir)
+	t.Setenv("GLIF_BACKUP_EXISTS", "1")
+	t.Setenv("GLIF_OWNER_PASSPHRASE", testPassphrase)
+	t.Setenv("GLIF_OPERATOR_PASSPHRASE", testPassphrase)
+
+	prevCfgDir, prevLogger := cfgDir, slog.Default()
+	PoolsSDK, clock = e.sdk, e.clock
+	t.Cleanup(func() {
+		PoolsSDK, clock, cfgDir = nil, clk.New(), prevCfgDir
+		if logFile != nil {
+			logFile.Close()
+		}
+		logOutput, logFile = os.Stderr, nil
+		slog.SetDefault(prevLogger)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		viper.Reset()
+	})
+	return e
+}
+
+// writeConfig replaces the config file of the environment
+func (e *testEnv) writeConfig(config string) {
+	if err := os.WriteFile(filepath.Join(e.dir, "config.toml"), []byte(config), 0644); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// run executes glif with args as main does, capturing its output and its exit
+// code. Flags and config are reset first, so that runs do not leak into each
+// other.
+func (e *testEnv) run(args ...string) runResult {
+	viper.Reset()
+	resetFlags(rootCmd)
+	ExitCode = 0
+
+	stopStdout := captureFile(e.t, &os.Stdout)
+	stopStderr := captureFile(e.t, &os.Stderr)
+
+	done := make(chan struct{})
+	go func() {
+		// Exit ends the goroutine of the command
+		defer close(done)
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err != nil && ExitCode == 0 {
+			ExitCode = 1
+		}
+	}()
+	<-done
+
+	journal.Close()
+	res := runResult{code: ExitCode, stdout: stopStdout(), stderr: stopStderr()}
+	if e.t.Failed() || testing.Verbose() {
+		e.t.Logf("glif %s exited with %d\nstdout:\n%s\nstderr:\n%s", strings.Join(args, " "), res.code, res.stdout, res.stderr)
+	}
+	return res
+}
+
+// journalEvents returns the events recorded in the journal of the environment
+func (e *testEnv) journalEvents() []jnal.Event {
+	var events []jnal.Event
+	err := fsjournal.QueryEvents(e.dir, jnal.Query{}, func(evt jnal.Event) error {
+		events = append(events, evt)
+		return nil
+	})
+	assert.NoError(e.t, err)
+	return events
+}
+
+// journalStatuses returns the statuses of the journal events of a command,
+// e.g. agent/pay, in order
+func (e *testEnv) journalStatuses(system, event string) []string {
+	var statuses []string
+	for _, evt := range e.journalEvents() {
+		if evt.EventType.System == system && evt.EventType.Event == event {
+			statuses = append(statuses, jnal.EventStatus(evt))
+		}
+	}
+	return statuses
+}
+
+// captureFile redirects *f to a pipe until the returned function is called,
+// which restores it and returns what was written
+func captureFile(t *testing.T, f **os.File) func() string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := *f
+	*f = w
+
+	var buf bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		io.Copy(&buf, r)
+	}()
+	return func() string {
+		*f = prev
+		w.Close()
+		wg.Wait()
+		r.Close()
+		return buf.String()
+	}
+}
+
+// resetFlags sets the flags of c and its subcommands back to their defaults
+func resetFlags(c *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			v.Replace(values)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.Flags().VisitAll(reset)
+	c.PersistentFlags().VisitAll(reset)
+	for _, sub := range c.Commands() {
+		resetFlags(sub)
+	}
+}
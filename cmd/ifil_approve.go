@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/glifio/glif/v2/events"
 	"github.com/spf13/cobra"
 )
 
@@ -38,12 +39,20 @@ var iFILApproveCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
+		evt := &events.IFILApprove{
+			Spender: addr.String(),
+			Amount:  amount.String(),
+		}
+		txj := newTxJournal("ifil", "approve", evt)
+		defer journal.Close()
+
 		tx, err := PoolsSDK.Act().IFILApprove(ctx, auth, addr, amount)
 		if err != nil {
-			logFatalf("Failed to approve iFIL %s", err)
+			logFatalf("Failed to approve iFIL %s", txj.failed(err))
 		}
+		txj.submitted(tx)
 
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
 			logFatalf("Failed to approve iFIL %s", err)
 		}
@@ -2,9 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"time"
 
-	"github.com/briandowns/spinner"
 	denoms "github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
 )
@@ -14,9 +12,11 @@ var iFILPriceCmd = &cobra.Command{
 	Short: "Get the iFIL price, denominated in FIL",
 	Long:  "Get the iFIL price, denominated in FIL. The number returned is the amount of FIL that 1 iFIL is worth.",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Print("Checking iFIL prices...")
+		if !structuredOutput() {
+			fmt.Print("Checking iFIL prices...")
+		}
 
-		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+		s := newSpinner()
 		s.Start()
 		defer s.Stop()
 
@@ -25,14 +25,22 @@ var iFILPriceCmd = &cobra.Command{
 			logFatalf("Failed to get iFIL balance %s", err)
 		}
 
-		priceFIL, _ := denoms.ToFIL(price).Float64()
-
 		s.Stop()
 
-		fmt.Printf("1 iFIL is worth %.09f FIL\n", priceFIL)
+		out := &iFILPriceOutput{Price: NewFILAmount(price)}
+
+		printOutput(out, func() {
+			priceFIL, _ := denoms.ToFIL(price).Float64()
+			fmt.Printf("1 iFIL is worth %.09f FIL\n", priceFIL)
+		})
 	},
 }
 
+// iFILPriceOutput holds the amount of FIL that 1 iFIL is worth
+type iFILPriceOutput struct {
+	Price FILAmount `json:"price"`
+}
+
 func init() {
 	iFILCmd.AddCommand(iFILPriceCmd)
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/glifio/glif/v2/events"
 	"github.com/spf13/cobra"
 )
 
@@ -40,10 +41,18 @@ var iFILTransferCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
+		evt := &events.IFILTransfer{
+			To:     addr.String(),
+			Amount: amt.String(),
+		}
+		txj := newTxJournal("ifil", "transfer", evt)
+		defer journal.Close()
+
 		tx, err := PoolsSDK.Act().IFILTransfer(ctx, auth, addr, amt)
 		if err != nil {
-			logFatalf("Failed to transfer iFIL %s", err)
+			logFatalf("Failed to transfer iFIL %s", txj.failed(err))
 		}
+		txj.submitted(tx)
 
 		eapi, err := PoolsSDK.Extern().ConnectEthClient()
 		if err != nil {
@@ -51,7 +60,7 @@ var iFILTransferCmd = &cobra.Command{
 		}
 		defer eapi.Close()
 
-		_, err = PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		_, err = txj.wait(ctx, tx)
 		if err != nil {
 			logFatalf("Failed to transfer iFIL %s", err)
 		}
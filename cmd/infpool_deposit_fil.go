@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/glifio/glif/v2/events"
 	"github.com/spf13/cobra"
 )
 
@@ -37,13 +38,21 @@ var depositFILCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
+		evt := &events.InfPoolDepositFIL{
+			Receiver: receiver.String(),
+			Amount:   amount.String(),
+		}
+		txj := newTxJournal("infpool", "depositfil", evt)
+		defer journal.Close()
+
 		tx, err := PoolsSDK.Act().InfPoolDepositFIL(ctx, auth, receiver, amount)
 		if err != nil {
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		receipt, err := PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+		receipt, err := txj.wait(ctx, tx)
 		if err != nil {
 			logFatal(err)
 		}
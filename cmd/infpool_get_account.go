@@ -7,9 +7,7 @@ import (
 	"fmt"
 	"log"
 	"math/big"
-	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
 )
@@ -24,9 +22,11 @@ var getAccountCmd = &cobra.Command{
 			logFatal(err)
 		}
 
-		fmt.Printf("Querying the Account of agent %s", agentAddr.String())
+		if !structuredOutput() {
+			fmt.Printf("Querying the Account of agent %s", agentAddr.String())
+		}
 
-		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+		s := newSpinner()
 		s.Start()
 		defer s.Stop()
 
@@ -42,17 +42,36 @@ var getAccountCmd = &cobra.Command{
 
 		s.Stop()
 
-		filPrincipal := util.ToFIL(account.Principal)
-
-		log.Printf("Account opened at epoch # %s", account.StartEpoch.String())
-		log.Printf("Outstanding principal: %0.09f", filPrincipal)
-		log.Printf("Account owes %s epoch payments", new(big.Int).Sub(new(big.Int).SetUint64(chainHeadHeight.Uint64()), account.EpochsPaid))
-		log.Printf("Account is paid up to epoch # %s", account.EpochsPaid.String())
-		log.Printf("Account in default? %v", account.Defaulted)
+		out := &poolAccountOutput{
+			Agent:       agentAddr.String(),
+			StartEpoch:  account.StartEpoch.String(),
+			Principal:   NewFILAmount(account.Principal),
+			EpochsPaid:  account.EpochsPaid.String(),
+			EpochsOwed:  new(big.Int).Sub(new(big.Int).SetUint64(chainHeadHeight.Uint64()), account.EpochsPaid).String(),
+			Defaulted:   account.Defaulted,
+			ChainHeight: chainHeadHeight.String(),
+		}
 
+		printOutput(out, func() {
+			log.Printf("Account opened at epoch # %s", out.StartEpoch)
+			log.Printf("Outstanding principal: %0.09f", util.ToFIL(account.Principal))
+			log.Printf("Account owes %s epoch payments", out.EpochsOwed)
+			log.Printf("Account is paid up to epoch # %s", out.EpochsPaid)
+			log.Printf("Account in default? %v", out.Defaulted)
+		})
 	},
 }
 
+type poolAccountOutput struct {
+	Agent       string    `json:"agent"`
+	StartEpoch  string    `json:"start_epoch"`
+	Principal   FILAmount `json:"principal"`
+	EpochsPaid  string    `json:"epochs_paid"`
+	EpochsOwed  string    `json:"epochs_owed"`
+	Defaulted   bool      `json:"defaulted"`
+	ChainHeight string    `json:"chain_height"`
+}
+
 func init() {
 	infinitypoolCmd.AddCommand(getAccountCmd)
 	getAccountCmd.Flags().String("agent-addr", "", "Address of the Agent")
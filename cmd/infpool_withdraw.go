@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/glifio/glif/v2/events"
 	"github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
 )
@@ -41,13 +42,22 @@ var withdrawFILCmd = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
+		evt := &events.InfPoolWithdraw{
+			Sender:   senderAccount.Address.String(),
+			Receiver: receiver.String(),
+			Amount:   amount.String(),
+		}
+		txj := newTxJournal("infpool", "withdraw", evt)
+		defer journal.Close()
+
 		tx, err := PoolsSDK.Act().RampWithdraw(cmd.Context(), auth, amount, senderAccount.Address, receiver)
 		if err != nil {
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
+		txj.submitted(tx)
 
 		// transaction landed on chain or errored
-		receipt, err := PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		receipt, err := txj.wait(cmd.Context(), tx)
 		if err != nil {
 			logFatal(err)
 		}
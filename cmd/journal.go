@@ -0,0 +1,96 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/journal/forward"
+	"github.com/glifio/glif/v2/journal/fsjournal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Manage the audit log",
+}
+
+// openJournal opens the journal in the config directory with the rotation and
+// retention policy of the [journal] config section, forwarding events to the
+// sinks of the [journal.sinks] section
+func openJournal() (jnal.Journal, error) {
+	sinks, err := journalSinksFromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	fwd := forward.NewForwarder(sinks...)
+	opts := journalOptions()
+	opts.Written = fwd.Forward
+	j, err := fsjournal.OpenFSJournalWithOptions(cfgDir, nil, opts)
+	if err != nil {
+		fwd.Close()
+		return nil, err
+	}
+	return forward.NewJournal(j, fwd), nil
+}
+
+func journalSinksFromConfig() ([]forward.Sink, error) {
+	sinks := []forward.Sink{}
+
+	if viper.GetBool("journal.sinks.stdout") {
+		sinks = append(sinks, forward.NewWriterSink("stdout", os.Stdout))
+	}
+
+	if network := viper.GetString("journal.sinks.syslog.network"); network != "" {
+		s, err := forward.NewSyslogSink(
+			network,
+			viper.GetString("journal.sinks.syslog.address"),
+			viper.GetString("journal.sinks.syslog.facility"),
+			viper.GetString("journal.sinks.syslog.app-name"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	if url := viper.GetString("journal.sinks.http.url"); url != "" {
+		s, err := forward.NewHTTPSink(forward.HTTPSinkOptions{
+			URL:           url,
+			Headers:       viper.GetStringMapString("journal.sinks.http.headers"),
+			BatchSize:     viper.GetInt("journal.sinks.http.batch-size"),
+			FlushInterval: viper.GetDuration("journal.sinks.http.flush-interval"),
+			MaxRetries:    viper.GetInt("journal.sinks.http.max-retries"),
+			SpoolDir:      filepath.Join(cfgDir, "journal", "spool"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	return sinks, nil
+}
+
+func journalOptions() fsjournal.Options {
+	opts := fsjournal.DefaultOptions()
+	if viper.IsSet("journal.max-size-mb") {
+		opts.MaxSize = viper.GetInt64("journal.max-size-mb") << 20
+	}
+	if viper.IsSet("journal.compress") {
+		opts.Compress = viper.GetBool("journal.compress")
+	}
+	opts.MaxAge = viper.GetDuration("journal.max-age")
+	opts.MaxFiles = viper.GetInt("journal.max-files")
+	opts.MaxFileAge = viper.GetDuration("journal.max-file-age")
+	return opts
+}
+
+func init() {
+	rootCmd.AddCommand(journalCmd)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/glifio/glif/v2/journal/fsjournal"
+	"github.com/spf13/cobra"
+)
+
+var journalVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that the audit log has not been altered",
+	Long: `Walks every journal file and checks the hash chain that links each entry to the
+one before it. Edited entries are reported as broken links, deleted entries as
+missing ranges, and entries moved around as reordered.
+
+Record the last sequence number and head hash printed by this command to later
+prove that no entries were removed from the end of the journal.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := fsjournal.Verify(cfgDir)
+		if err != nil {
+			logFatal(err)
+		}
+
+		printOutput(report, func() {
+			fmt.Printf("Verified %d entries in %d files\n", report.Entries, report.Files)
+			if report.Unchained > 0 {
+				fmt.Printf("%d entries were recorded before the journal was hash chained and cannot be verified\n", report.Unchained)
+			}
+			if report.LastSeq > 0 {
+				fmt.Printf("Entries: %d to %d\n", report.FirstSeq, report.LastSeq)
+			}
+			if report.HeadHash != "" {
+				fmt.Printf("Head hash: %s\n", report.HeadHash)
+			}
+
+			if report.OK() {
+				fmt.Println("The journal is intact")
+				return
+			}
+
+			fmt.Printf("Found %d problems, the first one is where the journal was first altered:\n", len(report.Problems))
+			for _, p := range report.Problems {
+				fmt.Println(p)
+			}
+		})
+
+		if !report.OK() {
+			Exit(1)
+		}
+	},
+}
+
+func init() {
+	journalCmd.AddCommand(journalVerifyCmd)
+}
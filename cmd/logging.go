@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/glifio/glif/v2/util"
+	"github.com/spf13/viper"
+)
+
+var (
+	logLevelFlag  string
+	logFormatFlag string
+	logFileFlag   string
+)
+
+// Keys of the fields of log lines, shared by all commands so that logs can be
+// filtered on them
+const (
+	logKeyAgent   = "agent"
+	logKeyMiner   = "miner"
+	logKeyTx      = "tx"
+	logKeyStage   = "stage"
+	logKeyEpoch   = "epoch"
+	logKeyAmount  = "amount_atto"
+	logKeyProfile = "profile"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var (
+	// logOutput receives the log lines, which the autopilots of profiles
+	// write to as well
+	logOutput io.Writer = os.Stderr
+	logFile   *util.RotatingFile
+	logLevel  = slog.LevelInfo
+	logFormat = logFormatText
+)
+
+// syncWriter serializes writes, so that lines of concurrent writers do not
+// interleave
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// setupLogging installs the default slog logger, at the level and in the
+// format of the --log-level and --log-format flags or the [log] config
+// section. Lines are written to file, rotated by the size of the [log] config
+// section, or to stderr when file is empty. The log package writes through
+// the same logger.
+func setupLogging(file string) error {
+	level := logLevelFlag
+	if level == "" {
+		level = viper.GetString("log.level")
+	}
+	format := logFormatFlag
+	if format == "" {
+		format = viper.GetString("log.format")
+	}
+
+	lvl := slog.LevelInfo
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("invalid log level %q, use debug, info, warn or error", level)
+		}
+	}
+	switch format = strings.ToLower(format); format {
+	case "":
+		format = logFormatText
+	case logFormatText, logFormatJSON:
+	default:
+		return fmt.Errorf("invalid log format %q, use text or json", format)
+	}
+
+	var out io.Writer = os.Stderr
+	var f *util.RotatingFile
+	if file != "" {
+		maxSize := int64(100)
+		if viper.IsSet("log.max-size-mb") {
+			maxSize = viper.GetInt64("log.max-size-mb")
+		}
+		maxFiles := 5
+		if viper.IsSet("log.max-files") {
+			maxFiles = viper.GetInt("log.max-files")
+		}
+
+		var err error
+		if f, err = util.OpenRotatingFile(file, maxSize<<20, maxFiles); err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = f
+	}
+	out = &syncWriter{w: out}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler = slog.NewTextHandler(out, opts)
+	if format == logFormatJSON {
+		handler = slog.NewJSONHandler(out, opts)
+	}
+	logger := slog.New(handler)
+	if name := os.Getenv(autopilotProfileEnv); name != "" {
+		logger = logger.With(logKeyProfile, name)
+	}
+	slog.SetDefault(logger)
+
+	if logFile != nil {
+		logFile.Close()
+	}
+	logOutput, logFile, logLevel, logFormat = out, f, lvl, format
+	return nil
+}
+
+// logFilePath is the log file of the --log-file flag or of the [log] config
+// section, logs go to stderr when it is empty
+func logFilePath() string {
+	if logFileFlag != "" {
+		return logFileFlag
+	}
+	return viper.GetString("log.file")
+}
+
+// logAmount is the log field of an amount in attoFIL
+func logAmount(amount *big.Int) slog.Attr {
+	return slog.String(logKeyAmount, amount.String())
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "", "minimum level of log lines: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormatFlag, "log-format", "", "format of log lines: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFileFlag, "log-file", "", "file to write logs to, rotated by size, logs go to stderr when empty")
+}
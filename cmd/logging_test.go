@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupLogging(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer func() {
+		logLevelFlag, logFormatFlag = "", ""
+		if logFile != nil {
+			logFile.Close()
+		}
+		logOutput, logFile, logLevel, logFormat = os.Stderr, nil, slog.LevelInfo, logFormatText
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	logLevelFlag, logFormatFlag = "warn", "json"
+	path := filepath.Join(t.TempDir(), "glif.log")
+	assert.NoError(t, setupLogging(path))
+
+	slog.Info("below the level")
+	slog.Warn("Payment is stuck", logKeyAgent, "0xagent", logAmount(big.NewInt(42)))
+	// the log package writes through the same logger, at the info level
+	log.Println("legacy line")
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Len(t, lines, 1)
+
+	var line map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "Payment is stuck", line["msg"])
+	assert.Equal(t, "0xagent", line[logKeyAgent])
+	assert.Equal(t, "42", line[logKeyAmount])
+}
+
+func TestSetupLoggingInvalid(t *testing.T) {
+	defer func() { logLevelFlag, logFormatFlag = "", "" }()
+
+	logLevelFlag = "verbose"
+	assert.Error(t, setupLogging(""))
+
+	logLevelFlag, logFormatFlag = "", "xml"
+	assert.Error(t, setupLogging(""))
+}
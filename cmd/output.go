@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat selects how query commands render their results
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+)
+
+var outputFlag string
+
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch OutputFormat(strings.ToLower(s)) {
+	case "", OutputTable:
+		return OutputTable, nil
+	case OutputJSON:
+		return OutputJSON, nil
+	case OutputYAML:
+		return OutputYAML, nil
+	default:
+		return "", fmt.Errorf("invalid output format %s, must be one of table, json or yaml", s)
+	}
+}
+
+// outputFormat returns the output format selected with the global --output flag
+func outputFormat() OutputFormat {
+	f, err := ParseOutputFormat(outputFlag)
+	if err != nil {
+		logFatal(err)
+	}
+	return f
+}
+
+// structuredOutput is true when a machine-readable format was requested, in
+// which case commands must not print anything but the document to stdout
+func structuredOutput() bool {
+	return outputFormat() != OutputTable
+}
+
+// printOutput renders v as a JSON or YAML document to stdout, or calls table
+// when the human-readable output was requested
+func printOutput(v interface{}, table func()) {
+	if err := writeOutput(os.Stdout, outputFormat(), v, table); err != nil {
+		logFatal(err)
+	}
+}
+
+func writeOutput(w io.Writer, format OutputFormat, v interface{}, table func()) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case OutputYAML:
+		b, err := marshalYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		table()
+		return nil
+	}
+}
+
+// marshalYAML encodes v to YAML using its JSON field names and ordering, so
+// that both formats describe exactly the same document
+func marshalYAML(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetYAMLStyle drops the flow and quoting styles inherited from JSON, the
+// encoder still quotes strings that would otherwise change type
+func resetYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetYAMLStyle(c)
+	}
+}
+
+// newSpinner returns a spinner that never writes to stdout when a structured
+// output format is selected
+func newSpinner() *spinner.Spinner {
+	if structuredOutput() {
+		return spinner.New(spinner.CharSets[9], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
+	}
+	return spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+}
+
+// FILAmount is the machine-readable representation of an amount of FIL. Both
+// values are strings to avoid any loss of precision in JSON parsers.
+type FILAmount struct {
+	Atto string `json:"atto"`
+	FIL  string `json:"fil"`
+}
+
+func NewFILAmount(atto *big.Int) FILAmount {
+	if atto == nil {
+		atto = big.NewInt(0)
+	}
+	return FILAmount{
+		Atto: atto.String(),
+		FIL:  attoToFILString(atto),
+	}
+}
+
+// attoToFILString formats an attoFIL amount as an exact decimal FIL string
+func attoToFILString(atto *big.Int) string {
+	wad := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+	abs := new(big.Int).Abs(atto)
+	whole, frac := new(big.Int).QuoRem(abs, wad, new(big.Int))
+
+	s := whole.String()
+	if frac.Sign() != 0 {
+		fs := fmt.Sprintf("%018s", frac.String())
+		s = s + "." + strings.TrimRight(fs, "0")
+	}
+	if atto.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(OutputTable), "output format of query commands <table|json|yaml>")
+}
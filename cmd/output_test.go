@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttoToFILString(t *testing.T) {
+	testCases := []struct {
+		atto     string
+		expected string
+	}{
+		{"0", "0"},
+		{"1", "0.000000000000000001"},
+		{"1000000000000000000", "1"},
+		{"1500000000000000000", "1.5"},
+		{"123456789012345678901", "123.456789012345678901"},
+		{"-2500000000000000000", "-2.5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.atto, func(t *testing.T) {
+			atto, _ := new(big.Int).SetString(tc.atto, 10)
+			assert.Equal(t, tc.expected, attoToFILString(atto))
+		})
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	v := struct {
+		Name   string    `json:"name"`
+		Epoch  string    `json:"epoch"`
+		Amount FILAmount `json:"amount"`
+	}{
+		Name:   "agent",
+		Epoch:  "42",
+		Amount: NewFILAmount(big.NewInt(1e18)),
+	}
+
+	var buf bytes.Buffer
+	err := writeOutput(&buf, OutputJSON, v, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "name": "agent",
+  "epoch": "42",
+  "amount": {
+    "atto": "1000000000000000000",
+    "fil": "1"
+  }
+}
+`, buf.String())
+
+	buf.Reset()
+	err = writeOutput(&buf, OutputYAML, v, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `name: agent
+epoch: "42"
+amount:
+  atto: "1000000000000000000"
+  fil: "1"
+`, buf.String())
+
+	buf.Reset()
+	called := false
+	err = writeOutput(&buf, OutputTable, v, func() { called = true })
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Empty(t, buf.String())
+}
@@ -19,12 +19,23 @@ var poolsListCmd = &cobra.Command{
 			logFatalf("Failed to get list of active pools: %s", err)
 		}
 
-		poolsStr := util.StringifyArg(poolsList)
+		out := &poolsListOutput{Pools: []string{}}
+		for _, pool := range poolsList {
+			out.Pools = append(out.Pools, pool.String())
+		}
+
+		printOutput(out, func() {
+			poolsStr := util.StringifyArg(poolsList)
 
-		fmt.Printf("Pools: %s\n", poolsStr)
+			fmt.Printf("Pools: %s\n", poolsStr)
+		})
 	},
 }
 
+type poolsListOutput struct {
+	Pools []string `json:"pools"`
+}
+
 func init() {
 	poolsCmd.AddCommand(poolsListCmd)
 }
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/glifio/go-pools/abigen"
+)
+
+// revertError explains why a call to the GLIF contracts reverted, and what to
+// do about it
+type revertError struct {
+	// Name is the custom error of the contracts, or empty for a revert with a
+	// reason string
+	Name        string
+	Reason      string
+	Explanation string
+	Hint        string
+
+	err error
+}
+
+func (e *revertError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("transaction reverted: %s", e.Reason)
+	}
+	msg := fmt.Sprintf("transaction reverted with %s", e.Name)
+	if e.Explanation != "" {
+		msg += ": " + e.Explanation
+	}
+	if e.Hint != "" {
+		msg += ". " + e.Hint
+	}
+	return msg
+}
+
+func (e *revertError) Unwrap() error {
+	return e.err
+}
+
+// contractError is what a custom error of the contracts means for the user
+type contractError struct {
+	explanation string
+	hint        string
+}
+
+var contractErrors = map[string]contractError{
+	"InvalidCredential": {
+		"the credential signed by the Agent Data Oracle is invalid or has expired",
+		"Run the command again to get a fresh credential",
+	},
+	"AgentStateRejected": {
+		"the Agent Data Oracle rejected the state of your Agent, the action would put it over the pool's LTV or DTI limits",
+		"Check `glif agent preview` for the amounts your Agent can borrow or withdraw",
+	},
+	"InsufficientLiquidity": {
+		"the pool does not have enough FIL available",
+		"Check `glif infinity-pool avail-liquidity` and try a smaller amount",
+	},
+	"InsufficientFunds": {
+		"the Agent does not hold enough liquid FIL",
+		"Pull funds from a miner with `glif agent miners pull-funds` first",
+	},
+	"Unauthorized": {
+		"the sender is not allowed to take this action on the Agent",
+		"Check that --from is the owner or operator key of the Agent, as shown by `glif wallet list`",
+	},
+	"BadAgentState": {
+		"the Agent is not in a state that allows this action, it may be faulty, defaulted or on an old version",
+		"Check `glif agent info`, and upgrade the Agent if it asks you to",
+	},
+	"PayUp": {
+		"the Agent owes fees to the pool",
+		"Make a payment with `glif agent pay to-current` first",
+	},
+	"AccountDNE": {
+		"the Agent has no account with the pool",
+		"Borrow from the pool before paying or exiting it",
+	},
+	"AlreadyDefaulted": {
+		"the Agent defaulted on the pool",
+		"",
+	},
+	"PoolShuttingDown": {
+		"the pool is shutting down and no longer lends",
+		"",
+	},
+	"RouteDNE": {
+		"the Agent routes to a contract that no longer exists",
+		"Update the routes of the Agent with `glif agent refresh-routes`",
+	},
+	"InvalidPoolID": {
+		"the pool does not exist",
+		"Check the pool name with `glif pools list`",
+	},
+	"InvalidParams": {
+		"the contract rejected the parameters of the call",
+		"Check the amounts and addresses passed to the command",
+	},
+	"InvalidState": {
+		"the contract is not in a state that allows this action",
+		"",
+	},
+	"InvalidAddress": {
+		"the address is not valid for this action",
+		"",
+	},
+	"CallFailed": {
+		"a call made by the contract failed",
+		"",
+	},
+}
+
+// revertSelector is the selector of Error(string) reverts
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// customErrors are the names of the custom errors of the contracts, by
+// selector
+var customErrors = func() map[[4]byte]string {
+	errs := map[[4]byte]string{}
+	for _, md := range []*bind.MetaData{
+		abigen.AgentMetaData,
+		abigen.AgentFactoryMetaData,
+		abigen.AgentPoliceMetaData,
+		abigen.CredParserMetaData,
+		abigen.InfinityPoolMetaData,
+		abigen.MinerRegistryMetaData,
+		abigen.PoolRegistryMetaData,
+		abigen.PoolTokenMetaData,
+		abigen.RateModuleMetaData,
+		abigen.RouterMetaData,
+		abigen.SimpleRampMetaData,
+	} {
+		parsed, err := md.GetAbi()
+		if err != nil {
+			continue
+		}
+		for name, e := range parsed.Errors {
+			var sel [4]byte
+			copy(sel[:], e.ID[:4])
+			errs[sel] = name
+		}
+	}
+	return errs
+}()
+
+var (
+	// hexDataRe matches revert data quoted in an error message
+	hexDataRe = regexp.MustCompile(`0x[0-9a-fA-F]{8,}`)
+	// revertNameRe matches a custom error already named in an error message,
+	// as go-pools and nodes report them
+	revertNameRe = regexp.MustCompile(`reverted(?: with error)?: (\w+)`)
+)
+
+// decodeRevert explains err if it is a revert of the contracts, from the
+// revert data of the RPC error or from the data or error name quoted in its
+// message. Other errors are returned as they are.
+func decodeRevert(err error) error {
+	if err == nil {
+		return nil
+	}
+	var rerr *revertError
+	if errors.As(err, &rerr) {
+		return err
+	}
+
+	var derr rpc.DataError
+	if errors.As(err, &derr) {
+		if s, ok := derr.ErrorData().(string); ok {
+			if data, decErr := hexutil.Decode(s); decErr == nil {
+				if rerr := decodeRevertData(data); rerr != nil {
+					rerr.err = err
+					return rerr
+				}
+			}
+		}
+	}
+
+	msg := err.Error()
+	for _, s := range hexDataRe.FindAllString(msg, -1) {
+		data, decErr := hexutil.Decode(s)
+		if decErr != nil {
+			continue
+		}
+		if rerr := decodeRevertData(data); rerr != nil {
+			rerr.err = err
+			return rerr
+		}
+	}
+	for _, m := range revertNameRe.FindAllStringSubmatch(msg, -1) {
+		if _, ok := contractErrors[m[1]]; ok {
+			rerr := newRevertError(m[1])
+			rerr.err = err
+			return rerr
+		}
+	}
+	return err
+}
+
+// decodeRevertData decodes the data a call reverted with, or returns nil if it
+// is not a custom error of the contracts nor a reason string
+func decodeRevertData(data []byte) *revertError {
+	if len(data) < 4 {
+		return nil
+	}
+	if bytes.Equal(data[:4], revertSelector) {
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return nil
+		}
+		return &revertError{Reason: reason}
+	}
+	var sel [4]byte
+	copy(sel[:], data[:4])
+	if name, ok := customErrors[sel]; ok {
+		return newRevertError(name)
+	}
+	return nil
+}
+
+func newRevertError(name string) *revertError {
+	ce := contractErrors[name]
+	return &revertError{Name: name, Explanation: ce.explanation, Hint: ce.hint}
+}
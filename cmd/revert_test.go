@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/glifio/glif/v2/events"
+	"github.com/glifio/glif/v2/fakesdk"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	reasonData := hexutil.Encode(append(append([]byte{}, revertSelector...),
+		hexutil.MustDecode("0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000046f6f7073")...))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"rpc data", fakesdk.CustomError("InsufficientLiquidity()"), "InsufficientLiquidity"},
+		{"wrapped rpc data", fmt.Errorf("pay: %w", fakesdk.CustomError("PayUp()")), "PayUp"},
+		{"quoted data", errors.New("Error calling contract (could not decode): message failed, revert reason: 0x82b42900"), "Unauthorized"},
+		{"go-pools name", errors.New("Transaction reverted with error: InvalidCredential"), "InvalidCredential"},
+		{"reason string", errors.New("execution reverted, data " + reasonData), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rerr *revertError
+			if assert.ErrorAs(t, decodeRevert(tt.err), &rerr) {
+				assert.Equal(t, tt.want, rerr.Name)
+				assert.ErrorIs(t, rerr, tt.err)
+			}
+		})
+	}
+
+	assert.Equal(t, "transaction reverted: oops", decodeRevert(errors.New("data "+reasonData)).Error())
+
+	plain := errors.New("connection refused")
+	assert.Equal(t, plain, decodeRevert(plain))
+	unknown := errors.New("execution reverted: InsufficientCollateral")
+	assert.Equal(t, unknown, decodeRevert(unknown))
+	assert.Nil(t, decodeRevert(nil))
+}
+
+func TestBorrowRevertExplained(t *testing.T) {
+	env := newTestEnv(t)
+	env.sdk.Reverts["AgentBorrow"] = fakesdk.CustomError("InsufficientLiquidity()")
+
+	res := env.run("agent", "borrow", "10")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "transaction reverted with InsufficientLiquidity: the pool does not have enough FIL available")
+
+	var failed []string
+	for _, evt := range env.journalEvents() {
+		if jnal.EventStatus(evt) == events.StatusFailed {
+			failed = append(failed, jnal.EventError(evt))
+		}
+	}
+	if assert.Len(t, failed, 1) {
+		assert.Contains(t, failed[0], "InsufficientLiquidity")
+		assert.Contains(t, failed[0], "avail-liquidity")
+	}
+}
@@ -27,12 +27,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	jnal "github.com/glifio/glif/v2/journal"
-	"github.com/glifio/glif/v2/journal/fsjournal"
 	"github.com/glifio/glif/v2/util"
 	"github.com/glifio/go-pools/constants"
 	"github.com/glifio/go-pools/deploy"
 	"github.com/glifio/go-pools/sdk"
 	types "github.com/glifio/go-pools/types"
+	clk "github.com/raulk/clock"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
@@ -44,6 +44,9 @@ var chainID int64 = constants.MainnetChainID
 var PoolsSDK types.PoolsSDK
 var journal jnal.Journal
 
+// clock times autopilot, tests replace it with a mock
+var clock = clk.New()
+
 var CommitHash, GoPoolsHash = func() (string, string) {
 	var ch string
 	if info, ok := debug.ReadBuildInfo(); ok {
@@ -78,6 +81,13 @@ func Execute() {
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := ParseOutputFormat(outputFlag); err != nil {
+			return err
+		}
+		initSDK()
+		return nil
+	}
 	rootCmd.PersistentFlags().StringVar(&cfgDir, "config-dir", "", "config directory")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
@@ -108,10 +118,6 @@ func initConfig() {
 	viper.SetConfigName("config")
 
 	var err error
-	if journal, err = fsjournal.OpenFSJournal(cfgDir, nil); err != nil {
-		logFatal(err)
-	}
-
 	util.NewKeyStore(fmt.Sprintf("%s/keystore", cfgDir))
 
 	if err := util.NewKeyStoreLegacy(fmt.Sprintf("%s/keys.toml", cfgDir)); err != nil {
@@ -126,6 +132,10 @@ func initConfig() {
 		logFatal(err)
 	}
 
+	if err := util.NewSignersStore(fmt.Sprintf("%s/signers.toml", cfgDir)); err != nil {
+		logFatal(err)
+	}
+
 	if err := util.NewBackupsStore(fmt.Sprintf("%s/backups.toml", cfgDir)); err != nil {
 		logFatal(err)
 	}
@@ -146,8 +156,16 @@ func initConfig() {
 		}
 	}
 
+	if err := setupLogging(logFilePath()); err != nil {
+		logFatal(err)
+	}
+
 	viper.WatchConfig()
 
+	if journal, err = openJournal(); err != nil {
+		logFatal(err)
+	}
+
 	if slices.Contains(os.Args[1:], "wallet") &&
 		(slices.Contains(os.Args[1:], "create-agent-accounts") ||
 			slices.Contains(os.Args[1:], "create-account") ||
@@ -170,6 +188,16 @@ func initConfig() {
 		}
 	}
 
+}
+
+// initSDK connects the pools SDK to the node of the config, before running
+// the commands that need it
+func initSDK() {
+	// tests set a fake SDK before running commands
+	if PoolsSDK != nil {
+		return
+	}
+
 	daemonURL := viper.GetString("daemon.rpc-url")
 	daemonToken := viper.GetString("daemon.token")
 	adoURL := viper.GetString("ado.address")
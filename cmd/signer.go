@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/glif/v2/signer"
+	"github.com/glifio/glif/v2/util"
+)
+
+// accountSigner returns the signer of the account at addr, as selected in
+// signers.toml. passphrase is only called for accounts that sign with the
+// local keystore.
+func accountSigner(addr common.Address, passphrase func() (string, error)) (signer.Signer, error) {
+	spec, err := signer.ParseSpec(util.SignersStore().SignerOf(util.AccountsStore(), addr))
+	if err != nil {
+		return nil, err
+	}
+
+	switch spec.Kind {
+	case signer.KindLotus:
+		return &signer.Lotus{Connect: connectLotusWallet}, nil
+	case signer.KindClef:
+		return signer.NewClef(spec.Target), nil
+	case signer.KindEth:
+		return signer.NewEth(spec.Target), nil
+	case signer.KindExec:
+		return &signer.Exec{Command: spec.Target}, nil
+	}
+
+	ks := util.KeyStore()
+	if !ks.HasAddress(addr) {
+		return nil, fmt.Errorf("%s is not in the keystore of %s", addr, cfgDir)
+	}
+	p, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	return &signer.Keystore{KeyStore: ks.KeyStore, Passphrase: p}, nil
+}
+
+func connectLotusWallet() (signer.WalletAPI, func(), error) {
+	if PoolsSDK == nil {
+		return nil, nil, errors.New("lotus signer needs a node, cannot sign offline")
+	}
+	lapi, closer, err := PoolsSDK.Extern().ConnectLotusClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return lapi, closer, nil
+}
+
+// signerTransactor returns the transactor of from, which signs with s
+func signerTransactor(from common.Address, s signer.Signer) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(context.Background(), from, tx, big.NewInt(chainID))
+		},
+		Context: context.Background(),
+	}
+}
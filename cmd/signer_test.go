@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/glifio/glif/v2/util"
+	toml "github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingService is a Clef signer holding the keys of a keystore
+type signingService struct {
+	ks     *keystore.KeyStore
+	signed []common.Address
+}
+
+func (s *signingService) SignTransaction(args apitypes.SendTxArgs) (map[string]interface{}, error) {
+	to := args.To.Address()
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     uint64(args.Nonce),
+		GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(),
+		Gas:       uint64(args.Gas),
+		To:        &to,
+		Value:     args.Value.ToInt(),
+		Data:      *args.Data,
+	})
+	from := args.From.Address()
+	signed, err := s.ks.SignTxWithPassphrase(accounts.Account{Address: from}, testPassphrase, tx, args.ChainID.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	s.signed = append(s.signed, from)
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": signed}, nil
+}
+
+func TestWithdrawRemoteSigner(t *testing.T) {
+	env := newTestEnv(t)
+	service := &signingService{
+		ks: keystore.NewKeyStore(filepath.Join(env.dir, "keystore"), keystore.LightScryptN, keystore.LightScryptP),
+	}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("account", service))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	t.Cleanup(server.Stop)
+
+	res := env.run("wallet", "set-signer", "owner", "clef:"+httpServer.URL)
+	assert.Equal(t, 0, res.code)
+	assert.Equal(t, "clef:"+httpServer.URL, util.SignersStore().Signer("owner"))
+
+	// accounts.toml stays flat, as older versions read it
+	var flat map[string]string
+	content, err := os.ReadFile(filepath.Join(env.dir, "accounts.toml"))
+	require.NoError(t, err)
+	require.NoError(t, toml.Unmarshal(content, &flat))
+
+	// the owner key is not unlocked by glif, only the signing service holds it
+	t.Setenv("GLIF_OWNER_PASSPHRASE", "wrong")
+	res = env.run("agent", "withdraw", "1", "owner")
+	assert.Equal(t, 0, res.code)
+	if txs := env.sdk.Sent("AgentWithdraw"); assert.Len(t, txs, 1) {
+		assert.Equal(t, env.owner, txs[0].From)
+	}
+	assert.Equal(t, []common.Address{env.owner}, service.signed)
+
+	res = env.run("wallet", "set-signer", "owner", "keystore")
+	assert.Equal(t, 0, res.code)
+	assert.Equal(t, "", util.SignersStore().Signer("owner"))
+}
+
+func TestSignOfflineLotusSigner(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	unsigned := filepath.Join(env.dir, "tx.json")
+
+	res := env.run("wallet", "set-signer", "owner", "lotus")
+	assert.Equal(t, 0, res.code)
+	res = env.run("agent", "withdraw", "1", receiver.Hex(), "--unsigned-out", unsigned)
+	assert.Equal(t, 0, res.code)
+
+	// tx sign runs offline, without a node for the lotus wallet
+	sdk := PoolsSDK
+	PoolsSDK = nil
+	defer func() { PoolsSDK = sdk }()
+	res = env.run("tx", "sign", unsigned, "--yes")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "lotus signer needs a node, cannot sign offline")
+}
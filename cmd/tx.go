@@ -0,0 +1,296 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/glifio/glif/v2/events"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/util"
+	denoms "github.com/glifio/go-pools/util"
+	"github.com/spf13/cobra"
+)
+
+// unsignedOutFlag is the file owner commands write their transaction to,
+// unsigned, instead of signing and sending it
+var unsignedOutFlag string
+
+// txFile is a transaction prepared by an owner command, to be signed offline
+// with glif tx sign and sent with glif tx broadcast
+type txFile struct {
+	ChainID     int64  `json:"chain_id"`
+	From        string `json:"from"`
+	Description string `json:"description"`
+	// System and Event are the journal type of the action, Data is its event
+	// with the correlation ID that broadcast records the transaction under
+	System string          `json:"system"`
+	Event  string          `json:"event"`
+	Data   json.RawMessage `json:"data"`
+	Signed bool            `json:"signed"`
+	// Tx carries the nonce, gas, fees and calldata, including the credential
+	// of the ADO signed by the requester key
+	Tx *types.Transaction `json:"tx"`
+}
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Track, speed up, cancel and sign transactions offline",
+	Long: `Track, speed up, cancel and sign transactions offline.
+
+The status of a transaction, and the action the journal recorded it for, are
+shown with "glif tx status", and "glif tx wait" waits for a transaction that a
+command stopped waiting for. A pending transaction is sent again with higher
+fees with "glif tx replace", or cancelled with "glif tx cancel".
+
+Owner commands run with --unsigned-out <file> write their transaction to the
+file instead of sending it. Copy the file to the machine that holds the owner
+key, sign it there with "glif tx sign", which does not need a node, and send
+the signed file with "glif tx broadcast".`,
+}
+
+// addUnsignedOutFlag adds --unsigned-out to an owner command
+func addUnsignedOutFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&unsignedOutFlag, "unsigned-out", "", "write the unsigned transaction to this file instead of sending it, to sign it offline with glif tx sign")
+}
+
+// unsignedSenders are the senders of the transactions built by
+// unsignedTransactor, which carry no signature to recover them from
+var unsignedSenders sync.Map
+
+// unsignedTransactor returns transact options that build the transaction of
+// from without signing or sending it
+func unsignedTransactor(from common.Address) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			unsignedSenders.Store(tx.Hash(), addr)
+			return tx, nil
+		},
+		NoSend: true,
+	}
+}
+
+// txSender returns the sender of a signed transaction, or of one built by
+// unsignedTransactor
+func txSender(tx *types.Transaction) (common.Address, error) {
+	if from, ok := unsignedSenders.Load(tx.Hash()); ok {
+		return from.(common.Address), nil
+	}
+	return types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+}
+
+// writeUnsignedTx writes the transaction prepared by an owner command to
+// --unsigned-out, along with the event of the action so that broadcast records
+// it in the journal
+func writeUnsignedTx(txj *txJournal, tx *types.Transaction, from common.Address, description string) {
+	if dryRunFlag {
+		txj.dryRun(tx)
+	}
+	data, err := json.Marshal(txj.evt)
+	if err != nil {
+		logFatal(err)
+	}
+	f := &txFile{
+		ChainID:     chainID,
+		From:        from.Hex(),
+		Description: description,
+		System:      txj.evtType.System,
+		Event:       txj.evtType.Event,
+		Data:        data,
+		Tx:          tx,
+	}
+	if err := writeTxFile(unsignedOutFlag, f); err != nil {
+		logFatal(err)
+	}
+
+	fmt.Printf("Unsigned transaction written to %s\n", unsignedOutFlag)
+	fmt.Printf("Sign it offline with: glif tx sign %s\n", unsignedOutFlag)
+}
+
+func readTxFile(path string) (*txFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f txFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction file %s: %w", path, err)
+	}
+	if f.Tx == nil {
+		return nil, fmt.Errorf("transaction file %s has no transaction", path)
+	}
+	return &f, nil
+}
+
+func writeTxFile(path string, f *txFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(path, b, 0600)
+}
+
+// event decodes the event of the action the transaction file was prepared by
+func (f *txFile) event() (events.TxEvent, error) {
+	e, ok := events.New(f.System, f.Event)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %s:%s", f.System, f.Event)
+	}
+	evt, ok := e.(events.TxEvent)
+	if !ok {
+		return nil, fmt.Errorf("event type %s:%s is not a transaction event", f.System, f.Event)
+	}
+	if err := json.Unmarshal(f.Data, evt); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	return evt, nil
+}
+
+func printTxFile(f *txFile) {
+	fmt.Println(f.Description)
+	fmt.Printf("  From:                     %s\n", f.From)
+	if to := f.Tx.To(); to != nil {
+		fmt.Printf("  To:                       %s\n", to)
+	}
+	fmt.Printf("  Value:                    %0.09f FIL\n", denoms.ToFIL(f.Tx.Value()))
+	fmt.Printf("  Chain ID:                 %d\n", f.ChainID)
+	fmt.Printf("  Nonce:                    %d\n", f.Tx.Nonce())
+	fmt.Printf("  Gas limit:                %d\n", f.Tx.Gas())
+	fmt.Printf("  Max fee per gas:          %s attoFIL\n", f.Tx.GasFeeCap())
+	fmt.Printf("  Max priority fee per gas: %s attoFIL\n", f.Tx.GasTipCap())
+}
+
+// signingPassphrase returns the passphrase of the owner or operator key from
+// their environment variable, of other keys from GLIF_PASSPHRASE, and prompts
+// for it otherwise
+func signingPassphrase(addr common.Address) (string, error) {
+	as := util.AccountsStore()
+	env, message := "GLIF_PASSPHRASE", "Passphrase for account"
+	if owner, _, err := as.GetAddrs(string(util.OwnerKey)); err == nil && owner == addr {
+		env, message = "GLIF_OWNER_PASSPHRASE", "Owner key passphrase"
+	} else if operator, _, err := as.GetAddrs(string(util.OperatorKey)); err == nil && operator == addr {
+		env, message = "GLIF_OPERATOR_PASSPHRASE", "Operator key passphrase"
+	}
+	if passphrase, ok := os.LookupEnv(env); ok {
+		return passphrase, nil
+	}
+
+	if err := util.KeyStore().Unlock(accounts.Account{Address: addr}, ""); err == nil {
+		util.KeyStore().Lock(addr)
+		return "", nil
+	}
+	var passphrase string
+	prompt := &survey.Password{Message: message}
+	survey.AskOne(prompt, &passphrase)
+	if passphrase == "" {
+		return "", errors.New("Aborted")
+	}
+	return passphrase, nil
+}
+
+// trackedTx is the action the journal recorded a transaction for
+type trackedTx struct {
+	system string
+	event  string
+	status string
+	evt    events.TxEvent
+}
+
+// findTrackedTx returns the latest journal entry of the action that sent the
+// transaction, or nil when the journal has none
+func findTrackedTx(hash common.Hash) (*trackedTx, error) {
+	var found *trackedTx
+	err := journal.QueryEvents(jnal.Query{Tx: hash.String()}, func(e jnal.Event) error {
+		evt, ok := events.Decode(e).Data.(events.TxEvent)
+		if !ok {
+			return nil
+		}
+		found = &trackedTx{system: e.System, event: e.Event, status: jnal.EventStatus(e), evt: evt}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// pending is whether the journal did not record the outcome of the action yet
+func (t *trackedTx) pending() bool {
+	return t.status == events.StatusSubmitted
+}
+
+// correlationID is the ID the entries of the action share
+func (t *trackedTx) correlationID() string {
+	return reflect.ValueOf(t.evt).Elem().FieldByName("CorrelationID").String()
+}
+
+// resume continues recording the lifecycle of the action
+func (t *trackedTx) resume() *txJournal {
+	return resumeTxJournal(t.system, t.event, t.evt)
+}
+
+// lookupTx returns a transaction known to the node, and its receipt once it
+// landed
+func lookupTx(ctx context.Context, eapi *ethclient.Client, hash common.Hash) (*types.Transaction, *types.Receipt, error) {
+	tx, pending, err := eapi.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil, fmt.Errorf("transaction %s not found, it may have been dropped or replaced", hash)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending {
+		return tx, nil, nil
+	}
+
+	receipt, err := eapi.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return tx, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, receipt, nil
+}
+
+// signTx signs a transaction of from with the signer of its account
+func signTx(from common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	s, err := accountSigner(from, func() (string, error) {
+		return signingPassphrase(from)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.SignTx(context.Background(), from, tx, chainID)
+}
+
+// parseBumpPercent parses the fee bump of a replacement, e.g. "20%"
+func parseBumpPercent(s string) (int64, error) {
+	percent, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(s), "%"), 10, 64)
+	if err != nil || percent <= 0 {
+		return 0, fmt.Errorf("invalid fee bump %q, must be a positive percentage, e.g. 25%%", s)
+	}
+	return percent, nil
+}
+
+func init() {
+	rootCmd.AddCommand(txCmd)
+}
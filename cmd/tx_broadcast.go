@@ -0,0 +1,76 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var txBroadcastCmd = &cobra.Command{
+	Use:   "broadcast <file>",
+	Short: "Send a transaction signed with glif tx sign and wait for its receipt",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		f, err := readTxFile(args[0])
+		if err != nil {
+			logFatal(err)
+		}
+		if !f.Signed {
+			logFatalf("%s is not signed, sign it with: glif tx sign %s", args[0], args[0])
+		}
+		if f.ChainID != chainID {
+			logFatalf("%s is for chain %d, not %d", args[0], f.ChainID, chainID)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(f.ChainID)), f.Tx)
+		if err != nil {
+			logFatal(err)
+		}
+		if sender != common.HexToAddress(f.From) {
+			logFatalf("%s is signed by %s, not %s", args[0], sender, f.From)
+		}
+
+		evt, err := f.event()
+		if err != nil {
+			logFatal(err)
+		}
+		txj := resumeTxJournal(f.System, f.Event, evt)
+		defer journal.Close()
+
+		client, err := PoolsSDK.Extern().ConnectEthClient()
+		if err != nil {
+			logFatal(err)
+		}
+		defer client.Close()
+
+		if dryRunFlag {
+			txj.dryRun(f.Tx)
+		}
+		if err := lockTxs(); err != nil {
+			logFatal(err)
+		}
+		fmt.Printf("Broadcasting transaction %s: %s\n", f.Tx.Hash(), f.Description)
+		if err := client.SendTransaction(ctx, f.Tx); err != nil {
+			logFatal(txj.failed(err))
+		}
+		txj.submitted(f.Tx)
+
+		receipt, err := txj.wait(ctx, f.Tx)
+		if err != nil {
+			logFatal(err)
+		}
+
+		fmt.Printf("Transaction %s confirmed at epoch %s\n", f.Tx.Hash(), receipt.BlockNumber)
+	},
+}
+
+func init() {
+	txCmd.AddCommand(txBroadcastCmd)
+}
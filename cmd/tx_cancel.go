@@ -0,0 +1,54 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var txCancelBump string
+
+var txCancelCmd = &cobra.Command{
+	Use:   "cancel <hash>",
+	Short: "Cancel a pending transaction",
+	Long: `Cancel a pending transaction by sending a zero value transfer from its sender
+to itself, with the same nonce and fees raised by --bump. The transaction is
+signed by the signer of its sender, see glif wallet set-signer.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		replacePendingTx(cmd, args[0], txCancelBump, "cancel", func(orig *types.Transaction, from common.Address, feeCap, tipCap *big.Int) (*types.Transaction, error) {
+			eapi, err := PoolsSDK.Extern().ConnectEthClient()
+			if err != nil {
+				return nil, err
+			}
+			defer eapi.Close()
+
+			gas, err := eapi.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &from, Value: big.NewInt(0)})
+			if err != nil {
+				return nil, err
+			}
+			return types.NewTx(&types.DynamicFeeTx{
+				ChainID:   big.NewInt(chainID),
+				Nonce:     orig.Nonce(),
+				GasTipCap: tipCap,
+				GasFeeCap: feeCap,
+				Gas:       gas,
+				To:        &from,
+				Value:     big.NewInt(0),
+			}), nil
+		})
+	},
+}
+
+func init() {
+	txCmd.AddCommand(txCancelCmd)
+	txCancelCmd.Flags().StringVar(&txCancelBump, "bump", "25%", "percentage the fees are raised by, lotus nodes require at least 25% by default")
+}
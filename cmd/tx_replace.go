@@ -0,0 +1,155 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/glif/v2/events"
+	"github.com/spf13/cobra"
+)
+
+var txReplaceBump string
+
+var txReplaceCmd = &cobra.Command{
+	Use:   "replace <hash>",
+	Short: "Send a pending transaction again with higher fees",
+	Long: `Send a pending transaction again, with the same nonce and call and its fees
+raised by --bump, so that it replaces the pending one in the mpool. The
+transaction is signed by the signer of its sender, see glif wallet set-signer.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		replacePendingTx(cmd, args[0], txReplaceBump, "replace", func(orig *types.Transaction, from common.Address, feeCap, tipCap *big.Int) (*types.Transaction, error) {
+			return types.NewTx(&types.DynamicFeeTx{
+				ChainID:   big.NewInt(chainID),
+				Nonce:     orig.Nonce(),
+				GasTipCap: tipCap,
+				GasFeeCap: feeCap,
+				Gas:       orig.Gas(),
+				To:        orig.To(),
+				Value:     orig.Value(),
+				Data:      orig.Data(),
+			}), nil
+		})
+	},
+}
+
+// replacePendingTx sends the transaction built by replacement, with the nonce
+// of the pending transaction and its fees bumped by bumpArg, and waits for it
+// to land. The action the journal recorded the pending transaction for is
+// resubmitted with the replacement and completed by its receipt, or failed
+// when it was cancelled.
+func replacePendingTx(cmd *cobra.Command, hashArg string, bumpArg string, action string, replacement func(orig *types.Transaction, from common.Address, feeCap, tipCap *big.Int) (*types.Transaction, error)) {
+	ctx := cmd.Context()
+
+	hash, err := parseTxHash(hashArg)
+	if err != nil {
+		logFatal(err)
+	}
+	bump, err := parseBumpPercent(bumpArg)
+	if err != nil {
+		logFatal(err)
+	}
+
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		logFatal(err)
+	}
+	defer eapi.Close()
+
+	orig, receipt, err := lookupTx(ctx, eapi, hash)
+	if err != nil {
+		logFatal(err)
+	}
+	if receipt != nil {
+		logFatalf("Transaction %s already landed at epoch %s", hash, receipt.BlockNumber)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(orig.ChainId()), orig)
+	if err != nil {
+		logFatal(err)
+	}
+	if err := lockTxs(); err != nil {
+		logFatal(err)
+	}
+
+	feeCap, tipCap, err := bumpFees(orig.GasFeeCap(), orig.GasTipCap(), bump)
+	if err != nil {
+		logFatalf("Cannot %s transaction %s: %s", action, hash, err)
+	}
+	unsigned, err := replacement(orig, from, feeCap, tipCap)
+	if err != nil {
+		logFatal(err)
+	}
+
+	evt := &events.TxReplace{
+		Action:   action,
+		Original: hash.String(),
+		From:     from.String(),
+		Nonce:    orig.Nonce(),
+	}
+	txj := newTxJournal("tx", action, evt)
+	defer journal.Close()
+
+	// the replacement is simulated as its sender, without asking the signer
+	// to sign a transaction that is not sent
+	if dryRunFlag {
+		unsignedSenders.Store(unsigned.Hash(), from)
+		txj.dryRun(unsigned)
+	}
+	tx, err := signTx(from, unsigned, big.NewInt(chainID))
+	if err != nil {
+		logFatal(err)
+	}
+
+	tracked, err := findTrackedTx(hash)
+	if err != nil {
+		logFatal(err)
+	}
+
+	s := newSpinner()
+	s.Start()
+	defer s.Stop()
+
+	if err := eapi.SendTransaction(ctx, tx); err != nil {
+		logFatal(txj.failed(err))
+	}
+	txj.submitted(tx)
+
+	// the replacement sends the action of the pending transaction again
+	var replaced *txJournal
+	if action == "replace" && tracked != nil && tracked.pending() {
+		replaced = tracked.resume()
+		replaced.evt.SetSubmitted(tx.Hash().String())
+		replaced.record()
+	}
+
+	receipt, err = txj.wait(ctx, tx)
+	switch {
+	case replaced != nil && err == nil:
+		replaced.confirmed(receipt)
+	case replaced != nil:
+		replaced.failedTx(ctx, tx, err)
+	case action == "cancel" && err == nil && tracked != nil && tracked.pending():
+		tracked.resume().failed(fmt.Errorf("cancelled by transaction %s", tx.Hash()))
+	}
+	if err != nil {
+		logFatal(err)
+	}
+
+	s.Stop()
+
+	if action == "cancel" {
+		fmt.Printf("Transaction %s cancelled %s at epoch %s\n", tx.Hash(), hash, receipt.BlockNumber)
+	} else {
+		fmt.Printf("Transaction %s replaced %s at epoch %s\n", tx.Hash(), hash, receipt.BlockNumber)
+	}
+}
+
+func init() {
+	txCmd.AddCommand(txReplaceCmd)
+	txReplaceCmd.Flags().StringVar(&txReplaceBump, "bump", "25%", "percentage the fees are raised by, lotus nodes require at least 25% by default")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var txSignOut string
+var txSignYes bool
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign <file>",
+	Short: "Sign a transaction prepared with --unsigned-out, offline",
+	Args:  cobra.ExactArgs(1),
+	// transactions are signed offline, without a node to connect to
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		_, err := ParseOutputFormat(outputFlag)
+		return err
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := readTxFile(args[0])
+		if err != nil {
+			logFatal(err)
+		}
+		if f.Signed {
+			logFatalf("%s is already signed", args[0])
+		}
+
+		printTxFile(f)
+		if !txSignYes {
+			var sign bool
+			prompt := &survey.Confirm{Message: "Sign this transaction?"}
+			if err := survey.AskOne(prompt, &sign); err != nil || !sign {
+				logFatal("Aborted")
+			}
+		}
+
+		signed, err := signTx(common.HexToAddress(f.From), f.Tx, big.NewInt(f.ChainID))
+		if err != nil {
+			logFatal(err)
+		}
+		f.Tx = signed
+		f.Signed = true
+
+		out := txSignOut
+		if out == "" {
+			out = strings.TrimSuffix(args[0], ".json") + ".signed.json"
+		}
+		if err := writeTxFile(out, f); err != nil {
+			logFatal(err)
+		}
+
+		fmt.Printf("Signed transaction %s written to %s\n", signed.Hash(), out)
+		fmt.Printf("Send it with: glif tx broadcast %s\n", out)
+	},
+}
+
+func init() {
+	txCmd.AddCommand(txSignCmd)
+	txSignCmd.Flags().StringVar(&txSignOut, "out", "", "file to write the signed transaction to, <file>.signed.json by default")
+	txSignCmd.Flags().BoolVar(&txSignYes, "yes", false, "sign without asking for confirmation")
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/glif/v2/events"
+	"github.com/spf13/cobra"
+)
+
+// txStatusPending is the status of a transaction that did not land yet
+const txStatusPending = "pending"
+
+var txStatusCmd = &cobra.Command{
+	Use:   "status <hash>",
+	Short: "Show whether a transaction is pending, confirmed or failed",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		hash, err := parseTxHash(args[0])
+		if err != nil {
+			logFatal(err)
+		}
+
+		eapi, err := PoolsSDK.Extern().ConnectEthClient()
+		if err != nil {
+			logFatal(err)
+		}
+		defer eapi.Close()
+
+		tx, receipt, err := lookupTx(ctx, eapi, hash)
+		if err != nil {
+			logFatal(err)
+		}
+		tracked, err := findTrackedTx(hash)
+		if err != nil {
+			logFatal(err)
+		}
+
+		out := newTxStatusOutput(ctx, tx, receipt, tracked)
+		printOutput(out, func() {
+			printTxStatus(out)
+		})
+	},
+}
+
+type txStatusOutput struct {
+	Hash      string    `json:"hash"`
+	Status    string    `json:"status"`
+	From      string    `json:"from"`
+	To        string    `json:"to,omitempty"`
+	Nonce     uint64    `json:"nonce"`
+	Value     FILAmount `json:"value"`
+	GasLimit  uint64    `json:"gas_limit"`
+	GasFeeCap string    `json:"gas_fee_cap"`
+	GasTipCap string    `json:"gas_tip_cap"`
+
+	// set once the transaction landed
+	BlockNumber       uint64     `json:"block_number,omitempty"`
+	GasUsed           uint64     `json:"gas_used,omitempty"`
+	EffectiveGasPrice string     `json:"effective_gas_price,omitempty"`
+	TotalFee          *FILAmount `json:"total_fee,omitempty"`
+	RevertReason      string     `json:"revert_reason,omitempty"`
+
+	// Action is the journal type of the action that sent the transaction, e.g.
+	// agent/pay
+	Action        string `json:"action,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+func newTxStatusOutput(ctx context.Context, tx *types.Transaction, receipt *types.Receipt, tracked *trackedTx) *txStatusOutput {
+	out := &txStatusOutput{
+		Hash:      tx.Hash().String(),
+		Status:    txStatusPending,
+		Nonce:     tx.Nonce(),
+		Value:     NewFILAmount(tx.Value()),
+		GasLimit:  tx.Gas(),
+		GasFeeCap: tx.GasFeeCap().String(),
+		GasTipCap: tx.GasTipCap().String(),
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		out.From = from.String()
+	}
+	if tx.To() != nil {
+		out.To = tx.To().String()
+	}
+	if tracked != nil {
+		out.Action = tracked.system + "/" + tracked.event
+		out.CorrelationID = tracked.correlationID()
+	}
+	if receipt == nil {
+		return out
+	}
+
+	out.Status = events.StatusConfirmed
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		out.Status = events.StatusFailed
+		_, reason := revertDetails(ctx, tx)
+		out.RevertReason = errorString(reason)
+	}
+	if receipt.BlockNumber != nil {
+		out.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	out.GasUsed = receipt.GasUsed
+	if receipt.EffectiveGasPrice != nil {
+		out.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		fee := NewFILAmount(new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)))
+		out.TotalFee = &fee
+	}
+	return out
+}
+
+func printTxStatus(out *txStatusOutput) {
+	fmt.Printf("Transaction %s is %s\n", out.Hash, out.Status)
+	if out.Action != "" {
+		fmt.Printf("  Action:                   %s\n", out.Action)
+	}
+	fmt.Printf("  From:                     %s\n", out.From)
+	if out.To != "" {
+		fmt.Printf("  To:                       %s\n", out.To)
+	}
+	fmt.Printf("  Nonce:                    %d\n", out.Nonce)
+	fmt.Printf("  Value:                    %0.09f FIL\n", filAmountToFloat(out.Value))
+	fmt.Printf("  Gas limit:                %d\n", out.GasLimit)
+	fmt.Printf("  Max fee per gas:          %s attoFIL\n", out.GasFeeCap)
+	fmt.Printf("  Max priority fee per gas: %s attoFIL\n", out.GasTipCap)
+	if out.Status == txStatusPending {
+		return
+	}
+	fmt.Printf("  Epoch:                    %d\n", out.BlockNumber)
+	fmt.Printf("  Gas used:                 %d\n", out.GasUsed)
+	if out.TotalFee != nil {
+		fmt.Printf("  Fee:                      %0.09f FIL\n", filAmountToFloat(*out.TotalFee))
+	}
+	if out.RevertReason != "" {
+		fmt.Printf("  Revert reason:            %s\n", out.RevertReason)
+	}
+}
+
+// parseTxHash parses the hash of a FEVM transaction
+func parseTxHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid transaction hash %s", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+func init() {
+	txCmd.AddCommand(txStatusCmd)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/glifio/glif/v2/events"
+	"github.com/glifio/glif/v2/fakesdk"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawSignedOffline(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	unsigned := filepath.Join(env.dir, "tx.json")
+	signed := filepath.Join(env.dir, "tx.signed.json")
+
+	// the owner key is not unlocked to prepare the transaction
+	t.Setenv("GLIF_OWNER_PASSPHRASE", "wrong")
+	res := env.run("agent", "withdraw", "2", receiver.Hex(), "--unsigned-out", unsigned)
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "glif tx sign "+unsigned)
+	assert.Empty(t, env.sdk.Sent("AgentWithdraw"))
+
+	res = env.run("tx", "broadcast", unsigned)
+	assert.Equal(t, 1, res.code)
+	assert.Empty(t, env.sdk.Sent("AgentWithdraw"))
+
+	t.Setenv("GLIF_OWNER_PASSPHRASE", testPassphrase)
+	res = env.run("tx", "sign", unsigned, "--yes")
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "Withdraw 2 FIL from agent")
+
+	res = env.run("tx", "broadcast", signed)
+	assert.Equal(t, 0, res.code)
+	if txs := env.sdk.Sent("AgentWithdraw"); assert.Len(t, txs, 1) {
+		assert.Equal(t, env.owner, txs[0].From)
+		assert.NotNil(t, txs[0].Receipt)
+	}
+	assert.Equal(t, fil(2), env.sdk.Balances[receiver])
+	assert.Equal(t, fil(3), env.agent.LiquidAssets)
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "withdraw"))
+}
+
+// stuckPayment leaves a payment of autopilot pending, and lands the
+// transactions sent after it as soon as a command waits for them
+func stuckPayment(t *testing.T, env *testEnv) *fakesdk.Tx {
+	env.sdk.HoldTxs = true
+	env.sdk.OnWait = func(*fakesdk.Tx) { env.clock.Add(30 * time.Minute) }
+	code, _ := runAutopilotOnce(t, env)
+	if !assert.Equal(t, 6, code) || !assert.Len(t, env.sdk.Sent("AgentPay"), 1) {
+		t.FailNow()
+	}
+	env.sdk.OnWait = func(*fakesdk.Tx) { env.sdk.Mine() }
+	return env.sdk.Sent("AgentPay")[0]
+}
+
+func TestTxLock(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	// another glif process, e.g. autopilot, is sending a transaction
+	l, err := util.LockFile(filepath.Join(env.dir, "tx.lock"))
+	require.NoError(t, err)
+
+	done := make(chan runResult)
+	go func() { done <- env.run("agent", "withdraw", "1", receiver.Hex()) }()
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, env.sdk.Sent("AgentWithdraw"))
+	l.Unlock()
+
+	res := <-done
+	assert.Equal(t, 0, res.code)
+	assert.Len(t, env.sdk.Sent("AgentWithdraw"), 1)
+
+	// the lock is released once the transaction is submitted
+	l, err = util.TryLockFile(filepath.Join(env.dir, "tx.lock"))
+	if assert.NoError(t, err) {
+		l.Unlock()
+	}
+}
+
+func TestWaitFailsWithoutReceipt(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	// the node cannot be reached while waiting, the transaction may still land
+	env.sdk.HoldTxs = true
+	env.sdk.WaitError = errors.New("connection refused")
+	res := env.run("agent", "withdraw", "1", receiver.Hex())
+	assert.Equal(t, 1, res.code)
+	assert.Equal(t, []string{events.StatusSubmitted}, env.journalStatuses("agent", "withdraw"))
+
+	env.sdk.WaitError = nil
+	env.sdk.Mine()
+	txs := env.sdk.Sent("AgentWithdraw")
+	if assert.Len(t, txs, 1) {
+		res = env.run("tx", "wait", txs[0].Tx.Hash().Hex())
+		assert.Equal(t, 0, res.code)
+	}
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "withdraw"))
+}
+
+func TestTxStatus(t *testing.T) {
+	env := newTestEnv(t)
+	stuck := stuckPayment(t, env)
+
+	res := env.run("tx", "status", stuck.Tx.Hash().Hex())
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "is pending")
+	assert.Contains(t, res.stdout, "agent/pay")
+
+	env.sdk.Mine()
+	res = env.run("tx", "wait", stuck.Tx.Hash().Hex())
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "is confirmed")
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "pay"))
+}
+
+func TestTxReplace(t *testing.T) {
+	env := newTestEnv(t)
+	stuck := stuckPayment(t, env)
+
+	res := env.run("tx", "replace", stuck.Tx.Hash().Hex(), "--bump", "30%")
+	assert.Equal(t, 0, res.code)
+	txs := env.sdk.Sent("AgentPay")
+	if assert.Len(t, txs, 2) {
+		assert.True(t, txs[0].Replaced)
+		assert.Equal(t, env.operator, txs[1].From)
+		assert.Equal(t, stuck.Tx.Nonce(), txs[1].Tx.Nonce())
+		assert.Equal(t, 1, txs[1].Tx.GasFeeCap().Cmp(stuck.Tx.GasFeeCap()))
+	}
+	assert.Equal(t, int64(0), env.agent.InterestOwed.Int64())
+	// the payment is resubmitted with the replacement, which completes it
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("agent", "pay"))
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("tx", "replace"))
+	if txs := env.sdk.Sent("AgentPay"); len(txs) == 2 {
+		var ids []string
+		for _, evt := range env.journalEvents() {
+			if evt.EventType.Event != "pay" {
+				continue
+			}
+			data := evt.Data.(map[string]interface{})
+			ids = append(ids, data["correlation_id"].(string))
+			if jnal.EventStatus(evt) == events.StatusConfirmed {
+				assert.Equal(t, txs[1].Tx.Hash().Hex(), data["tx"])
+			}
+		}
+		assert.Len(t, slices.Compact(ids), 1)
+	}
+}
+
+func TestTxReplaceDryRun(t *testing.T) {
+	env := newTestEnv(t)
+	stuck := stuckPayment(t, env)
+
+	// the replacement is simulated without unlocking the key of the sender
+	t.Setenv("GLIF_OPERATOR_PASSPHRASE", "wrong")
+	res := env.run("tx", "replace", stuck.Tx.Hash().Hex(), "--dry-run")
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "Dry run, the transaction would succeed")
+	assert.Contains(t, res.stdout, env.operator.Hex())
+	assert.Len(t, env.sdk.Sent("AgentPay"), 1)
+	assert.False(t, stuck.Replaced)
+}
+
+func TestTxCancel(t *testing.T) {
+	env := newTestEnv(t)
+	stuck := stuckPayment(t, env)
+
+	res := env.run("tx", "cancel", stuck.Tx.Hash().Hex())
+	assert.Equal(t, 0, res.code)
+	assert.True(t, stuck.Replaced)
+	if txs := env.sdk.Sent(""); assert.Len(t, txs, 1) {
+		assert.Equal(t, env.operator, txs[0].From)
+		assert.Equal(t, env.operator, *txs[0].Tx.To())
+		assert.Equal(t, int64(0), txs[0].Tx.Value().Int64())
+		assert.Equal(t, stuck.Tx.Nonce(), txs[0].Tx.Nonce())
+	}
+	assert.Equal(t, fil(1), env.agent.InterestOwed)
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusFailed}, env.journalStatuses("agent", "pay"))
+	assert.Equal(t, []string{events.StatusSubmitted, events.StatusConfirmed}, env.journalStatuses("tx", "cancel"))
+
+	res = env.run("tx", "replace", stuck.Tx.Hash().Hex())
+	assert.Equal(t, 1, res.code)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2023 Glif LTD
+*/
+package cmd
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var txWaitCmd = &cobra.Command{
+	Use:   "wait <hash>",
+	Short: "Wait for a transaction to land",
+	Long: `Wait for a transaction to land, for instance one that a command stopped
+waiting for when its terminal was closed. The outcome is recorded in the
+journal, under the action that sent the transaction.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		hash, err := parseTxHash(args[0])
+		if err != nil {
+			logFatal(err)
+		}
+
+		eapi, err := PoolsSDK.Extern().ConnectEthClient()
+		if err != nil {
+			logFatal(err)
+		}
+		defer eapi.Close()
+
+		tx, receipt, err := lookupTx(ctx, eapi, hash)
+		if err != nil {
+			logFatal(err)
+		}
+		tracked, err := findTrackedTx(hash)
+		if err != nil {
+			logFatal(err)
+		}
+
+		s := newSpinner()
+		s.Start()
+		defer s.Stop()
+
+		if tracked != nil && tracked.pending() {
+			txj := tracked.resume()
+			defer journal.Close()
+			if receipt, err = txj.wait(ctx, tx); err != nil {
+				logFatal(err)
+			}
+		} else if receipt == nil {
+			if receipt, err = PoolsSDK.Query().StateWaitReceipt(ctx, hash); err != nil {
+				logFatal(err)
+			}
+		}
+
+		s.Stop()
+
+		out := newTxStatusOutput(ctx, tx, receipt, tracked)
+		printOutput(out, func() {
+			printTxStatus(out)
+		})
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			Exit(1)
+		}
+	},
+}
+
+func init() {
+	txCmd.AddCommand(txWaitCmd)
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/glifio/glif/v2/events"
+	jnal "github.com/glifio/glif/v2/journal"
+	"github.com/ipfs/go-cid"
+)
+
+// txJournal records the lifecycle of an on-chain action to the journal: a
+// submitted entry once its transaction is sent, then a confirmed or failed
+// entry. All entries of the action share a correlation ID, and each one is a
+// copy of the action's event as it was when the entry was recorded.
+type txJournal struct {
+	evtType jnal.EventType
+	evt     events.TxEvent
+}
+
+func newTxJournal(system, event string, evt events.TxEvent) *txJournal {
+	evt.SetCorrelationID(events.NewCorrelationID())
+	return &txJournal{
+		evtType: journal.RegisterEventType(system, event),
+		evt:     evt,
+	}
+}
+
+// resumeTxJournal continues recording the lifecycle of an action whose
+// transaction was sent by an earlier run, under the correlation ID of evt
+func resumeTxJournal(system, event string, evt events.TxEvent) *txJournal {
+	return &txJournal{
+		evtType: journal.RegisterEventType(system, event),
+		evt:     evt,
+	}
+}
+
+// submitted records that the transaction of the action was sent, and releases
+// the lock on sending transactions. With
+// --dry-run, the transaction was only built, it is simulated instead and the
+// command exits.
+func (j *txJournal) submitted(tx *types.Transaction) {
+	if dryRunFlag {
+		j.dryRun(tx)
+	}
+	j.evt.SetSubmitted(tx.Hash().String())
+	j.record()
+	unlockTxs()
+}
+
+// pushed records that the Filecoin message of the action was pushed to the
+// mpool
+func (j *txJournal) pushed(msg cid.Cid) {
+	j.evt.SetSubmitted(msg.String())
+	j.record()
+	unlockTxs()
+}
+
+// failed records that the action failed before its transaction was sent, and
+// returns err, explained if the transaction would revert. A dry run prints the
+// entry instead of recording it.
+func (j *txJournal) failed(err error) error {
+	err = decodeRevert(err)
+	if dryRunFlag {
+		j.dryRunFailed(err)
+		return err
+	}
+	j.evt.SetFailed(err, nil, "")
+	j.record()
+	unlockTxs()
+	return err
+}
+
+// wait waits for the receipt of the transaction, and records whether the
+// transaction was confirmed or failed along with its receipt. Nothing is
+// recorded when the wait fails without a receipt.
+func (j *txJournal) wait(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := j.waitReceipt(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	j.confirmed(receipt)
+	return receipt, nil
+}
+
+// waitReceipt waits for the receipt of the transaction and only records a
+// failure, so that the caller can complete the event from the receipt before
+// recording it as confirmed
+func (j *txJournal) waitReceipt(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := PoolsSDK.Query().StateWaitReceipt(ctx, tx.Hash())
+	if err != nil {
+		_, err = j.failedTx(ctx, tx, err)
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// errTxPending is returned by waitTimeout for a transaction that did not land
+// within the timeout
+var errTxPending = errors.New("transaction is still pending")
+
+// waitTimeout is wait, but gives up once timeout passes without the
+// transaction landing. It then returns errTxPending and records nothing, as
+// the transaction may still land. The receipt of a transaction that landed
+// but failed is returned along with the error.
+func (j *txJournal) waitTimeout(ctx context.Context, tx *types.Transaction, timeout time.Duration) (*types.Receipt, error) {
+	tctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := clock.AfterFunc(timeout, cancel)
+	defer timer.Stop()
+
+	receipt, err := PoolsSDK.Query().StateWaitReceipt(tctx, tx.Hash())
+	if err != nil && ctx.Err() == nil && tctx.Err() != nil {
+		return nil, errTxPending
+	}
+	if err != nil {
+		return j.failedTx(ctx, tx, err)
+	}
+	j.confirmed(receipt)
+	return receipt, nil
+}
+
+// confirmed records that the transaction of the action landed successfully
+func (j *txJournal) confirmed(receipt *types.Receipt) {
+	j.evt.SetConfirmed(receipt)
+	j.record()
+}
+
+// failedTx handles err, returned while waiting for the transaction of the
+// action. A transaction that landed with a failed receipt is recorded as
+// failed, with the reason it reverted, and the returned error explains the
+// revert, or is err when the reason is unknown. Otherwise, e.g. when the node
+// could not be reached or the wait was cancelled, the transaction may still
+// land: nothing is recorded, and the action stays submitted until glif tx wait
+// settles it.
+func (j *txJournal) failedTx(ctx context.Context, tx *types.Transaction, err error) (*types.Receipt, error) {
+	receipt, reason := revertDetails(ctx, tx)
+	if receipt == nil || receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, err
+	}
+	if reason != nil {
+		err = reason
+	}
+	j.evt.SetFailed(err, receipt, errorString(reason))
+	j.record()
+	return receipt, err
+}
+
+// waitMsg waits for the Filecoin message to execute, and records whether it
+// succeeded along with the epoch it executed at. A message that executed with
+// a non zero exit code is returned as an error.
+func (j *txJournal) waitMsg(ctx context.Context, lapi api.FullNode, msg cid.Cid) (*api.MsgLookup, error) {
+	// the message may still execute when the wait fails, it stays submitted
+	lookup, err := lapi.StateWaitMsg(ctx, msg, build.MessageConfidence, 900, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if lookup.Receipt.ExitCode.IsError() {
+		err = fmt.Errorf("message %s failed with exit code %s", msg, lookup.Receipt.ExitCode)
+	}
+	j.evt.SetExecuted(uint64(lookup.Height), uint64(lookup.Receipt.GasUsed), err)
+	j.record()
+	return lookup, err
+}
+
+func (j *txJournal) record() {
+	// the journal serializes entries asynchronously, so it must be handed a
+	// copy that later stages do not modify
+	evt := reflect.New(reflect.TypeOf(j.evt).Elem())
+	evt.Elem().Set(reflect.ValueOf(j.evt).Elem())
+
+	entry := evt.Interface()
+	journal.RecordEvent(j.evtType, func() interface{} { return entry })
+}
+
+// revertDetails fetches the receipt of a transaction that did not succeed,
+// and replays it on the state it executed on to find out why it reverted. The
+// reason is nil if the transaction did not land, or could not be replayed.
+func revertDetails(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	eapi, err := PoolsSDK.Extern().ConnectEthClient()
+	if err != nil {
+		return nil, nil
+	}
+	defer eapi.Close()
+
+	receipt, err := eapi.TransactionReceipt(ctx, tx.Hash())
+	if err != nil || receipt == nil {
+		return nil, nil
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful || receipt.BlockNumber == nil {
+		return receipt, nil
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return receipt, nil
+	}
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	if _, err := eapi.CallContract(ctx, msg, parent); err != nil {
+		return receipt, decodeRevert(err)
+	}
+	return receipt, nil
+}
+
+// errorString is the message of err, or empty if it is nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
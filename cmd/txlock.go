@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/glifio/glif/v2/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	txLockMu sync.Mutex
+	txLock   *util.FileLock
+)
+
+// lockTxs takes the lock that serializes sending transactions across the glif
+// processes of the config directory, from reading the nonce of the sender
+// until the transaction is submitted, so that a manual command and autopilot
+// never send two transactions with the same nonce. The lock is held once per
+// process, and released by unlockTxs once the transaction is submitted, or
+// when the command exits.
+func lockTxs() error {
+	txLockMu.Lock()
+	defer txLockMu.Unlock()
+
+	if txLock != nil {
+		return nil
+	}
+	l, err := util.LockFile(filepath.Join(cfgDir, "tx.lock"))
+	if err != nil {
+		return fmt.Errorf("failed to lock transactions: %w", err)
+	}
+	txLock = l
+	return nil
+}
+
+// unlockTxs releases the lock taken by lockTxs, if held
+func unlockTxs() {
+	txLockMu.Lock()
+	defer txLockMu.Unlock()
+
+	if txLock != nil {
+		txLock.Unlock()
+		txLock = nil
+	}
+}
+
+func init() {
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		unlockTxs()
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
 	"os"
 	"regexp"
@@ -30,7 +30,6 @@ import (
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/glifio/glif/v2/util"
 	denoms "github.com/glifio/go-pools/util"
-	walletutils "github.com/glifio/go-wallet-utils"
 	"github.com/spf13/cobra"
 )
 
@@ -38,21 +37,25 @@ var ExitCode int
 
 func Exit(code int) {
 	ExitCode = code
+	unlockTxs()
 	runtime.Goexit()
 }
 
 func logExit(code int, msg string) {
-	log.Println(msg)
+	slog.Error(msg)
 	Exit(code)
 }
 
 func logFatal(arg interface{}) {
-	log.Println(arg)
+	if err, ok := arg.(error); ok {
+		arg = decodeRevert(err)
+	}
+	slog.Error(fmt.Sprint(arg))
 	Exit(1)
 }
 
 func logFatalf(format string, args ...interface{}) {
-	log.Printf(format, args...)
+	slog.Error(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
 	Exit(1)
 }
 
@@ -214,9 +217,6 @@ func commonOwnerOrOperatorSetup(ctx context.Context, from string) (agentAddr com
 
 	as := util.AccountsStore()
 	ks := util.KeyStore()
-	backends := []accounts.Backend{}
-	backends = append(backends, ks)
-	manager := accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: false}, backends...)
 
 	opEvm, opFevm, err := as.GetAddrs(string(util.OperatorKey))
 	if err != nil {
@@ -247,7 +247,7 @@ func commonOwnerOrOperatorSetup(ctx context.Context, from string) (agentAddr com
 		if funded {
 			fromAddress = opEvm
 		} else {
-			log.Println("operator not funded, falling back to owner address")
+			slog.Warn("operator not funded, falling back to owner address", "operator", opEvm)
 			fromAddress = owEvm
 		}
 		if err != nil {
@@ -268,30 +268,49 @@ func commonOwnerOrOperatorSetup(ctx context.Context, from string) (agentAddr com
 	}
 
 	account = accounts.Account{Address: fromAddress}
-	wallet, err := manager.Find(account)
-	if err != nil {
-		return common.Address{}, nil, accounts.Account{}, nil, err
-	}
 
-	var passphrase string
-	var envSet bool
-	var message string
-	if fromAddress == owEvm {
-		passphrase, envSet = os.LookupEnv("GLIF_OWNER_PASSPHRASE")
-		message = "Owner key passphrase"
-	} else if fromAddress == opEvm {
-		passphrase, envSet = os.LookupEnv("GLIF_OPERATOR_PASSPHRASE")
-		message = "Operator key passphrase"
-	}
-	if !envSet {
-		err = ks.Unlock(account, "")
+	// the transaction is signed offline with glif tx sign, or only simulated
+	// by a dry run, the credential of the ADO still needs the requester key
+	if unsignedOutFlag != "" || dryRunFlag {
+		requesterKey, err = getRequesterKey(as, ks)
 		if err != nil {
-			prompt := &survey.Password{Message: message}
-			survey.AskOne(prompt, &passphrase)
-			if passphrase == "" {
-				return common.Address{}, nil, accounts.Account{}, nil, fmt.Errorf("Aborted")
+			return common.Address{}, nil, accounts.Account{}, nil, err
+		}
+		auth = unsignedTransactor(fromAddress)
+		if err := applyFeeCaps(ctx, auth); err != nil {
+			return common.Address{}, nil, accounts.Account{}, nil, err
+		}
+		if err := lockTxs(); err != nil {
+			return common.Address{}, nil, accounts.Account{}, nil, err
+		}
+		return agentAddr, auth, account, requesterKey, nil
+	}
+
+	s, err := accountSigner(fromAddress, func() (string, error) {
+		var passphrase string
+		var envSet bool
+		var message string
+		if fromAddress == owEvm {
+			passphrase, envSet = os.LookupEnv("GLIF_OWNER_PASSPHRASE")
+			message = "Owner key passphrase"
+		} else if fromAddress == opEvm {
+			passphrase, envSet = os.LookupEnv("GLIF_OPERATOR_PASSPHRASE")
+			message = "Operator key passphrase"
+		}
+		if !envSet {
+			err := ks.Unlock(account, "")
+			if err != nil {
+				prompt := &survey.Password{Message: message}
+				survey.AskOne(prompt, &passphrase)
+				if passphrase == "" {
+					return "", fmt.Errorf("Aborted")
+				}
 			}
 		}
+		return passphrase, nil
+	})
+	if err != nil {
+		return common.Address{}, nil, accounts.Account{}, nil, err
 	}
 
 	requesterKey, err = getRequesterKey(as, ks)
@@ -299,9 +318,12 @@ func commonOwnerOrOperatorSetup(ctx context.Context, from string) (agentAddr com
 		return common.Address{}, nil, accounts.Account{}, nil, err
 	}
 
-	auth, err = walletutils.NewEthWalletTransactor(wallet, &account, passphrase, big.NewInt(chainID))
-	if err != nil {
-		logFatal(err)
+	auth = signerTransactor(fromAddress, s)
+	if err := applyFeeCaps(ctx, auth); err != nil {
+		return common.Address{}, nil, accounts.Account{}, nil, err
+	}
+	if err := lockTxs(); err != nil {
+		return common.Address{}, nil, accounts.Account{}, nil, err
 	}
 
 	return agentAddr, auth, account, requesterKey, nil
@@ -315,9 +337,6 @@ func commonGenericAccountSetup(ctx context.Context, from string) (auth *bind.Tra
 
 	as := util.AccountsStore()
 	ks := util.KeyStore()
-	backends := []accounts.Backend{}
-	backends = append(backends, ks)
-	manager := accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: false}, backends...)
 
 	var fromAddress common.Address
 	if strings.HasPrefix(from, "0x") {
@@ -334,36 +353,47 @@ func commonGenericAccountSetup(ctx context.Context, from string) (auth *bind.Tra
 	}
 
 	account = accounts.Account{Address: fromAddress}
-	wallet, err := manager.Find(account)
+	if dryRunFlag {
+		auth = unsignedTransactor(fromAddress)
+		if err := applyFeeCaps(ctx, auth); err != nil {
+			return nil, accounts.Account{}, err
+		}
+		if err := lockTxs(); err != nil {
+			return nil, accounts.Account{}, err
+		}
+		return auth, account, nil
+	}
+
+	s, err := accountSigner(fromAddress, func() (string, error) {
+		passphrase, envSet := os.LookupEnv("GLIF_PASSPHRASE")
+		if !envSet {
+			err := ks.Unlock(account, "")
+			if err != nil {
+				prompt := &survey.Password{Message: "Passphrase for account"}
+				survey.AskOne(prompt, &passphrase)
+				if passphrase == "" {
+					return "", fmt.Errorf("Aborted")
+				}
+			}
+		}
+		return passphrase, nil
+	})
 	if err != nil {
 		return nil, accounts.Account{}, err
 	}
 
-	var passphrase string
-	var envSet bool
-	var message string
-	passphrase, envSet = os.LookupEnv("GLIF_PASSPHRASE")
-	message = "Passphrase for account"
-	if !envSet {
-		err = ks.Unlock(account, "")
-		if err != nil {
-			prompt := &survey.Password{Message: message}
-			survey.AskOne(prompt, &passphrase)
-			if passphrase == "" {
-				return nil, accounts.Account{}, fmt.Errorf("Aborted")
-			}
-		}
+	auth = signerTransactor(fromAddress, s)
+	if err := applyFeeCaps(ctx, auth); err != nil {
+		return nil, accounts.Account{}, err
 	}
-
-	auth, err = walletutils.NewEthWalletTransactor(wallet, &account, passphrase, big.NewInt(chainID))
-	if err != nil {
-		logFatal(err)
+	if err := lockTxs(); err != nil {
+		return nil, accounts.Account{}, err
 	}
 
 	return auth, account, nil
 }
 
-func getRequesterKey(as *util.AccountsStorage, ks *keystore.KeyStore) (*ecdsa.PrivateKey, error) {
+func getRequesterKey(as *util.AccountsStorage, ks *util.LockedKeyStore) (*ecdsa.PrivateKey, error) {
 	requesterAddr, _, err := as.GetAddrs(string(util.RequestKey))
 	if err != nil {
 		return nil, err
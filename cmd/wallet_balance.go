@@ -9,25 +9,41 @@ import (
 
 	"github.com/filecoin-project/lotus/api"
 	"github.com/glifio/glif/v2/util"
-	denoms "github.com/glifio/go-pools/util"
 	"github.com/spf13/cobra"
 )
 
-func printBalance(ctx context.Context, lapi *api.FullNodeStruct, as *util.AccountsStorage, name string) {
+type walletBalance struct {
+	Name    string     `json:"name"`
+	Address string     `json:"address,omitempty"`
+	Balance *FILAmount `json:"balance,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+func getBalance(ctx context.Context, lapi *api.FullNodeStruct, as *util.AccountsStorage, name string) walletBalance {
 	_, addr, err := as.GetAddrs(name)
 	if err != nil {
-		fmt.Printf("%s balance: Error %v\n", name, err)
-		return
+		return walletBalance{Name: name, Error: err.Error()}
 	}
 
 	bal, err := lapi.WalletBalance(ctx, addr)
 	if err != nil {
-		fmt.Printf("%s balance: Error %v\n", name, err)
+		return walletBalance{Name: name, Address: addr.String(), Error: err.Error()}
+	}
+	amt := NewFILAmount(bal.Int)
+	return walletBalance{Name: name, Address: addr.String(), Balance: &amt}
+}
+
+func printBalance(b walletBalance) {
+	if b.Error != "" {
+		fmt.Printf("%s balance: Error %v\n", b.Name, b.Error)
 		return
 	}
-	balance := denoms.ToFIL(bal.Int)
-	bf64, _ := balance.Float64()
-	fmt.Printf("%s balance: %.02f FIL\n", name, bf64)
+	fmt.Printf("%s balance: %.02f FIL\n", b.Name, filAmountToFloat(*b.Balance))
+}
+
+type walletBalanceOutput struct {
+	AgentAccounts []walletBalance `json:"agent_accounts"`
+	Accounts      []walletBalance `json:"accounts"`
 }
 
 // newCmd represents the new command
@@ -44,6 +60,11 @@ var balCmd = &cobra.Command{
 		}
 		defer closer()
 
+		out := &walletBalanceOutput{
+			AgentAccounts: []walletBalance{},
+			Accounts:      []walletBalance{},
+		}
+
 		owner, _ := as.Get(string(util.OwnerKey))
 		operator, _ := as.Get(string(util.OperatorKey))
 		if owner != "" || operator != "" {
@@ -51,31 +72,38 @@ var balCmd = &cobra.Command{
 				string(util.OwnerKey),
 				string(util.OperatorKey),
 			}
-			fmt.Printf("Agent accounts:\n\n")
 			for _, name := range agentNames {
-				printBalance(ctx, lapi, as, name)
+				out.AgentAccounts = append(out.AgentAccounts, getBalance(ctx, lapi, as, name))
 			}
-			fmt.Println()
 		}
 
 		allNames := as.AccountNames()
-		names := make([]string, 0)
 		for _, name := range allNames {
 			if name == string(util.OwnerKey) ||
 				name == string(util.OperatorKey) ||
 				name == string(util.RequestKey) {
 				continue
 			}
-			names = append(names, name)
+			out.Accounts = append(out.Accounts, getBalance(ctx, lapi, as, name))
 		}
 
-		if len(names) > 0 {
-			fmt.Printf("Regular accounts:\n\n")
-			for _, name := range names {
-				printBalance(ctx, lapi, as, name)
+		printOutput(out, func() {
+			if len(out.AgentAccounts) > 0 {
+				fmt.Printf("Agent accounts:\n\n")
+				for _, b := range out.AgentAccounts {
+					printBalance(b)
+				}
+				fmt.Println()
 			}
-			fmt.Println()
-		}
+
+			if len(out.Accounts) > 0 {
+				fmt.Printf("Regular accounts:\n\n")
+				for _, b := range out.Accounts {
+					printBalance(b)
+				}
+				fmt.Println()
+			}
+		})
 	},
 }
 
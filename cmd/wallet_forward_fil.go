@@ -64,19 +64,17 @@ var forwardFIL = &cobra.Command{
 		s.Start()
 		defer s.Stop()
 
-		forwardFILevt := journal.RegisterEventType("wallet", "forwardFIL")
 		evt := &events.WalletFILForward{
 			From:   args[0],
 			To:     args[1],
 			Amount: args[2],
 		}
+		txj := newTxJournal("wallet", "forwardFIL", evt)
 		defer journal.Close()
-		defer journal.RecordEvent(forwardFILevt, func() interface{} { return evt })
 
 		nonce, err := PoolsSDK.Query().ChainGetNonce(cmd.Context(), senderAccount.Address)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
 
 		chainID := PoolsSDK.Query().ChainID()
@@ -91,15 +89,13 @@ var forwardFIL = &cobra.Command{
 			filForwardAddr = common.HexToAddress(os.Getenv("GLIF_FIL_FORWARDER"))
 		default:
 			err = errors.New("unsupported chain id for forward-fil command")
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
 
 		// get the FilForwarder contract address
 		filf, err := abigen.NewFilForwarderTransactor(filForwardAddr, ethClient)
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
 
 		auth.Nonce = nonce
@@ -107,10 +103,9 @@ var forwardFIL = &cobra.Command{
 
 		tx, err := filf.Forward(auth, to.Bytes())
 		if err != nil {
-			evt.Error = err.Error()
-			logFatal(err)
+			logFatal(txj.failed(err))
 		}
-		evt.Tx = tx.Hash().String()
+		txj.submitted(tx)
 		s.Stop()
 
 		fmt.Printf("Forward FIL transaction sent: %s\n", tx.Hash().Hex())
@@ -118,9 +113,8 @@ var forwardFIL = &cobra.Command{
 
 		s.Start()
 
-		_, err = PoolsSDK.Query().StateWaitReceipt(cmd.Context(), tx.Hash())
+		_, err = txj.wait(cmd.Context(), tx)
 		if err != nil {
-			evt.Error = err.Error()
 			logFatal(err)
 		}
 
@@ -18,6 +18,11 @@ var listCmd = &cobra.Command{
 		as := util.AccountsStore()
 		ks := util.KeyStore()
 
+		out := &walletListOutput{
+			AgentAccounts: []walletAccount{},
+			Accounts:      []walletAccount{},
+		}
+
 		owner, _ := as.Get(string(util.OwnerKey))
 		operator, _ := as.Get(string(util.OperatorKey))
 		request, _ := as.Get(string(util.RequestKey))
@@ -27,11 +32,11 @@ var listCmd = &cobra.Command{
 				string(util.OperatorKey),
 				string(util.RequestKey),
 			}
-			fmt.Printf("Agent accounts:\n\n")
 			for _, name := range agentNames {
-				printAddresses(as, name)
+				if acc, ok := walletAccountAddrs(as, name); ok {
+					out.AgentAccounts = append(out.AgentAccounts, acc)
+				}
 			}
-			fmt.Println()
 		}
 
 		allNames := as.AccountNames()
@@ -45,35 +50,67 @@ var listCmd = &cobra.Command{
 			names = append(names, name)
 		}
 
-		if len(names) > 0 {
-			fmt.Printf("Regular accounts:\n\n")
-			for _, name := range names {
-				evm, _, err := as.GetAddrs(name)
-				if err != nil {
-					logFatal(err)
+		includeReadOnly := cmd.Flags().Changed("include-read-only")
+		for _, name := range names {
+			evm, _, err := as.GetAddrs(name)
+			if err != nil {
+				logFatal(err)
+			}
+
+			if ks.HasAddress(evm) || includeReadOnly {
+				if acc, ok := walletAccountAddrs(as, name); ok {
+					out.Accounts = append(out.Accounts, acc)
 				}
+			}
+		}
 
-				includeReadOnly := cmd.Flags().Changed("include-read-only")
+		printOutput(out, func() {
+			if len(out.AgentAccounts) > 0 {
+				fmt.Printf("Agent accounts:\n\n")
+				for _, acc := range out.AgentAccounts {
+					printAddresses(acc)
+				}
+				fmt.Println()
+			}
 
-				if ks.HasAddress(evm) || includeReadOnly {
-					printAddresses(as, name)
+			if len(names) > 0 {
+				fmt.Printf("Regular accounts:\n\n")
+				for _, acc := range out.Accounts {
+					printAddresses(acc)
 				}
+				fmt.Println()
 			}
-			fmt.Println()
-		}
+		})
 	},
 }
 
-func printAddresses(as *util.AccountsStorage, name string) {
+type walletListOutput struct {
+	AgentAccounts []walletAccount `json:"agent_accounts"`
+	Accounts      []walletAccount `json:"accounts"`
+}
+
+type walletAccount struct {
+	Name string `json:"name"`
+	EVM  string `json:"evm"`
+	FIL  string `json:"fil"`
+}
+
+// walletAccountAddrs returns the addresses of the named account, and false if
+// the account does not exist
+func walletAccountAddrs(as *util.AccountsStorage, name string) (walletAccount, bool) {
 	evm, fevm, err := as.GetAddrs(name)
 	if err != nil {
 		var e *util.ErrKeyNotFound
 		if errors.As(err, &e) {
-			return
+			return walletAccount{}, false
 		}
 		logFatal(err)
 	}
-	fmt.Printf("%s: %s (EVM), %s (FIL)\n", name, evm, fevm)
+	return walletAccount{Name: name, EVM: evm.String(), FIL: fevm.String()}, true
+}
+
+func printAddresses(acc walletAccount) {
+	fmt.Printf("%s: %s (EVM), %s (FIL)\n", acc.Name, acc.EVM, acc.FIL)
 }
 
 func init() {
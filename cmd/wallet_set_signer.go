@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/glifio/glif/v2/signer"
+	"github.com/glifio/glif/v2/util"
+	"github.com/spf13/cobra"
+)
+
+// setSignerCmd represents the set-signer command
+var setSignerCmd = &cobra.Command{
+	Use:   "set-signer <account> <signer>",
+	Short: "Select how the transactions of an account are signed",
+	Long: `Selects the signer of an account, recorded in signers.toml next to accounts.toml:
+
+  keystore                  the local keystore, the default
+  lotus                     the wallet of the lotus node, which holds the delegated (f4) key
+  clef:<url>                a Clef signer, or a service implementing account_signTransaction
+  eth:<url>                 a node or service implementing eth_signTransaction
+  exec:<program> [args...]  a program that reads the transaction to sign as JSON on its
+                            standard input, and writes its signature as JSON on its standard output
+
+For example, to keep the owner key in a separate signing service:
+
+  glif wallet set-signer owner clef:https://signer.internal:8550`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		as := util.AccountsStore()
+
+		name := args[0]
+		addr, _, err := as.GetAddrs(name)
+		if err != nil {
+			logFatal(err)
+		}
+
+		spec, err := signer.ParseSpec(args[1])
+		if err != nil {
+			logFatal(err)
+		}
+
+		value := spec.String()
+		if spec.Kind == signer.KindKeystore {
+			value = ""
+		}
+		if err := util.SignersStore().SetSigner(name, value); err != nil {
+			logFatal(err)
+		}
+
+		fmt.Printf("Account %s (%s) now signs with %s\n", name, addr, spec)
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(setSignerCmd)
+}
@@ -0,0 +1,238 @@
+package events
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/go-address"
+	"github.com/glifio/glif/v2/journal"
+	"github.com/glifio/go-pools/abigen"
+)
+
+// SourceChain is the Source of events reconstructed from FEVM logs
+const SourceChain = "chain"
+
+// ChainContracts are the protocol contracts whose logs record the on-chain
+// history of an agent. The agent contract itself does not emit logs, so
+// pulls, pushes, withdrawals and ownership changes cannot be reconstructed.
+type ChainContracts struct {
+	InfinityPool  common.Address
+	MinerRegistry common.Address
+	AgentFactory  common.Address
+	AgentPolice   common.Address
+}
+
+// ChainLogs selects and decodes the FEVM logs of an agent
+type ChainLogs struct {
+	contracts ChainContracts
+	agent     common.Address
+	agentID   *big.Int
+	poolID    string
+
+	infpool  *abigen.InfinityPoolFilterer
+	minerReg *abigen.MinerRegistryFilterer
+	factory  *abigen.AgentFactoryFilterer
+	police   *abigen.AgentPoliceFilterer
+
+	// names of the decoded events by log topic
+	names map[common.Hash]string
+}
+
+func NewChainLogs(contracts ChainContracts, agent common.Address, agentID *big.Int, poolID string) (*ChainLogs, error) {
+	c := &ChainLogs{
+		contracts: contracts,
+		agent:     agent,
+		agentID:   agentID,
+		poolID:    poolID,
+		names:     map[common.Hash]string{},
+	}
+
+	var err error
+	// the filterers are only used to parse logs, which does not need a backend
+	if c.infpool, err = abigen.NewInfinityPoolFilterer(contracts.InfinityPool, nil); err != nil {
+		return nil, err
+	}
+	if c.minerReg, err = abigen.NewMinerRegistryFilterer(contracts.MinerRegistry, nil); err != nil {
+		return nil, err
+	}
+	if c.factory, err = abigen.NewAgentFactoryFilterer(contracts.AgentFactory, nil); err != nil {
+		return nil, err
+	}
+	if c.police, err = abigen.NewAgentPoliceFilterer(contracts.AgentPolice, nil); err != nil {
+		return nil, err
+	}
+
+	for _, e := range []struct {
+		meta  *bind.MetaData
+		names []string
+	}{
+		{abigen.InfinityPoolMetaData, []string{"Borrow", "Pay", "WriteOff"}},
+		{abigen.MinerRegistryMetaData, []string{"AddMiner", "RemoveMiner"}},
+		{abigen.AgentFactoryMetaData, []string{"CreateAgent"}},
+		{abigen.AgentPoliceMetaData, []string{"FaultySectors"}},
+	} {
+		parsed, err := e.meta.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.addEvents(parsed, e.names); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *ChainLogs) addEvents(parsed *abi.ABI, names []string) error {
+	for _, name := range names {
+		evt, ok := parsed.Events[name]
+		if !ok {
+			return fmt.Errorf("event %s not found in contract ABI", name)
+		}
+		c.names[evt.ID] = name
+	}
+	return nil
+}
+
+// FilterQuery selects the logs of the agent between the from and to blocks,
+// both inclusive. Every decoded event has the agent, by ID or by address, as
+// its first indexed argument.
+func (c *ChainLogs) FilterQuery(from, to uint64) ethereum.FilterQuery {
+	ids := make([]common.Hash, 0, len(c.names))
+	for id := range c.names {
+		ids = append(ids, id)
+	}
+
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{
+			c.contracts.InfinityPool,
+			c.contracts.MinerRegistry,
+			c.contracts.AgentFactory,
+			c.contracts.AgentPolice,
+		},
+		Topics: [][]common.Hash{
+			ids,
+			{common.BigToHash(c.agentID), common.BytesToHash(c.agent.Bytes())},
+		},
+	}
+}
+
+// Decode returns the event recorded by a log, without a timestamp. It returns
+// false for logs that were removed by a reorg, or that are not events of the
+// agent.
+func (c *ChainLogs) Decode(log types.Log) (journal.Event, bool, error) {
+	if log.Removed || len(log.Topics) == 0 {
+		return journal.Event{}, false, nil
+	}
+	name, ok := c.names[log.Topics[0]]
+	if !ok {
+		return journal.Event{}, false, nil
+	}
+
+	agent := c.agent.String()
+	var (
+		event string
+		data  chainEvent
+		match bool
+	)
+
+	switch {
+	case log.Address == c.contracts.InfinityPool && name == "Borrow":
+		l, err := c.infpool.ParseBorrow(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.Agent.Cmp(c.agentID) == 0
+		event, data = "borrow", &AgentBorrow{AgentID: agent, PoolID: c.poolID, Amount: l.Amount.String()}
+	case log.Address == c.contracts.InfinityPool && name == "Pay":
+		l, err := c.infpool.ParsePay(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.Agent.Cmp(c.agentID) == 0
+		event, data = "pay", &AgentPay{
+			AgentID:       agent,
+			PoolID:        c.poolID,
+			EpochsPaid:    l.EpochsPaid.String(),
+			PrincipalPaid: l.PrincipalPaid.String(),
+			Refund:        l.Refund.String(),
+		}
+	case log.Address == c.contracts.InfinityPool && name == "WriteOff":
+		l, err := c.infpool.ParseWriteOff(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.AgentID.Cmp(c.agentID) == 0
+		event, data = "writeoff", &AgentWriteOff{
+			AgentID:        agent,
+			PoolID:         c.poolID,
+			RecoveredFunds: l.RecoveredFunds.String(),
+			LostFunds:      l.LostFunds.String(),
+			InterestPaid:   l.InterestPaid.String(),
+		}
+	case log.Address == c.contracts.MinerRegistry && name == "AddMiner":
+		l, err := c.minerReg.ParseAddMiner(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.Agent == c.agent
+		event, data = "addminer", &AgentAddMiner{AgentID: agent, MinerID: minerID(l.Miner)}
+	case log.Address == c.contracts.MinerRegistry && name == "RemoveMiner":
+		l, err := c.minerReg.ParseRemoveMiner(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.Agent == c.agent
+		event, data = "removeminer", &AgentMinerRemove{AgentID: agent, MinerID: minerID(l.Miner)}
+	case log.Address == c.contracts.AgentFactory && name == "CreateAgent":
+		l, err := c.factory.ParseCreateAgent(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.Agent == c.agent
+		event, data = "create", &AgentCreate{AgentID: agent, ID: l.AgentID.String()}
+	case log.Address == c.contracts.AgentPolice && name == "FaultySectors":
+		l, err := c.police.ParseFaultySectors(log)
+		if err != nil {
+			return journal.Event{}, false, err
+		}
+		match = l.AgentID == c.agent
+		event, data = "faultysectors", &AgentFaultySectors{AgentID: agent, FaultEpoch: l.FaultEpoch.String()}
+	}
+	if !match {
+		return journal.Event{}, false, nil
+	}
+
+	data.SetSubmitted(log.TxHash.String())
+	data.SetExecuted(log.BlockNumber, 0, nil)
+	data.setSource(SourceChain)
+
+	return journal.Event{
+		EventType: journal.EventType{System: "agent", Event: event},
+		Data:      data,
+	}, true, nil
+}
+
+// chainEvent is implemented by the events that embed evtCommon
+type chainEvent interface {
+	TxEvent
+	setSource(source string)
+}
+
+func (c *evtCommon) setSource(source string) {
+	c.Source = source
+}
+
+func minerID(id uint64) string {
+	a, err := address.NewIDAddress(id)
+	if err != nil {
+		return fmt.Sprintf("%d", id)
+	}
+	return a.String()
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/go-pools/abigen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainLogsDecode(t *testing.T) {
+	contracts := ChainContracts{
+		InfinityPool:  common.HexToAddress("0x01"),
+		MinerRegistry: common.HexToAddress("0x02"),
+		AgentFactory:  common.HexToAddress("0x03"),
+		AgentPolice:   common.HexToAddress("0x04"),
+	}
+	agent := common.HexToAddress("0xf1a5c2a0e2b2d1e6f1b1a1c1d1e1f1a1b1c1d1e1")
+	logs, err := NewChainLogs(contracts, agent, big.NewInt(7), "0")
+	require.NoError(t, err)
+
+	parsed, err := abigen.InfinityPoolMetaData.GetAbi()
+	require.NoError(t, err)
+	borrow := parsed.Events["Borrow"]
+	data, err := borrow.Inputs.NonIndexed().Pack(big.NewInt(1e18))
+	require.NoError(t, err)
+
+	log := types.Log{
+		Address:     contracts.InfinityPool,
+		Topics:      []common.Hash{borrow.ID, common.BigToHash(big.NewInt(7))},
+		Data:        data,
+		BlockNumber: 100,
+		TxHash:      common.HexToHash("0xabc"),
+	}
+
+	e, ok, err := logs.Decode(log)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "agent:borrow", e.EventType.String())
+
+	evt := e.Data.(*AgentBorrow)
+	assert.Equal(t, "1000000000000000000", evt.Amount)
+	assert.Equal(t, uint64(100), evt.BlockNumber)
+	assert.Equal(t, StatusConfirmed, evt.Status)
+	assert.Equal(t, SourceChain, evt.Source)
+
+	// borrows of other agents are not decoded
+	log.Topics[1] = common.BigToHash(big.NewInt(8))
+	_, ok, err = logs.Decode(log)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Stages of the transaction lifecycle of an on-chain action
+const (
+	StatusSubmitted = "submitted"
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+)
+
+// TxEvent is implemented by the events of on-chain actions, which record the
+// lifecycle of their transaction
+type TxEvent interface {
+	SetCorrelationID(id string)
+	SetSubmitted(tx string)
+	SetConfirmed(receipt *types.Receipt)
+	SetFailed(err error, receipt *types.Receipt, revertReason string)
+	SetExecuted(epoch uint64, gasUsed uint64, err error)
+}
+
+// NewCorrelationID returns a random ID that ties the entries of an action
+// together
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (c *evtCommon) SetCorrelationID(id string) {
+	c.CorrelationID = id
+}
+
+// SetSubmitted records the hash of the transaction, or the CID of the Filecoin
+// message, that was sent for the action
+func (c *evtCommon) SetSubmitted(tx string) {
+	c.Status = StatusSubmitted
+	c.Tx = tx
+}
+
+func (c *evtCommon) SetConfirmed(receipt *types.Receipt) {
+	c.Status = StatusConfirmed
+	c.setReceipt(receipt)
+}
+
+// SetFailed records an action that failed before its transaction was sent, or
+// whose transaction reverted, in which case the receipt may be set
+func (c *evtCommon) SetFailed(err error, receipt *types.Receipt, revertReason string) {
+	c.Status = StatusFailed
+	if err != nil {
+		c.Error = err.Error()
+	}
+	c.RevertReason = revertReason
+	c.setReceipt(receipt)
+}
+
+// SetExecuted records the outcome of a Filecoin message executed at epoch,
+// which failed if err is set
+func (c *evtCommon) SetExecuted(epoch uint64, gasUsed uint64, err error) {
+	c.Status = StatusConfirmed
+	if err != nil {
+		c.Status = StatusFailed
+		c.Error = err.Error()
+	}
+	c.BlockNumber = epoch
+	c.GasUsed = gasUsed
+}
+
+func (c *evtCommon) setReceipt(receipt *types.Receipt) {
+	if receipt == nil {
+		return
+	}
+	c.Tx = receipt.TxHash.String()
+	c.GasUsed = receipt.GasUsed
+	if receipt.BlockNumber != nil {
+		c.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	if receipt.EffectiveGasPrice != nil {
+		c.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		fee := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		c.TotalFee = fee.String()
+	}
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/glifio/go-pools/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle(t *testing.T) {
+	evt := &InfPoolDepositFIL{Receiver: "0xf1a5c2a0e2b2d1e6f1b1a1c1d1e1f1a1b1c1d1e1", Amount: "1000000000000000000"}
+	var txEvt TxEvent = evt
+
+	txEvt.SetCorrelationID("c0ffee")
+	txEvt.SetSubmitted(common.HexToHash("0x01").String())
+	assert.Equal(t, StatusSubmitted, evt.Status)
+
+	txEvt.SetConfirmed(&types.Receipt{
+		TxHash:            common.HexToHash("0x01"),
+		GasUsed:           1000,
+		EffectiveGasPrice: big.NewInt(100),
+		BlockNumber:       big.NewInt(42),
+	})
+	assert.Equal(t, StatusConfirmed, evt.Status)
+	assert.Equal(t, "c0ffee", evt.CorrelationID)
+	assert.Equal(t, uint64(42), evt.BlockNumber)
+	assert.Equal(t, "100000", evt.TotalFee)
+
+	failed := &AgentRefreshRoutes{}
+	failed.SetFailed(errors.New("execution reverted"), nil, "InsufficientLiquidity()")
+	assert.Equal(t,
+		"status: failed  error: execution reverted  revert reason: InsufficientLiquidity()",
+		Format(failed, constants.MainnetChainID),
+	)
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+var (
+	registryLk sync.RWMutex
+	registry   = map[string]func() interface{}{}
+)
+
+// Register maps the system:event journal type to the event struct returned by
+// newEvent, so that recorded payloads can be decoded back into it
+func Register(system, event string, newEvent func() interface{}) {
+	registryLk.Lock()
+	defer registryLk.Unlock()
+	registry[system+":"+event] = newEvent
+}
+
+// New returns a new zero value of the event struct registered for the
+// system:event journal type
+func New(system, event string) (interface{}, bool) {
+	registryLk.RLock()
+	newEvent, ok := registry[system+":"+event]
+	registryLk.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return newEvent(), true
+}
+
+// Decode replaces the payload of a journal event read back from disk with the
+// event struct registered for its type. Events of unknown types, and payloads
+// that do not fit the registered struct, are returned unchanged.
+func Decode(e journal.Event) journal.Event {
+	evt, ok := New(e.System, e.Event)
+	if !ok || e.Data == nil {
+		return e
+	}
+
+	b, err := json.Marshal(e.Data)
+	if err != nil {
+		return e
+	}
+	if err := json.Unmarshal(b, evt); err != nil {
+		return e
+	}
+
+	e.Data = evt
+	return e
+}
+
+func init() {
+	Register("agent", "borrow", func() interface{} { return &AgentBorrow{} })
+	Register("agent", "addminer", func() interface{} { return &AgentAddMiner{} })
+	Register("agent", "removeminer", func() interface{} { return &AgentMinerRemove{} })
+	Register("agent", "reclaim", func() interface{} { return &AgentMinerReclaim{} })
+	Register("agent", "pull", func() interface{} { return &AgentMinerPull{} })
+	Register("agent", "push", func() interface{} { return &AgentMinerPush{} })
+	Register("agent", "pay", func() interface{} { return &AgentPay{} })
+	Register("agent", "withdraw", func() interface{} { return &AgentWithdraw{} })
+	Register("agent", "exit", func() interface{} { return &AgentExit{} })
+	Register("agent", "admin", func() interface{} { return &AgentAdmin{} })
+	Register("miner", "changeowner", func() interface{} { return &AgentMinerChangeOwner{} })
+	Register("miner", "changeworker", func() interface{} { return &AgentMinerChangeWorker{} })
+	Register("miner", "confirmworker", func() interface{} { return &AgentMinerConfirmWorker{} })
+	Register("agent", "refreshroutes", func() interface{} { return &AgentRefreshRoutes{} })
+	Register("agent", "create", func() interface{} { return &AgentCreate{} })
+	Register("agent", "import", func() interface{} { return &AgentImport{} })
+	Register("agent", "writeoff", func() interface{} { return &AgentWriteOff{} })
+	Register("agent", "faultysectors", func() interface{} { return &AgentFaultySectors{} })
+	Register("infpool", "depositfil", func() interface{} { return &InfPoolDepositFIL{} })
+	Register("infpool", "redeem", func() interface{} { return &InfPoolRedeem{} })
+	Register("infpool", "withdraw", func() interface{} { return &InfPoolWithdraw{} })
+	Register("ifil", "transfer", func() interface{} { return &IFILTransfer{} })
+	Register("ifil", "approve", func() interface{} { return &IFILApprove{} })
+	Register("wallet", "forwardFIL", func() interface{} { return &WalletFILForward{} })
+	Register("tx", "replace", func() interface{} { return &TxReplace{} })
+	Register("tx", "cancel", func() interface{} { return &TxReplace{} })
+}
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/glifio/glif/v2/journal"
+	"github.com/glifio/go-pools/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip mimics reading an event back from the NDJSON journal
+func roundTrip(t *testing.T, system, event string, data interface{}) journal.Event {
+	b, err := json.Marshal(journal.Event{
+		EventType: journal.EventType{System: system, Event: event},
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	require.NoError(t, err)
+
+	var e journal.Event
+	require.NoError(t, json.Unmarshal(b, &e))
+	return e
+}
+
+func TestDecode(t *testing.T) {
+	pay := &AgentPay{
+		AgentID: "0xf1a5c2a0e2b2d1e6f1b1a1c1d1e1f1a1b1c1d1e1",
+		PoolID:  "0",
+		Amount:  "1500000000000000000",
+		PayType: "to-current",
+	}
+	pay.Tx = "0xabc"
+
+	e := Decode(roundTrip(t, "agent", "pay", pay))
+	decoded, ok := e.Data.(*AgentPay)
+	require.True(t, ok, "expected *AgentPay, got %T", e.Data)
+	assert.Equal(t, pay, decoded)
+
+	assert.Equal(t,
+		"agent: 0xf1a5...d1e1  pool: 0  type: to-current  amount: 1.500000000 FIL  tx: https://filfox.info/en/message/0xabc",
+		Format(decoded, constants.MainnetChainID))
+}
+
+func TestDecodeUnknownType(t *testing.T) {
+	e := Decode(roundTrip(t, "alert", "raised", map[string]interface{}{"b": 2, "a": "x", "tx": "0xabc"}))
+	_, ok := e.Data.(map[string]interface{})
+	require.True(t, ok)
+
+	assert.Equal(t, "a: x  b: 2  tx: 0xabc", Format(e.Data, constants.LocalnetChainID))
+}
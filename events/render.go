@@ -0,0 +1,293 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/glifio/glif/v2/util"
+	"github.com/glifio/go-pools/constants"
+	denoms "github.com/glifio/go-pools/util"
+)
+
+// Field is a labelled value of a rendered event
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Describer is implemented by events that know how to render their payload
+// for humans. The transaction and error are rendered by Format.
+type Describer interface {
+	Describe() []Field
+}
+
+// hasCommon is implemented by the events that embed evtCommon
+type hasCommon interface {
+	commonFields() evtCommon
+}
+
+func (c evtCommon) commonFields() evtCommon { return c }
+
+// Format renders an event payload on a single line. Registered events are
+// rendered with FIL amounts, truncated addresses and a link to the
+// transaction on the explorer of the chain; any other payload is rendered as
+// its key: value pairs, sorted by key.
+func Format(data interface{}, chainID int64) string {
+	var fields []Field
+	var c evtCommon
+
+	switch d := data.(type) {
+	case Describer:
+		fields = d.Describe()
+		if cd, ok := data.(hasCommon); ok {
+			c = cd.commonFields()
+		}
+	default:
+		fields = mapFields(data)
+		for i := range fields {
+			if fields[i].Name == "tx" {
+				fields[i].Value = TxLink(fields[i].Value, chainID)
+			}
+		}
+	}
+
+	if c.Status != "" {
+		fields = append([]Field{{"status", c.Status}}, fields...)
+	}
+	if c.Tx != "" {
+		fields = append(fields, Field{"tx", TxLink(c.Tx, chainID)})
+	}
+	if c.BlockNumber != 0 {
+		fields = append(fields, Field{"epoch", strconv.FormatUint(c.BlockNumber, 10)})
+	}
+	if c.TotalFee != "" {
+		fields = append(fields, Field{"fee", fil(c.TotalFee)})
+	}
+	if c.Error != "" {
+		fields = append(fields, Field{"error", c.Error})
+	}
+	if c.RevertReason != "" {
+		fields = append(fields, Field{"revert reason", c.RevertReason})
+	}
+	if c.Source != "" {
+		fields = append(fields, Field{"source", c.Source})
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f.Value == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", f.Name, f.Value))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// TxLink returns a link to the transaction on the explorer of the chain, or
+// the bare hash on chains without an explorer
+func TxLink(tx string, chainID int64) string {
+	switch chainID {
+	case constants.MainnetChainID:
+		return "https://filfox.info/en/message/" + tx
+	case constants.CalibnetChainID:
+		return "https://calibration.filfox.info/en/message/" + tx
+	default:
+		return tx
+	}
+}
+
+// mapFields renders an unknown payload as its sorted key: value pairs
+func mapFields(data interface{}) []Field {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return []Field{{"data", fmt.Sprintf("%v", data)}}
+		}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return []Field{{"data", string(b)}}
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		v := m[k]
+		if s, ok := v.(string); ok {
+			fields = append(fields, Field{k, s})
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			fields = append(fields, Field{k, fmt.Sprintf("%v", v)})
+			continue
+		}
+		fields = append(fields, Field{k, string(b)})
+	}
+	return fields
+}
+
+// fil renders an attoFIL amount in FIL, falling back to the raw value
+func fil(atto string) string {
+	amt, ok := new(big.Int).SetString(atto, 10)
+	if !ok {
+		return atto
+	}
+	return fmt.Sprintf("%0.09f FIL", denoms.ToFIL(amt))
+}
+
+func addr(a string) string {
+	return util.TruncateAddr(a)
+}
+
+func addrs(as []string) string {
+	truncated := make([]string, len(as))
+	for i, a := range as {
+		truncated[i] = addr(a)
+	}
+	return strings.Join(truncated, ",")
+}
+
+func (e *AgentBorrow) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"pool", e.PoolID}, {"amount", fil(e.Amount)}}
+}
+
+func (e *AgentAddMiner) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"miner", e.MinerID}}
+}
+
+func (e *AgentMinerChangeOwner) Describe() []Field {
+	return []Field{
+		{"agent", addr(e.AgentID)},
+		{"miner", e.MinerID},
+		{"old owner", addr(e.OldOwner)},
+		{"new owner", addr(e.NewOwner)},
+	}
+}
+
+func (e *AgentMinerChangeWorker) Describe() []Field {
+	return []Field{
+		{"agent", addr(e.AgentID)},
+		{"miner", e.MinerID},
+		{"new worker", addr(e.NewWorker)},
+		{"new control", addrs(e.NewControl)},
+	}
+}
+
+func (e *AgentMinerConfirmWorker) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"miner", e.MinerID}}
+}
+
+func (e *AgentMinerPull) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"miner", e.MinerID}, {"amount", fil(e.Amount)}}
+}
+
+func (e *AgentMinerPush) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"miner", e.MinerID}, {"amount", fil(e.Amount)}}
+}
+
+func (e *AgentMinerReclaim) Describe() []Field {
+	return []Field{{"miner", e.MinerID}, {"new owner", addr(e.NewOwner)}}
+}
+
+func (e *AgentMinerRemove) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"miner", e.MinerID}, {"new owner", addr(e.NewOwner)}}
+}
+
+func (e *AgentPay) Describe() []Field {
+	return []Field{
+		{"agent", addr(e.AgentID)},
+		{"pool", e.PoolID},
+		{"type", e.PayType},
+		{"amount", fil(e.Amount)},
+		{"epochs paid", e.EpochsPaid},
+		{"principal paid", fil(e.PrincipalPaid)},
+		{"refund", fil(e.Refund)},
+	}
+}
+
+func (e *AgentWithdraw) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"to", addr(e.To)}, {"amount", fil(e.Amount)}}
+}
+
+func (e *AgentExit) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"pool", e.PoolID}, {"amount", fil(e.Amount)}}
+}
+
+// Describe renders the amount as is, the forwarded amount is recorded in FIL
+func (e *WalletFILForward) Describe() []Field {
+	amount := e.Amount
+	if amount != "" {
+		amount += " FIL"
+	}
+	return []Field{{"from", addr(e.From)}, {"to", addr(e.To)}, {"amount", amount}}
+}
+
+func (e *AgentAdmin) Describe() []Field {
+	return []Field{{"action", e.Action}, {"agent", addr(e.AgentID)}, {"new admin", addr(e.NewAdminAddress)}}
+}
+
+func (e *InfPoolDepositFIL) Describe() []Field {
+	return []Field{{"receiver", addr(e.Receiver)}, {"amount", fil(e.Amount)}}
+}
+
+func (e *InfPoolRedeem) Describe() []Field {
+	return []Field{{"sender", addr(e.Sender)}, {"receiver", addr(e.Receiver)}, {"amount", fil(e.Amount)}}
+}
+
+func (e *InfPoolWithdraw) Describe() []Field {
+	return []Field{{"sender", addr(e.Sender)}, {"receiver", addr(e.Receiver)}, {"amount", fil(e.Amount)}}
+}
+
+func (e *IFILTransfer) Describe() []Field {
+	return []Field{{"to", addr(e.To)}, {"amount", fil(e.Amount)}}
+}
+
+func (e *IFILApprove) Describe() []Field {
+	return []Field{{"spender", addr(e.Spender)}, {"amount", fil(e.Amount)}}
+}
+
+func (e *AgentRefreshRoutes) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}}
+}
+
+func (e *AgentCreate) Describe() []Field {
+	return []Field{
+		{"agent", addr(e.AgentID)},
+		{"id", e.ID},
+		{"owner", addr(e.Owner)},
+		{"operator", addr(e.Operator)},
+		{"requester", addr(e.Requester)},
+	}
+}
+
+func (e *AgentImport) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"id", e.ID}}
+}
+
+func (e *AgentWriteOff) Describe() []Field {
+	return []Field{
+		{"agent", addr(e.AgentID)},
+		{"pool", e.PoolID},
+		{"recovered", fil(e.RecoveredFunds)},
+		{"lost", fil(e.LostFunds)},
+		{"interest paid", fil(e.InterestPaid)},
+	}
+}
+
+func (e *AgentFaultySectors) Describe() []Field {
+	return []Field{{"agent", addr(e.AgentID)}, {"fault epoch", e.FaultEpoch}}
+}
+
+func (e *TxReplace) Describe() []Field {
+	return []Field{{"action", e.Action}, {"original", e.Original}, {"from", addr(e.From)}, {"nonce", strconv.FormatUint(e.Nonce, 10)}}
+}
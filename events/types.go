@@ -3,6 +3,22 @@ package events
 type evtCommon struct {
 	Error string `json:"error,omitempty"`
 	Tx    string `json:"tx,omitempty"`
+
+	// Status is the stage of the transaction lifecycle the entry records,
+	// entries of the same action share the CorrelationID
+	Status        string `json:"status,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// receipt of a confirmed or failed transaction, the block number of a
+	// FEVM transaction is the epoch it landed in
+	GasUsed           uint64 `json:"gas_used,omitempty"`
+	EffectiveGasPrice string `json:"effective_gas_price,omitempty"`
+	TotalFee          string `json:"total_fee,omitempty"`
+	BlockNumber       uint64 `json:"block_number,omitempty"`
+	RevertReason      string `json:"revert_reason,omitempty"`
+
+	// Source is SourceChain for events reconstructed from FEVM logs
+	Source string `json:"source,omitempty"`
 }
 
 type AgentBorrow struct {
@@ -73,6 +89,12 @@ type AgentPay struct {
 	PoolID  string `json:"pool_id"`
 	Amount  string `json:"amount"`
 	PayType string `json:"pay_type"`
+
+	// set for payments reconstructed from FEVM logs, which do not record the
+	// amount paid
+	EpochsPaid    string `json:"epochs_paid,omitempty"`
+	PrincipalPaid string `json:"principal_paid,omitempty"`
+	Refund        string `json:"refund,omitempty"`
 }
 
 type AgentWithdraw struct {
@@ -102,3 +124,81 @@ type AgentAdmin struct {
 	AgentID         string `json:"agent_id"`
 	NewAdminAddress string `json:"new_admin_address,omitempty"`
 }
+
+type InfPoolDepositFIL struct {
+	evtCommon
+	Receiver string `json:"receiver"`
+	Amount   string `json:"amount"`
+}
+
+type InfPoolRedeem struct {
+	evtCommon
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+	Amount   string `json:"amount"`
+}
+
+type InfPoolWithdraw struct {
+	evtCommon
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+	Amount   string `json:"amount"`
+}
+
+type IFILTransfer struct {
+	evtCommon
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
+
+type IFILApprove struct {
+	evtCommon
+	Spender string `json:"spender"`
+	Amount  string `json:"amount"`
+}
+
+type AgentRefreshRoutes struct {
+	evtCommon
+	AgentID string `json:"agent_id"`
+}
+
+type AgentCreate struct {
+	evtCommon
+	Owner     string `json:"owner"`
+	Operator  string `json:"operator"`
+	Requester string `json:"requester"`
+	AgentID   string `json:"agent_id,omitempty"`
+	ID        string `json:"id,omitempty"`
+}
+
+type AgentImport struct {
+	evtCommon
+	AgentID string `json:"agent_id"`
+	ID      string `json:"id"`
+}
+
+type AgentWriteOff struct {
+	evtCommon
+	AgentID        string `json:"agent_id"`
+	PoolID         string `json:"pool_id"`
+	RecoveredFunds string `json:"recovered_funds"`
+	LostFunds      string `json:"lost_funds"`
+	InterestPaid   string `json:"interest_paid"`
+}
+
+type AgentFaultySectors struct {
+	evtCommon
+	AgentID    string `json:"agent_id"`
+	FaultEpoch string `json:"fault_epoch"`
+}
+
+// TxReplace is a transaction sent with the nonce of a pending one, either
+// with the same call and higher fees or as a zero value self-transfer that
+// cancels it
+type TxReplace struct {
+	evtCommon
+	Action   string `json:"action"`
+	Original string `json:"original"`
+	From     string `json:"from"`
+	Nonce    uint64 `json:"nonce"`
+}
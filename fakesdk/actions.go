@@ -0,0 +1,239 @@
+package fakesdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/go-address"
+)
+
+type actions struct {
+	s *SDK
+}
+
+// errInsufficientFunds reverts transactions that move more than is available
+var errInsufficientFunds = errors.New("insufficient funds")
+
+// agentTx sends a transaction of method to the agent, which applies fn to the
+// agent when it lands
+func (a *actions) agentTx(auth *bind.TransactOpts, method string, agentAddr common.Address, tx *Tx, fn func(ag *Agent) error) (*types.Transaction, error) {
+	tx.Method, tx.Agent = method, agentAddr
+	if tx.Amount == nil {
+		tx.Amount = big.NewInt(0)
+	}
+	return a.s.send(auth, tx, agentAddr, nil, func() error {
+		ag, err := a.s.agent(agentAddr)
+		if err != nil {
+			return err
+		}
+		return fn(ag)
+	})
+}
+
+func (a *actions) AgentCreate(ctx context.Context, auth *bind.TransactOpts, owner common.Address, operator common.Address, request common.Address) (*types.Transaction, error) {
+	return nil, notImplemented("AgentCreate")
+}
+
+func (a *actions) AgentBorrow(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, poolID *big.Int, amount *big.Int, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentBorrow", agentAddr, &Tx{Amount: amount}, func(ag *Agent) error {
+		if ag.Account.Principal.Sign() == 0 {
+			ag.Account.StartEpoch = copyInt(a.s.Height)
+			ag.Account.EpochsPaid = copyInt(a.s.Height)
+		}
+		ag.Account.Principal = new(big.Int).Add(ag.Account.Principal, amount)
+		ag.Data.Principal = copyInt(ag.Account.Principal)
+		ag.LiquidAssets = new(big.Int).Add(ag.LiquidAssets, amount)
+		return nil
+	})
+}
+
+// AgentPay pays the interest owed by the agent from its liquid assets, moving
+// its epochs paid to the current height, then pays down its principal
+func (a *actions) AgentPay(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, poolID *big.Int, amount *big.Int, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentPay", agentAddr, &Tx{Amount: amount}, func(ag *Agent) error {
+		if ag.LiquidAssets.Cmp(amount) < 0 {
+			return errInsufficientFunds
+		}
+		ag.LiquidAssets = new(big.Int).Sub(ag.LiquidAssets, amount)
+
+		if amount.Cmp(ag.InterestOwed) < 0 {
+			ag.InterestOwed = new(big.Int).Sub(ag.InterestOwed, amount)
+			return nil
+		}
+		rest := new(big.Int).Sub(amount, ag.InterestOwed)
+		ag.InterestOwed = big.NewInt(0)
+		ag.Account.EpochsPaid = copyInt(a.s.Height)
+		if rest.Cmp(ag.Account.Principal) > 0 {
+			rest = ag.Account.Principal
+		}
+		ag.Account.Principal = new(big.Int).Sub(ag.Account.Principal, rest)
+		ag.Data.Principal = copyInt(ag.Account.Principal)
+		return nil
+	})
+}
+
+func (a *actions) AgentAddMiner(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, minerAddr address.Address, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentAddMiner", agentAddr, &Tx{Miner: minerAddr}, func(ag *Agent) error {
+		for _, m := range ag.Miners {
+			if m == minerAddr {
+				return fmt.Errorf("miner %s already added", minerAddr)
+			}
+		}
+		ag.Miners = append(ag.Miners, minerAddr)
+		return nil
+	})
+}
+
+func (a *actions) AgentRemoveMiner(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, minerAddr address.Address, newOwnerAddr address.Address, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentRemoveMiner", agentAddr, &Tx{Miner: minerAddr}, func(ag *Agent) error {
+		for i, m := range ag.Miners {
+			if m == minerAddr {
+				ag.Miners = append(ag.Miners[:i:i], ag.Miners[i+1:]...)
+				if miner, ok := a.s.Miners[minerAddr]; ok {
+					miner.Owner = newOwnerAddr
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("miner %s not added", minerAddr)
+	})
+}
+
+func (a *actions) AgentChangeMinerWorker(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, minerAddr address.Address, workerAddr address.Address, controlAddrs []address.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentChangeMinerWorker", agentAddr, &Tx{Miner: minerAddr}, func(ag *Agent) error {
+		if miner, ok := a.s.Miners[minerAddr]; ok {
+			miner.Worker = workerAddr
+		}
+		return nil
+	})
+}
+
+func (a *actions) AgentConfirmMinerWorkerChange(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, minerAddr address.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentConfirmMinerWorkerChange", agentAddr, &Tx{Miner: minerAddr}, func(ag *Agent) error {
+		return nil
+	})
+}
+
+// AgentPullFunds moves funds from the available balance of a miner to the
+// liquid assets of the agent
+func (a *actions) AgentPullFunds(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, amount *big.Int, miner address.Address, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentPullFunds", agentAddr, &Tx{Miner: miner, Amount: amount}, func(ag *Agent) error {
+		m, ok := a.s.Miners[miner]
+		if !ok {
+			return fmt.Errorf("miner %s does not exist", miner)
+		}
+		if m.Available.Cmp(amount) < 0 {
+			return errInsufficientFunds
+		}
+		m.Available = new(big.Int).Sub(m.Available, amount)
+		ag.LiquidAssets = new(big.Int).Add(ag.LiquidAssets, amount)
+		return nil
+	})
+}
+
+// AgentPushFunds moves funds from the liquid assets of the agent to a miner
+func (a *actions) AgentPushFunds(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, amount *big.Int, miner address.Address, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentPushFunds", agentAddr, &Tx{Miner: miner, Amount: amount}, func(ag *Agent) error {
+		m, ok := a.s.Miners[miner]
+		if !ok {
+			return fmt.Errorf("miner %s does not exist", miner)
+		}
+		if ag.LiquidAssets.Cmp(amount) < 0 {
+			return errInsufficientFunds
+		}
+		ag.LiquidAssets = new(big.Int).Sub(ag.LiquidAssets, amount)
+		m.Available = new(big.Int).Add(m.Available, amount)
+		return nil
+	})
+}
+
+// AgentWithdraw moves funds from the liquid assets of the agent to the
+// balance of receiver
+func (a *actions) AgentWithdraw(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, receiver common.Address, amount *big.Int, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentWithdraw", agentAddr, &Tx{Amount: amount}, func(ag *Agent) error {
+		if ag.LiquidAssets.Cmp(amount) < 0 {
+			return errInsufficientFunds
+		}
+		ag.LiquidAssets = new(big.Int).Sub(ag.LiquidAssets, amount)
+		balance := a.s.Balances[receiver]
+		if balance == nil {
+			balance = big.NewInt(0)
+		}
+		a.s.Balances[receiver] = new(big.Int).Add(balance, amount)
+		return nil
+	})
+}
+
+func (a *actions) AgentRefreshRoutes(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentRefreshRoutes", agentAddr, &Tx{}, func(ag *Agent) error {
+		return nil
+	})
+}
+
+func (a *actions) AgentSetRecovered(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, requesterKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentSetRecovered", agentAddr, &Tx{}, func(ag *Agent) error {
+		ag.FaultyEpochStart = big.NewInt(0)
+		return nil
+	})
+}
+
+// pending ownership and operator transfers are not tracked, the new owner or
+// operator takes over once the transfer lands
+func (a *actions) AgentTransferOwnership(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, newOwner common.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentTransferOwnership", agentAddr, &Tx{}, func(ag *Agent) error {
+		ag.Owner = newOwner
+		return nil
+	})
+}
+
+func (a *actions) AgentAcceptOwnership(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentAcceptOwnership", agentAddr, &Tx{}, func(ag *Agent) error {
+		return nil
+	})
+}
+
+func (a *actions) AgentTransferOperator(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, newOperator common.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentTransferOperator", agentAddr, &Tx{}, func(ag *Agent) error {
+		ag.Operator = newOperator
+		return nil
+	})
+}
+
+func (a *actions) AgentAcceptOperator(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentAcceptOperator", agentAddr, &Tx{}, func(ag *Agent) error {
+		return nil
+	})
+}
+
+func (a *actions) AgentChangeRequester(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, newRequester common.Address) (*types.Transaction, error) {
+	return a.agentTx(auth, "AgentChangeRequester", agentAddr, &Tx{}, func(ag *Agent) error {
+		ag.Requester = newRequester
+		return nil
+	})
+}
+
+func (a *actions) InfPoolDepositFIL(ctx context.Context, auth *bind.TransactOpts, agentAddr common.Address, amount *big.Int) (*types.Transaction, error) {
+	return nil, notImplemented("InfPoolDepositFIL")
+}
+
+func (a *actions) RampWithdraw(ctx context.Context, auth *bind.TransactOpts, assets *big.Int, sender common.Address, receiver common.Address) (*types.Transaction, error) {
+	return nil, notImplemented("RampWithdraw")
+}
+
+func (a *actions) RampRedeem(ctx context.Context, auth *bind.TransactOpts, shares *big.Int, sender common.Address, receiver common.Address) (*types.Transaction, error) {
+	return nil, notImplemented("RampRedeem")
+}
+
+func (a *actions) IFILTransfer(ctx context.Context, auth *bind.TransactOpts, receiver common.Address, amount *big.Int) (*types.Transaction, error) {
+	return nil, notImplemented("IFILTransfer")
+}
+
+func (a *actions) IFILApprove(ctx context.Context, auth *bind.TransactOpts, spender common.Address, allowance *big.Int) (*types.Transaction, error) {
+	return nil, notImplemented("IFILApprove")
+}
@@ -0,0 +1,39 @@
+package fakesdk
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+	"github.com/glifio/go-pools/abigen"
+	"github.com/glifio/go-pools/constants"
+	"github.com/glifio/go-pools/vc"
+)
+
+// ADO is the fake Agent Data Oracle, it reports the Data of the agents
+type ADO struct {
+	sdk *SDK
+}
+
+func (o *ADO) SignCredential(ctx context.Context, jws string) (abigen.SignedCredential, error) {
+	return abigen.SignedCredential{}, notImplemented("SignCredential")
+}
+
+func (o *ADO) AgentData(ctx context.Context, agentAddr common.Address) (*vc.AgentData, error) {
+	o.sdk.mu.Lock()
+	defer o.sdk.mu.Unlock()
+
+	a, err := o.sdk.agent(agentAddr)
+	if err != nil {
+		return nil, err
+	}
+	data := a.Data
+	return &data, nil
+}
+
+// PreviewAction reports the data of the agent as it is, the action is not
+// simulated
+func (o *ADO) PreviewAction(ctx context.Context, agentAddr common.Address, target address.Address, value *big.Int, method constants.Method) (*vc.AgentData, error) {
+	return o.AgentData(ctx, agentAddr)
+}
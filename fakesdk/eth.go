@@ -0,0 +1,195 @@
+package fakesdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ethAPI is the eth namespace of the Ethereum JSON-RPC API, served to the
+// clients of ConnectEthClient
+type ethAPI struct {
+	s *SDK
+}
+
+// callArgs are the arguments of eth_call that the fake reads
+type callArgs struct {
+	From  *common.Address `json:"from"`
+	To    *common.Address `json:"to"`
+	Data  hexutil.Bytes   `json:"data"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+func (e *ethAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(e.s.ChainID)
+}
+
+func (e *ethAPI) BlockNumber() hexutil.Uint64 {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	return hexutil.Uint64(e.s.Height.Uint64())
+}
+
+// MaxPriorityFeePerGas returns the priority fee transactions get when their
+// sender does not set one
+func (e *ethAPI) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(suggestedTip))
+}
+
+func (e *ethAPI) GetBalance(addr common.Address, block string) *hexutil.Big {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+
+	balance, ok := e.s.Balances[addr]
+	if !ok {
+		balance = big.NewInt(0)
+	}
+	return (*hexutil.Big)(copyInt(balance))
+}
+
+// GetTransactionCount returns the nonce of the landed transactions, or of the
+// pending ones too for the pending block
+func (e *ethAPI) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+
+	if block == "pending" {
+		return hexutil.Uint64(e.s.pendingNonce(addr))
+	}
+	return hexutil.Uint64(e.s.Nonces[addr])
+}
+
+// GetTransactionReceipt returns nil for pending and unknown transactions, as
+// nodes do
+func (e *ethAPI) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+
+	tx := e.s.txByHash(hash)
+	if tx == nil || tx.Receipt == nil {
+		return nil
+	}
+	if e.s.UnindexedReceipts > 0 {
+		e.s.UnindexedReceipts--
+		return nil
+	}
+	return tx.Receipt
+}
+
+// GetTransactionByHash returns nil for unknown transactions and for those that
+// were replaced, which nodes drop
+func (e *ethAPI) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+
+	tx := e.s.txByHash(hash)
+	if tx == nil || (tx.Replaced && tx.Receipt == nil) {
+		return nil, nil
+	}
+	b, err := tx.Tx.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var rpcTx map[string]interface{}
+	if err := json.Unmarshal(b, &rpcTx); err != nil {
+		return nil, err
+	}
+	rpcTx["from"] = tx.From
+	if tx.Receipt != nil {
+		rpcTx["blockNumber"] = (*hexutil.Big)(tx.Receipt.BlockNumber)
+		rpcTx["blockHash"] = tx.Receipt.BlockHash
+	}
+	return rpcTx, nil
+}
+
+// GetBlockByNumber returns the header of a block with the base fee of the SDK
+func (e *ethAPI) GetBlockByNumber(block string, full bool) (*types.Header, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+
+	number := copyInt(e.s.Height)
+	if strings.HasPrefix(block, "0x") {
+		n, err := hexutil.DecodeBig(block)
+		if err != nil {
+			return nil, err
+		}
+		number = n
+	}
+	return &types.Header{
+		Difficulty: big.NewInt(0),
+		Number:     number,
+		GasLimit:   10000000000,
+		Time:       number.Uint64() * 30,
+		BaseFee:    copyInt(e.s.BaseFee),
+	}, nil
+}
+
+// RevertError is an error of Reverts carrying the data the contract reverted
+// with, which calls return as the data of their RPC error as nodes do
+type RevertError struct {
+	Data []byte
+}
+
+// CustomError is a revert with the custom error of signature sig, e.g.
+// "InsufficientLiquidity()"
+func CustomError(sig string) *RevertError {
+	return &RevertError{Data: crypto.Keccak256([]byte(sig))[:4]}
+}
+
+func (e *RevertError) Error() string {
+	return "execution reverted"
+}
+
+func (e *RevertError) ErrorData() interface{} {
+	return hexutil.Encode(e.Data)
+}
+
+// Call replays the transaction of an action, failing with the error of
+// Reverts
+func (e *ethAPI) Call(args callArgs, block string) (hexutil.Bytes, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+
+	data := args.Input
+	if len(data) == 0 {
+		data = args.Data
+	}
+	if err := e.s.Reverts[string(data)]; err != nil {
+		var rerr *RevertError
+		if errors.As(err, &rerr) {
+			return nil, rerr
+		}
+		return nil, fmt.Errorf("execution reverted: %w", err)
+	}
+	return hexutil.Bytes{}, nil
+}
+
+// EstimateGas returns the gas limit of the transactions of the fake
+func (e *ethAPI) EstimateGas(args callArgs, block *string) hexutil.Uint64 {
+	return 1000000
+}
+
+// GetLogs returns no logs, the fake contracts emit no events
+func (e *ethAPI) GetLogs(ctx context.Context, query map[string]interface{}) ([]*types.Log, error) {
+	return []*types.Log{}, nil
+}
+
+// SendRawTransaction submits a signed transaction
+func (e *ethAPI) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := e.s.sendRaw(tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
@@ -0,0 +1,38 @@
+package fakesdk
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/lotus/api"
+	pools "github.com/glifio/go-pools/rpc"
+)
+
+type extern struct {
+	s *SDK
+}
+
+// ConnectEthClient returns a client of an in-process server of the eth
+// namespace
+func (e *extern) ConnectEthClient() (*ethclient.Client, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &ethAPI{e.s}); err != nil {
+		return nil, err
+	}
+	client := rpc.DialInProc(server)
+	return ethclient.NewClient(client), nil
+}
+
+func (e *extern) ConnectLotusClient() (*api.FullNodeStruct, jsonrpc.ClientCloser, error) {
+	return e.s.Lotus.Struct(), func() {}, nil
+}
+
+// ConnectAdoClient points the ADO client of go-pools at the fake ADO
+func (e *extern) ConnectAdoClient(ctx context.Context) (jsonrpc.ClientCloser, error) {
+	pools.ADOClient.SignCredential = e.s.ADO.SignCredential
+	pools.ADOClient.AgentData = e.s.ADO.AgentData
+	pools.ADOClient.PreviewAction = e.s.ADO.PreviewAction
+	return func() {}, nil
+}
@@ -0,0 +1,109 @@
+package fakesdk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api"
+	filtypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// FullNode is the part of the lotus FullNode API that glif uses, served from
+// the state of the SDK
+type FullNode struct {
+	sdk *SDK
+}
+
+// Struct returns an api.FullNodeStruct that calls the methods of the fake,
+// the other methods return api.ErrNotSupported
+func (n *FullNode) Struct() *api.FullNodeStruct {
+	var out api.FullNodeStruct
+	rv := reflect.ValueOf(n)
+	for _, internal := range api.GetInternalStructs(&out) {
+		iv := reflect.ValueOf(internal).Elem()
+		for i := 0; i < iv.NumField(); i++ {
+			field := iv.Field(i)
+			if field.Kind() != reflect.Func {
+				continue
+			}
+			m := rv.MethodByName(iv.Type().Field(i).Name)
+			if m.IsValid() && m.Type() == field.Type() {
+				field.Set(m)
+			}
+		}
+	}
+	return &out
+}
+
+func (n *FullNode) StateLookupID(ctx context.Context, addr address.Address, tsk filtypes.TipSetKey) (address.Address, error) {
+	if addr.Protocol() == address.ID {
+		return addr, nil
+	}
+
+	n.sdk.mu.Lock()
+	defer n.sdk.mu.Unlock()
+	id, ok := n.sdk.IDs[addr]
+	if !ok {
+		return address.Undef, fmt.Errorf("actor not found: %s", addr)
+	}
+	return id, nil
+}
+
+func (n *FullNode) StateGetActor(ctx context.Context, addr address.Address, tsk filtypes.TipSetKey) (*filtypes.Actor, error) {
+	balance, err := n.WalletBalance(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &filtypes.Actor{Balance: balance}, nil
+}
+
+func (n *FullNode) StateMinerAvailableBalance(ctx context.Context, addr address.Address, tsk filtypes.TipSetKey) (filtypes.BigInt, error) {
+	n.sdk.mu.Lock()
+	defer n.sdk.mu.Unlock()
+
+	m, ok := n.sdk.Miners[addr]
+	if !ok {
+		return filtypes.EmptyInt, fmt.Errorf("miner %s not found", addr)
+	}
+	return filtypes.BigInt{Int: copyInt(m.Available)}, nil
+}
+
+func (n *FullNode) StateMinerInfo(ctx context.Context, addr address.Address, tsk filtypes.TipSetKey) (api.MinerInfo, error) {
+	n.sdk.mu.Lock()
+	defer n.sdk.mu.Unlock()
+
+	m, ok := n.sdk.Miners[addr]
+	if !ok {
+		return api.MinerInfo{}, fmt.Errorf("miner %s not found", addr)
+	}
+	return api.MinerInfo{
+		Owner:       m.Owner,
+		Worker:      m.Worker,
+		NewWorker:   address.Undef,
+		Beneficiary: m.Owner,
+	}, nil
+}
+
+// WalletBalance returns the balance of f4 addresses, other addresses have no
+// balance
+func (n *FullNode) WalletBalance(ctx context.Context, addr address.Address) (filtypes.BigInt, error) {
+	if addr.Protocol() != address.Delegated {
+		return filtypes.NewInt(0), nil
+	}
+	ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(addr)
+	if err != nil {
+		return filtypes.EmptyInt, err
+	}
+
+	n.sdk.mu.Lock()
+	defer n.sdk.mu.Unlock()
+	balance, ok := n.sdk.Balances[common.Address(ethAddr)]
+	if !ok {
+		return filtypes.NewInt(0), nil
+	}
+	return filtypes.BigInt{Int: copyInt(balance)}, nil
+}
@@ -0,0 +1,431 @@
+package fakesdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/go-address"
+	filtypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/glifio/go-pools/abigen"
+	"github.com/glifio/go-pools/constants"
+	"github.com/glifio/go-pools/terminate"
+	"github.com/glifio/go-pools/vc"
+)
+
+type queries struct {
+	s *SDK
+}
+
+func copyInt(i *big.Int) *big.Int {
+	return new(big.Int).Set(i)
+}
+
+func copyAccount(a abigen.Account) abigen.Account {
+	return abigen.Account{
+		StartEpoch: copyInt(a.StartEpoch),
+		Principal:  copyInt(a.Principal),
+		EpochsPaid: copyInt(a.EpochsPaid),
+		Defaulted:  a.Defaulted,
+	}
+}
+
+// withAgent calls fn with the agent at agentAddr, under the lock of the SDK
+func (q *queries) withAgent(agentAddr common.Address, fn func(a *Agent)) error {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	a, err := q.s.agent(agentAddr)
+	if err != nil {
+		return err
+	}
+	fn(a)
+	return nil
+}
+
+func (q *queries) AgentID(ctx context.Context, agentAddr common.Address) (id *big.Int, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { id = copyInt(a.ID) })
+	return id, err
+}
+
+func (q *queries) AgentAccount(ctx context.Context, agentAddr common.Address, poolID *big.Int, blockNumber *big.Int) (acc abigen.Account, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { acc = copyAccount(a.Account) })
+	return acc, err
+}
+
+func (q *queries) AgentAddrIDFromRcpt(ctx context.Context, rcpt *types.Receipt) (common.Address, *big.Int, error) {
+	return common.Address{}, nil, notImplemented("AgentAddrIDFromRcpt")
+}
+
+func (q *queries) AgentOwner(ctx context.Context, agentAddr common.Address) (owner common.Address, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { owner = a.Owner })
+	return owner, err
+}
+
+func (q *queries) AgentOperator(ctx context.Context, agentAddr common.Address) (operator common.Address, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { operator = a.Operator })
+	return operator, err
+}
+
+func (q *queries) AgentRequester(ctx context.Context, agentAddr common.Address) (requester common.Address, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { requester = a.Requester })
+	return requester, err
+}
+
+func (q *queries) AgentAdministrator(ctx context.Context, agentAddr common.Address) (admin common.Address, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { admin = a.Administrator })
+	return admin, err
+}
+
+func (q *queries) AgentDefaulted(ctx context.Context, agentAddr common.Address) (defaulted bool, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { defaulted = a.Account.Defaulted })
+	return defaulted, err
+}
+
+func (q *queries) AgentVersion(ctx context.Context, agentAddr common.Address) (uint8, uint8, error) {
+	return 0, 0, notImplemented("AgentVersion")
+}
+
+func (q *queries) AgentIsValid(ctx context.Context, agentAddr common.Address) (bool, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	_, ok := q.s.Agents[agentAddr]
+	return ok, nil
+}
+
+func (q *queries) AgentMiners(ctx context.Context, agentAddr common.Address, blockNumber *big.Int) (miners []address.Address, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { miners = append([]address.Address{}, a.Miners...) })
+	return miners, err
+}
+
+func (q *queries) AgentLiquidAssets(ctx context.Context, agentAddr common.Address, blockNumber *big.Int) (assets *big.Int, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { assets = copyInt(a.LiquidAssets) })
+	return assets, err
+}
+
+func (q *queries) AgentPrincipal(ctx context.Context, agentAddr common.Address, blockNumber *big.Int) (principal *big.Int, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { principal = copyInt(a.Account.Principal) })
+	return principal, err
+}
+
+func (q *queries) AgentInterestOwed(ctx context.Context, agentAddr common.Address, tsk *filtypes.TipSet) (owed *big.Int, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { owed = copyInt(a.InterestOwed) })
+	return owed, err
+}
+
+func (q *queries) AgentFaultyEpochStart(ctx context.Context, agentAddr common.Address) (epoch *big.Int, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) { epoch = copyInt(a.FaultyEpochStart) })
+	return epoch, err
+}
+
+// minersAvailable is the sum of the available balances of the agent's miners
+func (q *queries) minersAvailable(a *Agent) *big.Int {
+	total := big.NewInt(0)
+	for _, id := range a.Miners {
+		if m, ok := q.s.Miners[id]; ok {
+			total.Add(total, m.Available)
+		}
+	}
+	return total
+}
+
+func (q *queries) AgentCollateralStatsQuick(ctx context.Context, agentAddr common.Address) (stats *terminate.AgentCollateralStats, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) {
+		stats = &terminate.AgentCollateralStats{
+			AvailableBalance:   copyInt(a.LiquidAssets),
+			TerminationPenalty: big.NewInt(0),
+		}
+		for _, id := range a.Miners {
+			m, ok := q.s.Miners[id]
+			if !ok {
+				continue
+			}
+			stats.MinersTerminationStats = append(stats.MinersTerminationStats, &terminate.MinerCollateralStat{
+				Address:            id,
+				Total:              new(big.Int).Add(m.Available, m.Pledge),
+				Available:          copyInt(m.Available),
+				Pledged:            copyInt(m.Pledge),
+				Vesting:            big.NewInt(0),
+				TerminationPenalty: big.NewInt(0),
+			})
+		}
+	})
+	return stats, err
+}
+
+func (q *queries) AgentPreviewTerminationPrecise(ctx context.Context, agentAddr common.Address, tipset *filtypes.TipSet) (terminate.PreviewAgentTerminationSummary, error) {
+	return q.AgentPreviewTerminationQuick(ctx, agentAddr)
+}
+
+// AgentPreviewTerminationQuick previews the termination of the agent's miners
+// without termination penalties
+func (q *queries) AgentPreviewTerminationQuick(ctx context.Context, agentAddr common.Address) (summary terminate.PreviewAgentTerminationSummary, err error) {
+	err = q.withAgent(agentAddr, func(a *Agent) {
+		pledge := big.NewInt(0)
+		for _, id := range a.Miners {
+			if m, ok := q.s.Miners[id]; ok {
+				pledge.Add(pledge, m.Pledge)
+			}
+		}
+		summary = terminate.PreviewAgentTerminationSummary{
+			TerminationPenalty: big.NewInt(0),
+			InitialPledge:      pledge,
+			VestingBalance:     big.NewInt(0),
+			MinersAvailableBal: q.minersAvailable(a),
+			AgentAvailableBal:  copyInt(a.LiquidAssets),
+		}
+	})
+	return summary, err
+}
+
+func (q *queries) AgentFactoryAgentCount(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return big.NewInt(int64(len(q.s.Agents))), nil
+}
+
+func (q *queries) InfPoolGetRate(ctx context.Context, cred abigen.VerifiableCredential) (*big.Int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return copyInt(q.s.Rate), nil
+}
+
+func (q *queries) InfPoolRateFromGCRED(ctx context.Context, gcred *big.Int) (*big.Float, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return new(big.Float).SetInt(q.s.Rate), nil
+}
+
+func (q *queries) InfPoolGetAgentLvl(ctx context.Context, agentID *big.Int) (*big.Int, float64, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	for _, a := range q.s.Agents {
+		if a.ID.Cmp(agentID) == 0 {
+			return copyInt(a.Level), a.Cap, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("agent %s does not exist", agentID)
+}
+
+func (q *queries) InfPoolGetAccount(ctx context.Context, agentAddr common.Address, blockNumber *big.Int) (abigen.Account, error) {
+	return q.AgentAccount(ctx, agentAddr, big.NewInt(0), blockNumber)
+}
+
+func (q *queries) InfPoolBorrowableLiquidity(ctx context.Context, blockNumber *big.Int) (*big.Float, error) {
+	return nil, notImplemented("InfPoolBorrowableLiquidity")
+}
+
+func (q *queries) InfPoolTotalAssets(ctx context.Context, blockNumber *big.Int) (*big.Float, error) {
+	return nil, notImplemented("InfPoolTotalAssets")
+}
+
+func (q *queries) InfPoolTotalBorrowed(ctx context.Context, blockNumber *big.Int) (*big.Float, error) {
+	return nil, notImplemented("InfPoolTotalBorrowed")
+}
+
+func (q *queries) InfPoolExitReserve(ctx context.Context, blockNumber *big.Int) (*big.Int, *big.Int, error) {
+	return nil, nil, notImplemented("InfPoolExitReserve")
+}
+
+func (q *queries) InfPoolAgentMaxBorrow(ctx context.Context, agentAddr common.Address, agentData *vc.AgentData) (*big.Int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return copyInt(q.s.MaxBorrow), nil
+}
+
+func (q *queries) InfPoolMaxEpochsOwedTolerance(ctx context.Context, agentAddr common.Address) (*big.Int, error) {
+	return nil, notImplemented("InfPoolMaxEpochsOwedTolerance")
+}
+
+func (q *queries) InfPoolFeesAccrued(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	return nil, notImplemented("InfPoolFeesAccrued")
+}
+
+func (q *queries) InfPoolApy(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	return nil, notImplemented("InfPoolApy")
+}
+
+func (q *queries) ListPools(ctx context.Context) ([]common.Address, error) {
+	return []common.Address{InfinityPoolAddr}, nil
+}
+
+func (q *queries) TreasuryFeeRate(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	return nil, notImplemented("TreasuryFeeRate")
+}
+
+func (q *queries) IFILBalanceOf(ctx context.Context, hodler common.Address) (*big.Float, error) {
+	return nil, notImplemented("IFILBalanceOf")
+}
+
+func (q *queries) IFILPrice(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	return nil, notImplemented("IFILPrice")
+}
+
+func (q *queries) IFILSupply(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	return nil, notImplemented("IFILSupply")
+}
+
+func (q *queries) IFILMinter(ctx context.Context) (common.Address, error) {
+	return common.Address{}, notImplemented("IFILMinter")
+}
+
+func (q *queries) IFILBurner(ctx context.Context) (common.Address, error) {
+	return common.Address{}, notImplemented("IFILBurner")
+}
+
+func (q *queries) WFILBalanceOf(ctx context.Context, hodler common.Address) (*big.Float, error) {
+	return nil, notImplemented("WFILBalanceOf")
+}
+
+func (q *queries) WFILAllowance(ctx context.Context, hodler common.Address, spender common.Address) (*big.Float, error) {
+	return nil, notImplemented("WFILAllowance")
+}
+
+func (q *queries) CredentialUsed(ctx context.Context, v uint8, r [32]byte, s [32]byte, blockNumber *big.Int) (bool, error) {
+	return false, nil
+}
+
+func (q *queries) CredentialValidityPeriod(ctx context.Context) (*big.Int, *big.Int, error) {
+	return nil, nil, notImplemented("CredentialValidityPeriod")
+}
+
+func (q *queries) DefaultEpoch(ctx context.Context) (*big.Int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return copyInt(q.s.DefaultEpoch), nil
+}
+
+func (q *queries) MaxConsecutiveFaultEpochs(ctx context.Context) (*big.Int, error) {
+	return nil, notImplemented("MaxConsecutiveFaultEpochs")
+}
+
+func (q *queries) SectorFaultyTolerance(ctx context.Context) (*big.Int, error) {
+	return nil, notImplemented("SectorFaultyTolerance")
+}
+
+func (q *queries) MinerRegistryAgentMinersCount(ctx context.Context, agentID *big.Int, blockNumber *big.Int) (*big.Int, error) {
+	miners, err := q.MinerRegistryAgentMinersList(ctx, agentID, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(int64(len(miners))), nil
+}
+
+func (q *queries) MinerRegistryAgentMinersList(ctx context.Context, agentID *big.Int, blockNumber *big.Int) ([]address.Address, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	for _, a := range q.s.Agents {
+		if a.ID.Cmp(agentID) == 0 {
+			return append([]address.Address{}, a.Miners...), nil
+		}
+	}
+	return nil, fmt.Errorf("agent %s does not exist", agentID)
+}
+
+func (q *queries) ChainHeight(ctx context.Context) (*big.Int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return copyInt(q.s.Height), nil
+}
+
+func (q *queries) ChainHead(ctx context.Context) (*filtypes.TipSet, error) {
+	return nil, notImplemented("ChainHead")
+}
+
+func (q *queries) ChainID() *big.Int {
+	return copyInt(q.s.ChainID)
+}
+
+func (q *queries) ChainGetNonce(ctx context.Context, fromAddr common.Address) (*big.Int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+	return new(big.Int).SetUint64(q.s.pendingNonce(fromAddr)), nil
+}
+
+func (q *queries) StateWaitTx(ctx context.Context, txHash common.Hash, ch chan *types.Receipt) {
+	receipt, err := q.waitLanded(ctx, txHash)
+	if err == nil {
+		ch <- receipt
+	}
+}
+
+// StateWaitReceipt waits for the transaction to land, calling OnWait first
+// when it is pending. Like the SDK, it fails on reverted transactions.
+func (q *queries) StateWaitReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	q.s.mu.Lock()
+	waitErr := q.s.WaitError
+	q.s.mu.Unlock()
+	if waitErr != nil {
+		return nil, waitErr
+	}
+
+	receipt, err := q.waitLanded(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction failed: %v", receipt.Status)
+	}
+	return receipt, nil
+}
+
+func (q *queries) waitLanded(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	notified := false
+	for {
+		q.s.mu.Lock()
+		tx := q.s.txByHash(txHash)
+		if tx == nil {
+			q.s.mu.Unlock()
+			return nil, fmt.Errorf("transaction %s not found", txHash)
+		}
+		if tx.Receipt != nil {
+			q.s.mu.Unlock()
+			return tx.Receipt, nil
+		}
+		landed, onWait := q.s.landed, q.s.OnWait
+		q.s.mu.Unlock()
+
+		if !notified && onWait != nil {
+			notified = true
+			onWait(tx)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.New("timed out waiting for transaction")
+		case <-landed:
+		}
+	}
+}
+
+func (q *queries) StateWaitNextTick(ctx context.Context, currentEpochHeight *big.Int) error {
+	return nil
+}
+
+func (q *queries) RouterOwner(ctx context.Context) (common.Address, error) {
+	return common.Address{}, notImplemented("RouterOwner")
+}
+
+func (q *queries) RouterGetRoute(ctx context.Context, route constants.Route) (common.Address, error) {
+	return common.Address{}, notImplemented("RouterGetRoute")
+}
+
+func (q *queries) AgentPolice() common.Address   { return AgentPoliceAddr }
+func (q *queries) MinerRegistry() common.Address { return MinerRegistryAddr }
+func (q *queries) Router() common.Address        { return RouterAddr }
+func (q *queries) PoolRegistry() common.Address  { return PoolRegistryAddr }
+func (q *queries) AgentFactory() common.Address  { return AgentFactoryAddr }
+func (q *queries) IFIL() common.Address          { return IFILAddr }
+func (q *queries) WFIL() common.Address          { return WFILAddr }
+func (q *queries) InfinityPool() common.Address  { return InfinityPoolAddr }
+func (q *queries) SimpleRamp() common.Address    { return SimpleRampAddr }
+
+func (q *queries) RateModule() (common.Address, error) {
+	return common.Address{}, notImplemented("RateModule")
+}
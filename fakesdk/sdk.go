@@ -0,0 +1,440 @@
+// Package fakesdk is an in-memory stand-in for the go-pools SDK, the lotus
+// FullNode API, the Ethereum JSON-RPC API and the Agent Data Oracle, for tests
+// that run glif commands without a live node.
+//
+// Tests program the state of agents, miners and wallets, run a command, and
+// then assert on the transactions it sent. Transactions land as soon as they
+// are sent, unless HoldTxs is set, in which case they stay pending until Mine
+// is called.
+package fakesdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/go-address"
+	"github.com/glifio/go-pools/abigen"
+	"github.com/glifio/go-pools/constants"
+	ptypes "github.com/glifio/go-pools/types"
+	"github.com/glifio/go-pools/vc"
+)
+
+// ErrNotImplemented is returned by the methods the fake does not simulate
+var ErrNotImplemented = errors.New("not implemented by the fake SDK")
+
+func notImplemented(method string) error {
+	return fmt.Errorf("%s: %w", method, ErrNotImplemented)
+}
+
+// Addresses of the fake deployment
+var (
+	InfinityPoolAddr  = common.HexToAddress("0x00000000000000000000000000000000000001f1")
+	AgentFactoryAddr  = common.HexToAddress("0x00000000000000000000000000000000000001fa")
+	RouterAddr        = common.HexToAddress("0x00000000000000000000000000000000000001f0")
+	AgentPoliceAddr   = common.HexToAddress("0x00000000000000000000000000000000000001fc")
+	MinerRegistryAddr = common.HexToAddress("0x00000000000000000000000000000000000001fd")
+	PoolRegistryAddr  = common.HexToAddress("0x00000000000000000000000000000000000001fe")
+	IFILAddr          = common.HexToAddress("0x00000000000000000000000000000000000001e1")
+	WFILAddr          = common.HexToAddress("0x00000000000000000000000000000000000001e2")
+	SimpleRampAddr    = common.HexToAddress("0x00000000000000000000000000000000000001e3")
+)
+
+// Agent is the on-chain state of an agent, and the data the ADO reports for it
+type Agent struct {
+	Address       common.Address
+	ID            *big.Int
+	Owner         common.Address
+	Operator      common.Address
+	Requester     common.Address
+	Administrator common.Address
+	Miners        []address.Address
+	LiquidAssets  *big.Int
+	// Account is the agent's account with the infinity pool
+	Account      abigen.Account
+	InterestOwed *big.Int
+	// FaultyEpochStart is the epoch the agent's miners started faulting at,
+	// zero when they are not faulty
+	FaultyEpochStart *big.Int
+	Level            *big.Int
+	Cap              float64
+	// Data is returned by the ADO, with the principal of the account
+	Data vc.AgentData
+}
+
+// Miner is the state of a miner actor
+type Miner struct {
+	ID        address.Address
+	Owner     address.Address
+	Worker    address.Address
+	Available *big.Int
+	Pledge    *big.Int
+}
+
+// Tx is a transaction sent through the fake SDK
+type Tx struct {
+	// Method is the name of the SDK action that sent the transaction, e.g.
+	// AgentPay
+	Method string
+	From   common.Address
+	Agent  common.Address
+	Miner  address.Address
+	Amount *big.Int
+	Tx     *types.Transaction
+	// Receipt is nil while the transaction is pending
+	Receipt *types.Receipt
+	// Replaced is set once a transaction with the same nonce and higher fees
+	// replaced this one
+	Replaced bool
+
+	apply func() error
+}
+
+// suggestedTip is the priority fee of transactions whose sender does not set
+// one
+const suggestedTip = 1000
+
+// SDK implements types.PoolsSDK over in-memory state. Its fields may be set
+// before a command runs, and read once it completed.
+type SDK struct {
+	mu sync.Mutex
+
+	ChainID      *big.Int
+	Height       *big.Int
+	BaseFee      *big.Int
+	DefaultEpoch *big.Int
+	// Rate is the interest rate per epoch of the infinity pool, scaled by
+	// 1e36
+	Rate      *big.Int
+	MaxBorrow *big.Int
+
+	Agents map[common.Address]*Agent
+	Miners map[address.Address]*Miner
+	// Balances are the FIL balances of wallets
+	Balances map[common.Address]*big.Int
+	// Nonces are the nonces of the landed transactions of each sender
+	Nonces map[common.Address]uint64
+	// IDs resolves addresses to their actor ID address
+	IDs map[address.Address]address.Address
+
+	// Txs are the transactions that were sent, in order
+	Txs []*Tx
+	// HoldTxs keeps sent transactions pending until Mine is called
+	HoldTxs bool
+	// Errors fail the actions by method name before a transaction is sent,
+	// as a failed gas estimation does
+	Errors map[string]error
+	// Reverts make the transactions of an action land with a failed receipt,
+	// and calls replaying them fail with the error
+	Reverts map[string]error
+	// OnWait is called when a command starts waiting for a transaction that
+	// did not land yet
+	OnWait func(tx *Tx)
+	// WaitError fails the waits for transactions, as a node that cannot be
+	// reached does
+	WaitError error
+	// UnindexedReceipts is the number of lookups of landed transactions that
+	// find no receipt, as on a node that did not index the latest block yet
+	UnindexedReceipts int
+
+	// ADO is the fake Agent Data Oracle
+	ADO *ADO
+	// Lotus is the fake lotus FullNode API
+	Lotus *FullNode
+
+	// prepared are the transactions of actions that were not sent, by
+	// their signing hash
+	prepared map[common.Hash]*Tx
+	landed   chan struct{}
+}
+
+var _ ptypes.PoolsSDK = (*SDK)(nil)
+
+// New returns a fake SDK at height 1000, with the agents' payments due by
+// DefaultEpoch
+func New() *SDK {
+	s := &SDK{
+		ChainID:      big.NewInt(constants.LocalnetChainID),
+		Height:       big.NewInt(1000),
+		BaseFee:      big.NewInt(100),
+		DefaultEpoch: big.NewInt(0),
+		Rate:         big.NewInt(0),
+		MaxBorrow:    big.NewInt(0),
+		Agents:       map[common.Address]*Agent{},
+		Miners:       map[address.Address]*Miner{},
+		Balances:     map[common.Address]*big.Int{},
+		Nonces:       map[common.Address]uint64{},
+		IDs:          map[address.Address]address.Address{},
+		Errors:       map[string]error{},
+		Reverts:      map[string]error{},
+		prepared:     map[common.Hash]*Tx{},
+		landed:       make(chan struct{}),
+	}
+	s.ADO = &ADO{sdk: s}
+	s.Lotus = &FullNode{sdk: s}
+	return s
+}
+
+// AddAgent adds an agent, filling in the state it leaves empty
+func (s *SDK) AddAgent(a *Agent) *Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range []**big.Int{&a.ID, &a.LiquidAssets, &a.InterestOwed, &a.FaultyEpochStart, &a.Level,
+		&a.Account.StartEpoch, &a.Account.Principal, &a.Account.EpochsPaid} {
+		if *v == nil {
+			*v = big.NewInt(0)
+		}
+	}
+	d := &a.Data
+	for _, v := range []**big.Int{&d.AgentValue, &d.CollateralValue, &d.ExpectedDailyFaultPenalties,
+		&d.ExpectedDailyRewards, &d.Gcred, &d.QaPower, &d.Principal, &d.FaultySectors, &d.LiveSectors, &d.GreenScore} {
+		if *v == nil {
+			*v = big.NewInt(0)
+		}
+	}
+	s.Agents[a.Address] = a
+	return a
+}
+
+// AddMiner adds a miner actor
+func (s *SDK) AddMiner(m *Miner) *Miner {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m.Available == nil {
+		m.Available = big.NewInt(0)
+	}
+	if m.Pledge == nil {
+		m.Pledge = big.NewInt(0)
+	}
+	s.Miners[m.ID] = m
+	return m
+}
+
+// SetBalance sets the FIL balance of a wallet
+func (s *SDK) SetBalance(addr common.Address, balance *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Balances[addr] = balance
+}
+
+// Sent returns the transactions sent by the action method
+func (s *SDK) Sent(method string) []*Tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var txs []*Tx
+	for _, tx := range s.Txs {
+		if tx.Method == method {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// Mine lands the pending transactions at the next height
+func (s *SDK) Mine() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Height = new(big.Int).Add(s.Height, big.NewInt(1))
+	for _, tx := range s.Txs {
+		if tx.Receipt == nil && !tx.Replaced {
+			s.land(tx)
+		}
+	}
+}
+
+func (s *SDK) agent(addr common.Address) (*Agent, error) {
+	a, ok := s.Agents[addr]
+	if !ok {
+		return nil, fmt.Errorf("agent %s does not exist", addr)
+	}
+	return a, nil
+}
+
+func (s *SDK) txByHash(hash common.Hash) *Tx {
+	for _, tx := range s.Txs {
+		if tx.Tx.Hash() == hash {
+			return tx
+		}
+	}
+	return nil
+}
+
+// pendingNonce is the nonce of the next transaction of from
+func (s *SDK) pendingNonce(from common.Address) uint64 {
+	nonce := s.Nonces[from]
+	for _, tx := range s.Txs {
+		if tx.From == from && tx.Receipt == nil && !tx.Replaced && tx.Tx.Nonce() >= nonce {
+			nonce = tx.Tx.Nonce() + 1
+		}
+	}
+	return nonce
+}
+
+// send signs a transaction of method with auth, and submits it unless
+// auth.NoSend is set. apply changes the state once the transaction lands
+// successfully.
+func (s *SDK) send(auth *bind.TransactOpts, tx *Tx, to common.Address, value *big.Int, apply func() error) (*types.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Errors[tx.Method]; err != nil {
+		return nil, err
+	}
+	if auth == nil || auth.Signer == nil {
+		return nil, fmt.Errorf("%s: no signer", tx.Method)
+	}
+
+	nonce := s.pendingNonce(auth.From)
+	if auth.Nonce != nil {
+		nonce = auth.Nonce.Uint64()
+	}
+	tipCap := auth.GasTipCap
+	if tipCap == nil {
+		tipCap = big.NewInt(suggestedTip)
+	}
+	feeCap := auth.GasFeeCap
+	if feeCap == nil {
+		feeCap = new(big.Int).Add(new(big.Int).Mul(s.BaseFee, big.NewInt(2)), tipCap)
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	unsigned := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   s.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       1000000,
+		To:        &to,
+		Value:     value,
+		Data:      []byte(tx.Method),
+	})
+	signed, err := auth.Signer(auth.From, unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.apply = apply
+	// transactions that are not sent are kept until they are broadcast
+	// signed, which is how offline signing works
+	if auth.NoSend {
+		s.prepared[s.signer().Hash(signed)] = tx
+		return signed, nil
+	}
+	if err := s.submit(tx, auth.From, signed); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// signer signs and recovers the transactions of the chain
+func (s *SDK) signer() types.Signer {
+	return types.LatestSignerForChainID(s.ChainID)
+}
+
+// submit adds the signed transaction to the pending ones, and lands it unless
+// HoldTxs is set
+func (s *SDK) submit(tx *Tx, from common.Address, signed *types.Transaction) error {
+	if signed.Nonce() < s.Nonces[from] {
+		return fmt.Errorf("nonce too low: next nonce %d, tx nonce %d", s.Nonces[from], signed.Nonce())
+	}
+
+	// a pending transaction with the same nonce is only replaced by one that
+	// raises its fees, as the mpool requires
+	for _, other := range s.Txs {
+		if other.From != from || other.Tx.Nonce() != signed.Nonce() || other.Replaced {
+			continue
+		}
+		if other.Receipt != nil {
+			return fmt.Errorf("nonce too low: nonce %d was used", signed.Nonce())
+		}
+		if signed.GasFeeCap().Cmp(other.Tx.GasFeeCap()) <= 0 || signed.GasTipCap().Cmp(other.Tx.GasTipCap()) <= 0 {
+			return errors.New("replacement transaction underpriced")
+		}
+		other.Replaced = true
+		// a replacement signed outside of the SDK with the same call makes
+		// the same state changes
+		if tx.apply == nil && bytes.Equal(signed.Data(), other.Tx.Data()) {
+			tx.Agent, tx.Miner, tx.Amount, tx.apply = other.Agent, other.Miner, other.Amount, other.apply
+		}
+	}
+
+	tx.From = from
+	tx.Tx = signed
+	s.Txs = append(s.Txs, tx)
+	if !s.HoldTxs {
+		s.land(tx)
+	}
+	return nil
+}
+
+// sendRaw submits a transaction signed outside of the SDK. Transactions that
+// an action prepared without sending keep the state changes of the action.
+func (s *SDK) sendRaw(signed *types.Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, err := types.Sender(s.signer(), signed)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	hash := s.signer().Hash(signed)
+	tx, ok := s.prepared[hash]
+	if ok {
+		delete(s.prepared, hash)
+	} else {
+		tx = &Tx{Method: string(signed.Data()), Amount: signed.Value()}
+	}
+	return s.submit(tx, from, signed)
+}
+
+// land executes the transaction at the current height, its state changes
+// are only applied when it does not revert
+func (s *SDK) land(tx *Tx) {
+	status := types.ReceiptStatusSuccessful
+	if s.Reverts[tx.Method] != nil {
+		status = types.ReceiptStatusFailed
+	} else if tx.apply != nil {
+		if err := tx.apply(); err != nil {
+			status = types.ReceiptStatusFailed
+		}
+	}
+
+	s.Nonces[tx.From] = tx.Tx.Nonce() + 1
+	tx.Receipt = &types.Receipt{
+		Type:              tx.Tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: 100000,
+		Logs:              []*types.Log{},
+		TxHash:            tx.Tx.Hash(),
+		GasUsed:           100000,
+		EffectiveGasPrice: new(big.Int).Add(s.BaseFee, tx.Tx.GasTipCap()),
+		BlockHash:         common.BigToHash(s.Height),
+		BlockNumber:       new(big.Int).Set(s.Height),
+	}
+
+	close(s.landed)
+	s.landed = make(chan struct{})
+}
+
+func (s *SDK) Query() ptypes.FEVMQueries {
+	return &queries{s}
+}
+
+func (s *SDK) Act() ptypes.FEVMActions {
+	return &actions{s}
+}
+
+func (s *SDK) Extern() ptypes.FEVMExtern {
+	return &extern{s}
+}
@@ -13,9 +13,11 @@ import (
 // Alerting provides simple stateful alert system. Consumers can register alerts,
 // which can be raised and resolved.
 //
-// When an alert is raised or resolved, a related journal entry is recorded.
+// When an alert is raised or resolved, a related journal entry is recorded and,
+// if a Notifier is set, the state change is sent to its sinks.
 type Alerting struct {
 	j journal.Journal
+	n *Notifier
 
 	lk     sync.Mutex
 	alerts map[AlertType]Alert
@@ -51,6 +53,14 @@ func NewAlertingSystem(j journal.Journal) *Alerting {
 	}
 }
 
+// SetNotifier sets the notifier that alert state changes are sent to
+func (a *Alerting) SetNotifier(n *Notifier) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	a.n = n
+}
+
 func (a *Alerting) AddAlertType(system, subsystem string) AlertType {
 	a.lk.Lock()
 	defer a.lk.Unlock()
@@ -77,7 +87,15 @@ func (a *Alerting) AddAlertType(system, subsystem string) AlertType {
 
 func (a *Alerting) update(at AlertType, message interface{}, upd func(Alert, json.RawMessage) Alert) {
 	a.lk.Lock()
-	defer a.lk.Unlock()
+	var notify *Notification
+	defer func() {
+		n := a.n
+		a.lk.Unlock()
+		// sinks can be slow, so deliver outside of the lock
+		if notify != nil && n != nil {
+			n.Notify(*notify)
+		}
+	}()
 
 	alert, ok := a.alerts[at]
 	if !ok {
@@ -95,14 +113,25 @@ func (a *Alerting) update(at AlertType, message interface{}, upd func(Alert, jso
 		log.Println("marshaling marshaling error failed", "type", at, "error", err)
 	}
 
-	a.alerts[at] = upd(alert, rawMsg)
+	updated := upd(alert, rawMsg)
+	a.alerts[at] = updated
+
+	if updated.Active && updated.LastActive != nil && (!alert.Active || a.n.due(at)) {
+		notify = &Notification{Type: at, Event: *updated.LastActive}
+	}
+	if alert.Active && !updated.Active && updated.LastResolved != nil {
+		notify = &Notification{Type: at, Event: *updated.LastResolved}
+	}
 }
 
-// Raise marks the alert condition as active and records related event in the journal
+// Raise marks the alert condition as active and records related event in the journal.
+// Raising an alert that is already active only refreshes its message.
 func (a *Alerting) Raise(at AlertType, message interface{}) {
 	log.Println("alert raised", "type", at, "message", message)
 
 	a.update(at, message, func(alert Alert, rawMsg json.RawMessage) Alert {
+		wasActive := alert.Active
+
 		alert.Active = true
 		alert.LastActive = &AlertEvent{
 			Type:    "raised",
@@ -110,16 +139,23 @@ func (a *Alerting) Raise(at AlertType, message interface{}) {
 			Time:    time.Now(),
 		}
 
-		a.j.RecordEvent(alert.journalType, func() interface{} {
-			return alert.LastActive
-		})
+		if !wasActive {
+			a.j.RecordEvent(alert.journalType, func() interface{} {
+				return alert.LastActive
+			})
+		}
 
 		return alert
 	})
 }
 
-// Resolve marks the alert condition as resolved and records related event in the journal
+// Resolve marks the alert condition as resolved and records related event in the journal.
+// Resolving an alert that is not active is a no-op.
 func (a *Alerting) Resolve(at AlertType, message interface{}) {
+	if !a.IsRaised(at) {
+		return
+	}
+
 	log.Println("alert resolved", "type", at, "message", message)
 
 	a.update(at, message, func(alert Alert, rawMsg json.RawMessage) Alert {
@@ -4,6 +4,7 @@ package alerting
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -61,3 +62,58 @@ func TestAlerting(t *testing.T) {
 	require.Nil(t, l[1].LastActive)
 	require.Nil(t, l[1].LastResolved)
 }
+
+type testSink struct {
+	sent []Notification
+}
+
+func (s *testSink) Name() string { return "test" }
+
+func (s *testSink) Send(n Notification) error {
+	s.sent = append(s.sent, n)
+	return nil
+}
+
+func TestAlertingNotifier(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	j := mockjournal.NewMockJournal(mockCtrl)
+
+	sink := &testSink{}
+	a := NewAlertingSystem(j)
+	a.SetNotifier(NewNotifier(0, sink))
+
+	j.EXPECT().RegisterEventType("s1", "b1").Return(journal.EventType{System: "s1", Event: "b1"})
+	al := a.AddAlertType("s1", "b1")
+
+	// resolving an inactive alert is a no-op
+	a.Resolve(al, "ok")
+	require.Len(t, sink.sent, 0)
+
+	// raising twice only notifies and journals the transition
+	j.EXPECT().RecordEvent(a.alerts[al].journalType, gomock.Any()).Times(1)
+	a.Raise(al, "down")
+	a.Raise(al, "still down")
+	require.Len(t, sink.sent, 1)
+	require.Equal(t, "raised", sink.sent[0].Event.Type)
+	require.Equal(t, json.RawMessage(`"down"`), sink.sent[0].Event.Message)
+
+	j.EXPECT().RecordEvent(a.alerts[al].journalType, gomock.Any()).Times(1)
+	a.Resolve(al, "up")
+	require.Len(t, sink.sent, 2)
+	require.Equal(t, "resolved", sink.sent[1].Event.Type)
+}
+
+func TestNotifierRenotify(t *testing.T) {
+	at := AlertType{System: "s1", Subsystem: "b1"}
+
+	n := NewNotifier(time.Hour)
+	require.True(t, n.due(at))
+	n.Notify(Notification{Type: at})
+	require.False(t, n.due(at))
+
+	n.last[at] = time.Now().Add(-2 * time.Hour)
+	require.True(t, n.due(at))
+
+	require.False(t, NewNotifier(0).due(at))
+}
@@ -0,0 +1,73 @@
+package alerting
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Notification is sent to sinks when an alert is raised or resolved, and
+// again every re-notify interval while an alert stays raised.
+type Notification struct {
+	Type  AlertType
+	Event AlertEvent
+}
+
+func (n Notification) String() string {
+	return fmt.Sprintf("[%s] %s:%s %s", n.Event.Type, n.Type.System, n.Type.Subsystem, string(n.Event.Message))
+}
+
+// Sink delivers alert notifications to an external system
+type Sink interface {
+	Name() string
+	Send(n Notification) error
+}
+
+// Notifier fans notifications out to a set of sinks. It de-duplicates
+// notifications of alerts that stay raised, re-sending them at most once per
+// re-notify interval.
+type Notifier struct {
+	sinks    []Sink
+	renotify time.Duration
+
+	lk   sync.Mutex
+	last map[AlertType]time.Time
+}
+
+// NewNotifier creates a notifier, a zero renotify interval disables
+// re-notifications of alerts that stay raised
+func NewNotifier(renotify time.Duration, sinks ...Sink) *Notifier {
+	return &Notifier{
+		sinks:    sinks,
+		renotify: renotify,
+		last:     map[AlertType]time.Time{},
+	}
+}
+
+// Notify sends the notification to all sinks, a failing sink does not prevent
+// delivery to the others
+func (n *Notifier) Notify(notification Notification) {
+	n.lk.Lock()
+	n.last[notification.Type] = time.Now()
+	n.lk.Unlock()
+
+	for _, s := range n.sinks {
+		if err := s.Send(notification); err != nil {
+			log.Printf("failed to send alert to %s sink: %s", s.Name(), err)
+		}
+	}
+}
+
+// due returns whether an alert that is still raised should be notified again
+func (n *Notifier) due(at AlertType) bool {
+	if n == nil || n.renotify <= 0 {
+		return false
+	}
+
+	n.lk.Lock()
+	defer n.lk.Unlock()
+
+	last, ok := n.last[at]
+	return !ok || time.Since(last) >= n.renotify
+}
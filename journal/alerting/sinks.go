@@ -0,0 +1,161 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const sinkTimeout = 30 * time.Second
+
+// webhookPayload is the JSON document POSTed by the webhook sink
+type webhookPayload struct {
+	System    string          `json:"system"`
+	Subsystem string          `json:"subsystem"`
+	State     string          `json:"state"`
+	Message   json.RawMessage `json:"message"`
+	Time      time.Time       `json:"time"`
+}
+
+func newWebhookPayload(n Notification) webhookPayload {
+	msg := n.Event.Message
+	if len(msg) == 0 {
+		msg = json.RawMessage("null")
+	}
+	return webhookPayload{
+		System:    n.Type.System,
+		Subsystem: n.Type.Subsystem,
+		State:     n.Event.Type,
+		Message:   msg,
+		Time:      n.Event.Time,
+	}
+}
+
+// WebhookSink POSTs each notification as a JSON document to a URL
+type WebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, client: &http.Client{Timeout: sinkTimeout}}
+}
+
+func (w *WebhookSink) Name() string { return "webhook" }
+
+func (w *WebhookSink) Send(n Notification) error {
+	return postJSON(w.client, w.URL, newWebhookPayload(n))
+}
+
+// ChatWebhookSink POSTs a text message to a Slack or Discord compatible
+// incoming webhook
+type ChatWebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+func NewChatWebhookSink(url string) *ChatWebhookSink {
+	return &ChatWebhookSink{URL: url, client: &http.Client{Timeout: sinkTimeout}}
+}
+
+func (c *ChatWebhookSink) Name() string { return "chat-webhook" }
+
+func (c *ChatWebhookSink) Send(n Notification) error {
+	text := n.String()
+	// slack reads the text field and discord the content field
+	return postJSON(c.client, c.URL, map[string]string{
+		"text":    text,
+		"content": text,
+	})
+}
+
+func postJSON(client *http.Client, url string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailSink sends each notification as a plain text email over SMTP
+type EmailSink struct {
+	// Server is the host:port of the SMTP server
+	Server   string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (e *EmailSink) Name() string { return "email" }
+
+func (e *EmailSink) Send(n Notification) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		host := e.Server
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+
+	subject := fmt.Sprintf("GLIF alert %s: %s:%s", n.Event.Type, n.Type.System, n.Type.Subsystem)
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", e.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", n.Event.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&body, "%s\r\n", n.String())
+
+	return smtp.SendMail(e.Server, auth, e.From, e.To, body.Bytes())
+}
+
+// ExecSink runs a command for each notification. The notification is passed
+// as a JSON document on stdin, and as GLIF_ALERT_* environment variables.
+type ExecSink struct {
+	Command string
+	Args    []string
+}
+
+func (e *ExecSink) Name() string { return "exec" }
+
+func (e *ExecSink) Send(n Notification) error {
+	b, err := json.Marshal(newWebhookPayload(n))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Env = append(os.Environ(),
+		"GLIF_ALERT_SYSTEM="+n.Type.System,
+		"GLIF_ALERT_SUBSYSTEM="+n.Type.Subsystem,
+		"GLIF_ALERT_STATE="+n.Event.Type,
+		"GLIF_ALERT_MESSAGE="+string(n.Event.Message),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package chainindex stores the events of an agent that were reconstructed
+// from chain, so that later scans only fetch the blocks that were not
+// scanned yet.
+package chainindex
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/xerrors"
+
+	"github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/util"
+)
+
+// Entry is an event reconstructed from a log of the block
+type Entry struct {
+	Block uint64        `json:"block"`
+	Event journal.Event `json:"event"`
+}
+
+// Index is the checkpointed list of the events of an agent. Blocks up to the
+// checkpoint are final and never scanned again, entries of later blocks are
+// dropped and scanned again by the next scan, as they may have been reorged.
+type Index struct {
+	Checkpoint uint64  `json:"checkpoint"`
+	Entries    []Entry `json:"entries"`
+
+	path string
+}
+
+// Open reads the index stored at path, or returns an empty index if there is
+// none yet
+func Open(path string) (*Index, error) {
+	ix := &Index{path: path}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ix, nil
+		}
+		return nil, xerrors.Errorf("failed to read chain index: %w", err)
+	}
+	if err := json.Unmarshal(b, ix); err != nil {
+		return nil, xerrors.Errorf("failed to decode chain index %s: %w", path, err)
+	}
+
+	ix.truncate()
+	return ix, nil
+}
+
+// Next returns the first block the next scan must fetch, or start if the
+// index was never checkpointed past it
+func (ix *Index) Next(start uint64) uint64 {
+	if ix.Checkpoint < start {
+		return start
+	}
+	return ix.Checkpoint + 1
+}
+
+// Add records the entries found while scanning up to the to block, and moves
+// the checkpoint up to the final block
+func (ix *Index) Add(entries []Entry, to uint64, final uint64) {
+	ix.Entries = append(ix.Entries, entries...)
+	sort.SliceStable(ix.Entries, func(i, j int) bool {
+		return ix.Entries[i].Block < ix.Entries[j].Block
+	})
+
+	if final > to {
+		final = to
+	}
+	if final > ix.Checkpoint {
+		ix.Checkpoint = final
+	}
+}
+
+// Reset drops all entries so that the chain is scanned again from the start
+func (ix *Index) Reset() {
+	ix.Checkpoint = 0
+	ix.Entries = nil
+}
+
+// Save writes the index atomically, so that an interrupted scan or a crash
+// never leaves a partial index behind
+func (ix *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0755); err != nil {
+		return xerrors.Errorf("failed to create chain index directory: %w", err)
+	}
+
+	b, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+
+	if err := util.WriteFileAtomic(ix.path, b, 0644); err != nil {
+		return xerrors.Errorf("failed to write chain index: %w", err)
+	}
+	return nil
+}
+
+// QueryEvents calls fn with the indexed events that match the query, in
+// chronological order
+func (ix *Index) QueryEvents(q journal.Query, fn func(journal.Event) error) error {
+	visit, flush := journal.Collect(q, fn)
+	for _, e := range ix.Entries {
+		if err := visit(e.Event); err != nil {
+			if errors.Is(err, journal.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	if err := flush(); err != nil && !errors.Is(err, journal.ErrStop) {
+		return err
+	}
+	return nil
+}
+
+// truncate drops the entries past the checkpoint, which the next scan fetches
+// again
+func (ix *Index) truncate() {
+	n := 0
+	for _, e := range ix.Entries {
+		if e.Block <= ix.Checkpoint {
+			ix.Entries[n] = e
+			n++
+		}
+	}
+	ix.Entries = ix.Entries[:n]
+}
@@ -0,0 +1,39 @@
+package chainindex
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glifio/glif/v2/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entry(block uint64) Entry {
+	return Entry{Block: block, Event: journal.Event{EventType: journal.EventType{System: "agent", Event: "pay"}}}
+}
+
+func TestIndexResumesFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	ix, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), ix.Next(10))
+
+	// the second entry is past the final block, it is scanned again
+	ix.Add([]Entry{entry(20), entry(95)}, 100, 90)
+	require.NoError(t, ix.Save())
+	assert.Equal(t, uint64(91), ix.Next(10))
+
+	ix, err = Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(90), ix.Checkpoint)
+	assert.Equal(t, []Entry{entry(20)}, ix.Entries)
+
+	var n int
+	require.NoError(t, ix.QueryEvents(journal.Query{Event: "pay"}, func(journal.Event) error {
+		n++
+		return nil
+	}))
+	assert.Equal(t, 1, n)
+}
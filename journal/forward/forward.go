@@ -0,0 +1,131 @@
+// Package forward implements a journal that forwards the events it records to
+// external sinks, such as syslog or an HTTP endpoint, on top of a primary
+// journal that stores them.
+package forward
+
+import (
+	"log"
+	"sync"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+// queueSize is the number of events buffered for each sink. Events recorded
+// while the buffer of a sink is full are dropped for that sink.
+const queueSize = 256
+
+// Sink receives a copy of every event recorded to the journal
+type Sink interface {
+	Name() string
+	Write(evt journal.Event) error
+	Close() error
+}
+
+// Forwarder forwards the events written by a primary journal to sinks, as the
+// journal wrote them. Each sink is fed from its own goroutine so that a slow or
+// unreachable sink does not block the others, nor the primary journal.
+type Forwarder struct {
+	queues []*sinkQueue
+}
+
+func NewForwarder(sinks ...Sink) *Forwarder {
+	f := &Forwarder{}
+	for _, s := range sinks {
+		q := &sinkQueue{
+			sink:     s,
+			incoming: make(chan journal.Event, queueSize),
+			closed:   make(chan struct{}),
+		}
+		go q.run()
+		f.queues = append(f.queues, q)
+	}
+	return f
+}
+
+// Forward queues an event written by the primary journal for every sink. It is
+// meant to be the hook the primary journal calls once an event is written,
+// e.g. fsjournal.Options.Written.
+func (f *Forwarder) Forward(evt journal.Event) {
+	for _, q := range f.queues {
+		q.push(evt)
+	}
+}
+
+// Close waits for the sinks to write out the events that are still queued
+func (f *Forwarder) Close() {
+	for _, q := range f.queues {
+		q.close()
+	}
+}
+
+// fanoutJournal is a primary journal whose written events are forwarded to
+// sinks
+type fanoutJournal struct {
+	journal.Journal
+
+	fwd *Forwarder
+}
+
+// NewJournal wraps the primary journal, which passes the events it writes to
+// fwd.Forward, so that closing the journal also closes the forwarder
+func NewJournal(primary journal.Journal, fwd *Forwarder) journal.Journal {
+	if len(fwd.queues) == 0 {
+		return primary
+	}
+	return &fanoutJournal{Journal: primary, fwd: fwd}
+}
+
+// Close closes the primary journal, then waits for the sinks to write out the
+// events that are still queued
+func (f *fanoutJournal) Close() error {
+	err := f.Journal.Close()
+	f.fwd.Close()
+	return err
+}
+
+type sinkQueue struct {
+	sink     Sink
+	incoming chan journal.Event
+	closed   chan struct{}
+
+	lk      sync.Mutex
+	done    bool
+	dropped int
+}
+
+func (q *sinkQueue) push(evt journal.Event) {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	if q.done {
+		return
+	}
+
+	select {
+	case q.incoming <- evt:
+	default:
+		q.dropped++
+		log.Printf("journal sink %s is full, dropped %d events", q.sink.Name(), q.dropped)
+	}
+}
+
+func (q *sinkQueue) run() {
+	defer close(q.closed)
+	for evt := range q.incoming {
+		if err := q.sink.Write(evt); err != nil {
+			log.Printf("failed to forward journal event %s to %s sink: %s", evt.EventType, q.sink.Name(), err)
+		}
+	}
+	if err := q.sink.Close(); err != nil {
+		log.Printf("failed to close %s journal sink: %s", q.sink.Name(), err)
+	}
+}
+
+func (q *sinkQueue) close() {
+	q.lk.Lock()
+	if !q.done {
+		q.done = true
+		close(q.incoming)
+	}
+	q.lk.Unlock()
+	<-q.closed
+}
@@ -0,0 +1,151 @@
+package forward
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/journal/fsjournal"
+)
+
+func TestFanoutWritesToSinks(t *testing.T) {
+	var buf bytes.Buffer
+	fwd := NewForwarder(NewWriterSink("stdout", &buf))
+	opts := fsjournal.DefaultOptions()
+	opts.Written = fwd.Forward
+	dir := t.TempDir()
+	primary, err := fsjournal.OpenFSJournalWithOptions(dir, nil, opts)
+	require.NoError(t, err)
+	j := NewJournal(primary, fwd)
+
+	pay := j.RegisterEventType("agent", "pay")
+	j.RecordEvent(pay, func() interface{} { return map[string]string{"amount": "1"} })
+	j.RecordEvent(pay, func() interface{} { return map[string]string{"amount": "2"} })
+	require.NoError(t, j.Close())
+
+	// the sink gets the entries as the primary journal wrote them, with their
+	// timestamp and position in the hash chain
+	written, err := os.ReadFile(filepath.Join(dir, "journal", "glif-journal.ndjson"))
+	require.NoError(t, err)
+	require.Equal(t, string(written), buf.String())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"System":"agent","Event":"pay"`)
+	require.Contains(t, lines[1], `"amount":"2"`)
+	require.Contains(t, lines[1], `"seq":2`)
+	require.Contains(t, lines[1], `"prev_hash":"`)
+}
+
+func TestSyslogSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		size, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			return
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return
+		}
+		received <- string(b)
+	}()
+
+	s, err := NewSyslogSink("tcp", ln.Addr().String(), "local0", "glif")
+	require.NoError(t, err)
+	defer s.Close()
+
+	evt := journal.Event{
+		EventType: journal.EventType{System: "agent", Event: "pay"},
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Data:      map[string]string{"error": "reverted"},
+	}
+	require.NoError(t, s.Write(evt))
+
+	msg := <-received
+	// local0 (16) * 8 + error severity (3)
+	require.True(t, strings.HasPrefix(msg, "<131>1 2024-01-01T00:00:00.000000Z "), msg)
+	require.Contains(t, msg, " glif ")
+	require.Contains(t, msg, " agent:pay - {")
+}
+
+func TestHTTPSinkSpoolsUntilDelivered(t *testing.T) {
+	var lk sync.Mutex
+	var bodies []string
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		defer lk.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		require.Equal(t, "secret", r.Header.Get("Authorization"))
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+	}))
+	defer srv.Close()
+
+	opts := HTTPSinkOptions{
+		URL:           srv.URL,
+		Headers:       map[string]string{"Authorization": "secret"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		SpoolDir:      t.TempDir(),
+	}
+
+	// the endpoint is down, events stay in the spool
+	s, err := NewHTTPSink(opts)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Write(journal.Event{EventType: journal.EventType{System: "agent", Event: "pay"}}))
+	}
+	require.Error(t, s.Close())
+
+	// a later process delivers the spooled events in batches
+	lk.Lock()
+	fail = false
+	lk.Unlock()
+
+	s, err = NewHTTPSink(opts)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	lk.Lock()
+	defer lk.Unlock()
+	require.Len(t, bodies, 2)
+	require.Equal(t, 2, strings.Count(bodies[0], "\n"))
+	require.Equal(t, 1, strings.Count(bodies[1], "\n"))
+
+	lines, err := readLines(s.spool, 10)
+	require.NoError(t, err)
+	require.Empty(t, lines)
+}
@@ -0,0 +1,307 @@
+package forward
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glifio/glif/v2/journal"
+	"github.com/glifio/glif/v2/util"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 10 * time.Second
+	defaultMaxRetries    = 5
+	maxBackoff           = 30 * time.Second
+	httpTimeout          = 30 * time.Second
+)
+
+// HTTPSinkOptions configure an HTTPSink
+type HTTPSinkOptions struct {
+	URL string
+	// Headers are added to every request, e.g. for authentication
+	Headers map[string]string
+	// BatchSize is the maximum number of events sent in a single request
+	BatchSize int
+	// FlushInterval is how often spooled events are sent
+	FlushInterval time.Duration
+	// MaxRetries is the number of times a failed request is retried, with an
+	// exponential backoff, before the events are left in the spool until the
+	// next flush
+	MaxRetries int
+	// SpoolDir holds the events that were not delivered yet
+	SpoolDir string
+}
+
+// HTTPSink POSTs events in batches of NDJSON to an HTTP endpoint.
+//
+// Events are first appended to an on-disk spool, and only removed from it once
+// the endpoint accepted them. Events that could not be delivered, because the
+// endpoint was unreachable or the process exited, are sent by a later flush,
+// possibly of another glif process that shares the spool.
+type HTTPSink struct {
+	opts   HTTPSinkOptions
+	client *http.Client
+
+	spool     string
+	spoolLock string
+	flushLock string
+
+	pending int
+	trigger chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func NewHTTPSink(opts HTTPSinkOptions) (*HTTPSink, error) {
+	if opts.URL == "" {
+		return nil, errors.New("http journal sink requires a URL")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if err := os.MkdirAll(opts.SpoolDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal spool directory: %w", err)
+	}
+
+	// endpoints get their own spool, so that changing the URL does not send
+	// events meant for another endpoint
+	sum := sha256.Sum256([]byte(opts.URL))
+	spool := filepath.Join(opts.SpoolDir, "http-"+hex.EncodeToString(sum[:4])+".ndjson")
+
+	s := &HTTPSink{
+		opts:      opts,
+		client:    &http.Client{Timeout: httpTimeout},
+		spool:     spool,
+		spoolLock: spool + ".lock",
+		flushLock: spool + ".flush.lock",
+		trigger:   make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *HTTPSink) Name() string { return "http" }
+
+// Write appends the event to the spool, and triggers a flush once a full batch
+// is spooled
+func (s *HTTPSink) Write(evt journal.Event) error {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	err = s.withSpoolLock(func() error {
+		fi, err := os.OpenFile(s.spool, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := fi.Write(append(b, '\n')); err != nil {
+			_ = fi.Close()
+			return err
+		}
+		return fi.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to spool event: %w", err)
+	}
+
+	s.pending++
+	if s.pending >= s.opts.BatchSize {
+		s.pending = 0
+		select {
+		case s.trigger <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close stops the background flushes and makes a last attempt to send the
+// spooled events, without retries
+func (s *HTTPSink) Close() error {
+	close(s.stop)
+	<-s.stopped
+	return s.flush(0)
+}
+
+func (s *HTTPSink) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.trigger:
+		case <-s.stop:
+			return
+		}
+		if err := s.flush(s.opts.MaxRetries); err != nil {
+			log.Printf("failed to send journal events to %s: %s", s.opts.URL, err)
+		}
+	}
+}
+
+// flush sends the spooled events in batches. Only one process flushes a spool
+// at a time, a flush is skipped when another process is already flushing.
+func (s *HTTPSink) flush(retries int) error {
+	l, err := util.TryLockFile(s.flushLock)
+	if errors.Is(err, util.ErrLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	for {
+		var batch [][]byte
+		err := s.withSpoolLock(func() error {
+			var err error
+			batch, err = readLines(s.spool, s.opts.BatchSize)
+			return err
+		})
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+
+		if err := s.post(batch, retries); err != nil {
+			return err
+		}
+
+		err = s.withSpoolLock(func() error {
+			return dropLines(s.spool, len(batch))
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// post sends a batch of NDJSON lines, retrying with an exponential backoff
+func (s *HTTPSink) post(batch [][]byte, retries int) error {
+	body := append(bytes.Join(batch, []byte("\n")), '\n')
+
+	backoff := time.Second
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = s.postOnce(body); err == nil {
+			return nil
+		}
+		if attempt >= retries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.stop:
+			return err
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (s *HTTPSink) postOnce(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// withSpoolLock runs fn while holding the lock on the spool, which is shared
+// by all glif processes that spool to it
+func (s *HTTPSink) withSpoolLock(fn func() error) error {
+	l, err := util.LockFile(s.spoolLock)
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	return fn()
+}
+
+// readLines returns the first n lines of a file
+func readLines(path string, n int) ([][]byte, error) {
+	fi, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer fi.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(fi)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for len(lines) < n && scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	return lines, scanner.Err()
+}
+
+// dropLines removes the first n non empty lines of a file
+func dropLines(path string, n int) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for n > 0 && len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			i = len(b) - 1
+		}
+		if len(bytes.TrimSpace(b[:i+1])) > 0 {
+			n--
+		}
+		b = b[i+1:]
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
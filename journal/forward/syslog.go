@@ -0,0 +1,194 @@
+package forward
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+// rfc5424Time is the timestamp format of RFC 5424, which allows at most six
+// fractional digits
+const rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+
+const dialTimeout = 10 * time.Second
+
+// syslog severities of RFC 5424
+const (
+	severityError = 3
+	severityInfo  = 6
+)
+
+var facilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// SyslogSink sends every event as an RFC 5424 syslog message. Events that
+// recorded an error are sent with the error severity, all others with the
+// informational severity.
+//
+// Messages are sent one per datagram over udp and unixgram, and framed with
+// octet counting (RFC 6587) over tcp and unix stream sockets.
+type SyslogSink struct {
+	network  string
+	address  string
+	facility int
+	appName  string
+	hostname string
+	pid      int
+
+	lk   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a sink that sends messages to the syslog server
+// listening on address. The network is one of udp, tcp, unix or unixgram, and
+// facility a syslog facility name such as local0.
+func NewSyslogSink(network, address, facility, appName string) (*SyslogSink, error) {
+	switch network {
+	case "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("invalid syslog network %q, must be one of udp, tcp, unix or unixgram", network)
+	}
+
+	if facility == "" {
+		facility = "local0"
+	}
+	code, ok := facilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("invalid syslog facility %q", facility)
+	}
+
+	if appName == "" {
+		appName = "glif"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogSink{
+		network:  network,
+		address:  address,
+		facility: code,
+		appName:  headerField(appName, 48),
+		hostname: headerField(hostname, 255),
+		pid:      os.Getpid(),
+	}, nil
+}
+
+func (s *SyslogSink) Name() string { return "syslog" }
+
+func (s *SyslogSink) Write(evt journal.Event) error {
+	msg, err := s.format(evt)
+	if err != nil {
+		return err
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	// reconnect once, the server may have closed an idle connection
+	if err := s.send(msg); err != nil {
+		s.closeConn()
+		return s.send(msg)
+	}
+	return nil
+}
+
+func (s *SyslogSink) Close() error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.closeConn()
+}
+
+func (s *SyslogSink) format(evt journal.Event) ([]byte, error) {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	severity := severityInfo
+	if journal.EventError(evt) != "" {
+		severity = severityError
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		s.facility*8+severity,
+		evt.Timestamp.Format(rfc5424Time),
+		s.hostname,
+		s.appName,
+		s.pid,
+		headerField(evt.EventType.String(), 32),
+	)
+	return append([]byte(header), body...), nil
+}
+
+func (s *SyslogSink) send(msg []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	if s.network == "tcp" || s.network == "unix" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+func (s *SyslogSink) closeConn() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// headerField makes s a valid RFC 5424 header field: printable ASCII without
+// spaces, of at most max characters, or the nil value "-" when empty
+func headerField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
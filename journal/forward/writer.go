@@ -0,0 +1,37 @@
+package forward
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+// WriterSink writes every event as a line of JSON to a writer, such as stdout
+type WriterSink struct {
+	name string
+
+	lk sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w}
+}
+
+func (s *WriterSink) Name() string { return s.name }
+
+func (s *WriterSink) Write(evt journal.Event) error {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+func (s *WriterSink) Close() error { return nil }
@@ -0,0 +1,251 @@
+package fsjournal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+// hashEntry returns the hash that the next entry records as its PrevHash
+func hashEntry(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// chainHead returns the sequence number and hash of the last entry written to
+// the journal in dir, following the chain across rolled files. Both are zero
+// for an empty journal.
+func chainHead(dir string) (uint64, string, error) {
+	segs, err := segments(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, "", nil
+		}
+		return 0, "", err
+	}
+
+	for i := len(segs) - 1; i >= 0; i-- {
+		var line []byte
+		if segs[i].compressed {
+			line, err = lastCompressedLine(segs[i])
+		} else {
+			line, err = lastLine(segs[i].path)
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		if line == nil {
+			continue
+		}
+
+		var evt journal.Event
+		if err := json.Unmarshal(line, &evt); err != nil {
+			return 0, "", fmt.Errorf("%s: last entry is corrupt: %w", segs[i].path, err)
+		}
+		return evt.Seq, hashEntry(line), nil
+	}
+	return 0, "", nil
+}
+
+// lastLine returns the last non empty line of a file, or nil if the file is
+// empty. The file is read backwards so that large files are not read whole.
+func lastLine(path string) ([]byte, error) {
+	fi, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+
+	st, err := fi.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const chunk = 64 * 1024
+	var buf []byte
+	end := st.Size()
+	for off := end; off > 0; {
+		n := int64(chunk)
+		if off < n {
+			n = off
+		}
+		off -= n
+
+		b := make([]byte, n)
+		if _, err := fi.ReadAt(b, off); err != nil && err != io.EOF {
+			return nil, err
+		}
+		buf = append(b, buf...)
+
+		trimmed := bytes.TrimRight(buf, " \r\n\t")
+		if len(trimmed) == 0 {
+			continue
+		}
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+		if off == 0 {
+			return trimmed, nil
+		}
+		if int64(len(buf)) > maxLineSize {
+			return nil, fmt.Errorf("%s: last entry exceeds %d bytes", path, maxLineSize)
+		}
+	}
+	return nil, nil
+}
+
+// lastCompressedLine returns the last non empty line of a compressed file.
+// Compressed files cannot be read backwards, so the whole file is read.
+func lastCompressedLine(seg segment) ([]byte, error) {
+	r := newReader([]segment{seg})
+	defer r.Close()
+
+	var last []byte
+	for {
+		line, err := r.nextLine()
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		last = append(last[:0], line...)
+	}
+}
+
+// Problem kinds reported by Verify
+const (
+	ProblemCorrupt    = "corrupt"
+	ProblemBrokenLink = "broken-link"
+	ProblemMissing    = "missing"
+	ProblemReordered  = "reordered"
+	ProblemUnchained  = "unchained"
+)
+
+// Problem is a break in the hash chain of a journal
+type Problem struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Seq     uint64 `json:"seq,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, p.Kind, p.Message)
+}
+
+// VerifyReport is the result of verifying the hash chain of a journal
+type VerifyReport struct {
+	Files   int `json:"files"`
+	Entries int `json:"entries"`
+	// Unchained counts the entries written before the journal was chained
+	Unchained int       `json:"unchained"`
+	FirstSeq  uint64    `json:"first_seq"`
+	LastSeq   uint64    `json:"last_seq"`
+	HeadHash  string    `json:"head_hash"`
+	Problems  []Problem `json:"problems"`
+}
+
+// OK returns whether the chain is intact
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify walks every file of the journal stored in journalPath in
+// chronological order and checks that entries are numbered consecutively and
+// that each one records the hash of the entry before it. Entries written
+// before the journal was chained are only accepted at its start.
+//
+// The first and last entries can only be checked against a range recorded
+// elsewhere, see VerifyReport.FirstSeq, VerifyReport.LastSeq and
+// VerifyReport.HeadHash.
+func Verify(journalPath string) (*VerifyReport, error) {
+	report := &VerifyReport{Problems: []Problem{}}
+
+	segs, err := segments(filepath.Join(journalPath, "journal"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return report, nil
+		}
+		return nil, err
+	}
+	report.Files = len(segs)
+
+	r := newReader(segs)
+	defer r.Close()
+
+	var (
+		expected uint64
+		prevHash string
+	)
+	for {
+		line, err := r.nextLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Entries++
+
+		file, lineNo := filepath.Base(r.segments[r.idx].path), r.line
+		problem := func(kind string, seq uint64, format string, args ...interface{}) {
+			report.Problems = append(report.Problems, Problem{
+				Kind:    kind,
+				File:    file,
+				Line:    lineNo,
+				Seq:     seq,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		var evt journal.Event
+		if err := json.Unmarshal(line, &evt); err != nil {
+			problem(ProblemCorrupt, 0, "entry cannot be decoded: %s", err)
+			prevHash = hashEntry(line)
+			continue
+		}
+
+		switch {
+		case evt.Seq == 0 && expected == 0:
+			report.Unchained++
+		case evt.Seq == 0:
+			problem(ProblemUnchained, 0, "entry without a sequence number after entry %d", expected-1)
+		case expected == 0:
+			// the first chained entry links to the unchained entries before
+			// it, otherwise older files may have been removed by retention
+			report.FirstSeq = evt.Seq
+			if report.Unchained > 0 && (evt.Seq != 1 || evt.PrevHash != prevHash) {
+				problem(ProblemBrokenLink, evt.Seq, "first chained entry does not link to the entry before it")
+			}
+			expected = evt.Seq + 1
+		case evt.Seq == expected:
+			if evt.PrevHash != prevHash {
+				problem(ProblemBrokenLink, evt.Seq, "previous entry hash %s does not match %s, entry %d was altered", evt.PrevHash, prevHash, evt.Seq-1)
+			}
+			expected++
+		case evt.Seq > expected:
+			problem(ProblemMissing, evt.Seq, "entries %d to %d are missing", expected, evt.Seq-1)
+			expected = evt.Seq + 1
+		default:
+			problem(ProblemReordered, evt.Seq, "entry %d found after entry %d", evt.Seq, expected-1)
+		}
+
+		if evt.Seq > report.LastSeq {
+			report.LastSeq = evt.Seq
+		}
+		prevHash = hashEntry(line)
+	}
+
+	report.HeadHash = prevHash
+	return report, nil
+}
@@ -0,0 +1,139 @@
+package fsjournal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	clk "github.com/raulk/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+// writeChainedJournal records n events, rolling the journal file every few
+// entries, and returns the journal files in chronological order. Every event
+// is recorded a minute after the previous one.
+func writeChainedJournal(t *testing.T, root string, n int) []segment {
+	return writeJournal(t, root, n, Options{MaxSize: 300})
+}
+
+func writeJournal(t *testing.T, root string, n int, opts Options) []segment {
+	mock := clk.NewMock()
+	mock.Set(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock = mock
+	t.Cleanup(func() { clock = clk.New() })
+
+	j, err := OpenFSJournalWithOptions(root, nil, opts)
+	require.NoError(t, err)
+
+	evtType := j.RegisterEventType("agent", "pay")
+	for i := 0; i < n; i++ {
+		mock.Add(time.Minute)
+		j.RecordEvent(evtType, func() interface{} {
+			return map[string]interface{}{"amount": "1000"}
+		})
+	}
+	require.NoError(t, j.Close())
+
+	segs, err := segments(filepath.Join(root, "journal"))
+	require.NoError(t, err)
+	return segs
+}
+
+func TestVerifyChainAcrossRolledFiles(t *testing.T) {
+	root := t.TempDir()
+	segs := writeChainedJournal(t, root, 10)
+	require.Greater(t, len(segs), 2)
+
+	report, err := Verify(root)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, 10, report.Entries)
+	require.Equal(t, uint64(1), report.FirstSeq)
+	require.Equal(t, uint64(10), report.LastSeq)
+
+	// reopening the journal continues the chain
+	j, err := OpenFSJournal(root, nil)
+	require.NoError(t, err)
+	j.RecordEvent(j.RegisterEventType("agent", "borrow"), func() interface{} { return nil })
+	require.NoError(t, j.Close())
+
+	report, err = Verify(root)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, uint64(11), report.LastSeq)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tamper := func(t *testing.T, seg int, edit func(lines [][]byte) [][]byte) *VerifyReport {
+		root := t.TempDir()
+		segs := writeChainedJournal(t, root, 10)
+
+		path := segs[seg].path
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+		require.Greater(t, len(lines), 1)
+		lines = edit(lines)
+		require.NoError(t, os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0644))
+
+		report, err := Verify(root)
+		require.NoError(t, err)
+		require.NotEmpty(t, report.Problems)
+		return report
+	}
+
+	report := tamper(t, 0, func(lines [][]byte) [][]byte {
+		lines[0] = bytes.Replace(lines[0], []byte("1000"), []byte("9000"), 1)
+		return lines
+	})
+	require.Equal(t, ProblemBrokenLink, report.Problems[0].Kind)
+	require.Equal(t, uint64(2), report.Problems[0].Seq)
+
+	// the chain continues across rolled files
+	report = tamper(t, 1, func(lines [][]byte) [][]byte {
+		return lines[1:]
+	})
+	require.Equal(t, ProblemMissing, report.Problems[0].Kind)
+
+	report = tamper(t, 0, func(lines [][]byte) [][]byte {
+		return append(lines[:1], lines[2:]...)
+	})
+	require.Equal(t, ProblemMissing, report.Problems[0].Kind)
+
+	report = tamper(t, 0, func(lines [][]byte) [][]byte {
+		lines[0], lines[1] = lines[1], lines[0]
+		return lines
+	})
+	require.Equal(t, ProblemReordered, report.Problems[0].Kind)
+}
+
+func TestChainContinuesUnchainedJournal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "journal")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	writeSegment(t, filepath.Join(dir, currentFile),
+		testEvent("borrow", time.Now(), map[string]interface{}{"tx": "0x01"}),
+	)
+
+	j, err := OpenFSJournal(root, nil)
+	require.NoError(t, err)
+	j.RecordEvent(j.RegisterEventType("agent", "pay"), func() interface{} { return nil })
+	require.NoError(t, j.Close())
+
+	report, err := Verify(root)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, 1, report.Unchained)
+	require.Equal(t, uint64(1), report.LastSeq)
+
+	var evts []journal.Event
+	require.NoError(t, QueryEvents(root, journal.Query{}, func(e journal.Event) error {
+		evts = append(evts, e)
+		return nil
+	}))
+	require.Len(t, evts, 2)
+}
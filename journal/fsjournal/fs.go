@@ -2,11 +2,14 @@ package fsjournal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	clk "github.com/raulk/clock"
 	"golang.org/x/xerrors"
@@ -22,21 +25,36 @@ const RFC3339nocolon = "2006-01-02T150405Z0700"
 type fsJournal struct {
 	journal.EventTypeRegistry
 
-	dir       string
-	sizeLimit int64
+	dir  string
+	opts Options
 
 	fi    *os.File
 	fSize int64
+	// fStart is the time of the first entry in the current file
+	fStart time.Time
+
+	// lock serializes writes of all processes that share the journal, and
+	// seq and prevHash are the head of the hash chain
+	lock     *os.File
+	seq      uint64
+	prevHash string
 
 	incoming chan *journal.Event
 
-	closing chan struct{}
-	closed  chan struct{}
+	closing   chan struct{}
+	closeOnce sync.Once
+	closed    chan struct{}
 }
 
 // OpenFSJournal constructs a rolling filesystem journal, with a default
 // per-file size limit of 1GiB.
 func OpenFSJournal(journalPath string, disabled journal.DisabledEvents) (journal.Journal, error) {
+	return OpenFSJournalWithOptions(journalPath, disabled, DefaultOptions())
+}
+
+// OpenFSJournalWithOptions constructs a rolling filesystem journal that rolls,
+// compresses and removes files according to opts.
+func OpenFSJournalWithOptions(journalPath string, disabled journal.DisabledEvents, opts Options) (journal.Journal, error) {
 	dir := filepath.Join(journalPath, "journal")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to mk directory %s for file journal: %w", dir, err)
@@ -45,7 +63,7 @@ func OpenFSJournal(journalPath string, disabled journal.DisabledEvents) (journal
 	f := &fsJournal{
 		EventTypeRegistry: journal.NewEventTypeRegistry(disabled),
 		dir:               dir,
-		sizeLimit:         1 << 30,
+		opts:              opts,
 		incoming:          make(chan *journal.Event, 32),
 		closing:           make(chan struct{}),
 		closed:            make(chan struct{}),
@@ -53,7 +71,8 @@ func OpenFSJournal(journalPath string, disabled journal.DisabledEvents) (journal
 
 	var nfi *os.File
 	var nfSize int64
-	current := filepath.Join(f.dir, "glif-journal.ndjson")
+	var err error
+	current := filepath.Join(f.dir, currentFile)
 	if fi, err := os.Stat(current); err == nil && !fi.IsDir() {
 		nfi, err = os.OpenFile(current, os.O_APPEND|os.O_RDWR, 0644)
 		if err != nil {
@@ -69,12 +88,28 @@ func OpenFSJournal(journalPath string, disabled journal.DisabledEvents) (journal
 	f.fi = nfi
 	f.fSize = nfSize
 
+	if f.lock, err = os.OpenFile(filepath.Join(f.dir, lockFile), os.O_CREATE|os.O_RDWR, 0644); err != nil {
+		return nil, xerrors.Errorf("failed to open journal lock file: %w", err)
+	}
+	if f.seq, f.prevHash, err = chainHead(f.dir); err != nil {
+		return nil, xerrors.Errorf("failed to read journal head: %w", err)
+	}
+	if f.fStart, err = firstEntryTime(current); err != nil {
+		return nil, xerrors.Errorf("failed to read journal file: %w", err)
+	}
+
 	go f.runLoop()
 
 	return f, nil
 }
 
 func (f *fsJournal) RecordEvent(evtType journal.EventType, supplier func() interface{}) {
+	f.RecordEventAt(evtType, clock.Now(), supplier)
+}
+
+// RecordEventAt records an event with the time it happened at. The entry is
+// still appended to the end of the journal.
+func (f *fsJournal) RecordEventAt(evtType journal.EventType, t time.Time, supplier func() interface{}) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("recovered from panic while recording journal event; type=%s, err=%v", evtType, r)
@@ -87,7 +122,7 @@ func (f *fsJournal) RecordEvent(evtType journal.EventType, supplier func() inter
 
 	je := &journal.Event{
 		EventType: evtType,
-		Timestamp: clock.Now(),
+		Timestamp: t,
 		Data:      supplier(),
 	}
 	select {
@@ -99,35 +134,59 @@ func (f *fsJournal) RecordEvent(evtType journal.EventType, supplier func() inter
 
 func (f *fsJournal) ReadEvents() ([]journal.Event, error) {
 	evts := []journal.Event{}
-	b := make([]byte, f.fSize)
-
-	_, err := f.fi.Read(b)
+	err := f.QueryEvents(journal.Query{}, func(evt journal.Event) error {
+		evts = append(evts, evt)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return evts, nil
+}
 
-	bstrs := strings.Split(string(b), "\n")
-	for _, bs := range bstrs {
-		evt := &journal.Event{}
-		if bs == "" {
-			continue
-		}
-		err := json.Unmarshal([]byte(bs), evt)
-		if err != nil {
-			return nil, err
-		}
-		evts = append(evts, *evt)
+func (f *fsJournal) QueryEvents(q journal.Query, fn func(journal.Event) error) error {
+	segs, err := segments(f.dir)
+	if err != nil {
+		return err
 	}
-	return evts, nil
+	r := newReader(segs)
+	defer r.Close()
+
+	return queryReader(r, q, fn)
 }
 
+// Close writes out the recorded events and closes the journal, closing an
+// already closed journal is a no-op
 func (f *fsJournal) Close() error {
-	close(f.closing)
+	f.closeOnce.Do(func() {
+		close(f.closing)
+	})
 	<-f.closed
 	return nil
 }
 
 func (f *fsJournal) putEvent(evt *journal.Event) error {
+	if err := syscall.Flock(int(f.lock.Fd()), syscall.LOCK_EX); err != nil {
+		return xerrors.Errorf("failed to lock journal: %w", err)
+	}
+	defer func() {
+		_ = syscall.Flock(int(f.lock.Fd()), syscall.LOCK_UN)
+	}()
+
+	if err := f.syncHead(); err != nil {
+		return err
+	}
+
+	now := clock.Now()
+	if f.shouldRoll(now) {
+		if err := f.rollJournalFile(); err != nil {
+			return err
+		}
+	}
+
+	evt.Seq = f.seq + 1
+	evt.PrevHash = f.prevHash
+
 	b, err := json.Marshal(evt)
 	if err != nil {
 		return err
@@ -137,24 +196,69 @@ func (f *fsJournal) putEvent(evt *journal.Event) error {
 		return err
 	}
 
+	if f.fSize == 0 {
+		f.fStart = now
+	}
+	f.seq = evt.Seq
+	f.prevHash = hashEntry(b)
 	f.fSize += int64(n)
 
-	if f.fSize >= f.sizeLimit {
-		_ = f.rollJournalFile()
+	if f.shouldRoll(now) {
+		if err := f.rollJournalFile(); err != nil {
+			log.Println(err)
+		}
 	}
 
 	return nil
 }
 
+// syncHead catches up with the entries written, and the files rolled, by other
+// processes since this journal last wrote to the current file. It must be
+// called with the journal locked.
+func (f *fsJournal) syncHead() error {
+	current := filepath.Join(f.dir, currentFile)
+	st, err := os.Stat(current)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	ourSt, err := f.fi.Stat()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case st == nil || !os.SameFile(st, ourSt):
+		// the file was rolled by another process
+		_ = f.fi.Close()
+		nfi, err := os.OpenFile(current, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return xerrors.Errorf("failed to open journal file: %w", err)
+		}
+		nst, err := nfi.Stat()
+		if err != nil {
+			return err
+		}
+		f.fi = nfi
+		f.fSize = nst.Size()
+	case st.Size() != f.fSize:
+		f.fSize = st.Size()
+	default:
+		return nil
+	}
+
+	if f.seq, f.prevHash, err = chainHead(f.dir); err != nil {
+		return err
+	}
+	f.fStart, err = firstEntryTime(current)
+	return err
+}
+
 func (f *fsJournal) rollJournalFile() error {
 	if f.fi != nil {
 		_ = f.fi.Close()
 	}
-	current := filepath.Join(f.dir, "glif-journal.ndjson")
-	rolled := filepath.Join(f.dir, fmt.Sprintf(
-		"glif-journal-%s.ndjson",
-		clock.Now().Format(RFC3339nocolon),
-	))
+	current := filepath.Join(f.dir, currentFile)
+	rolled := rolledName(f.dir, clock.Now())
 
 	// check if journal file exists
 	if fi, err := os.Stat(current); err == nil && !fi.IsDir() {
@@ -171,8 +275,36 @@ func (f *fsJournal) rollJournalFile() error {
 
 	f.fi = nfi
 	f.fSize = 0
+	f.fStart = time.Time{}
 
-	return nil
+	if f.opts.Compress {
+		if err := f.compressRolled(); err != nil {
+			return err
+		}
+	}
+	return f.applyRetention(clock.Now())
+}
+
+// rolledName returns the path of a file rolled at time t. Files are named
+// after the second they were rolled at, and must sort after the files rolled
+// before them, so a later second is used when a file was already rolled at or
+// after t.
+func rolledName(dir string, t time.Time) string {
+	t = t.Truncate(time.Second)
+	if segs, err := segments(dir); err == nil {
+		for _, seg := range segs {
+			if !seg.rolled.IsZero() && !seg.rolled.Before(t) {
+				t = seg.rolled.Add(time.Second)
+			}
+		}
+	}
+
+	return filepath.Join(dir, fmt.Sprintf(
+		"%s%s%s",
+		rolledPrefix,
+		t.Format(RFC3339nocolon),
+		rolledSuffix,
+	))
 }
 
 func (f *fsJournal) runLoop() {
@@ -181,12 +313,32 @@ func (f *fsJournal) runLoop() {
 	for {
 		select {
 		case je := <-f.incoming:
-			if err := f.putEvent(je); err != nil {
-				log.Print("failed to write out journal event", "event", je, "err", err)
-			}
+			f.writeEvent(je)
 		case <-f.closing:
+			// write out the events recorded before the journal was closed
+			for {
+				select {
+				case je := <-f.incoming:
+					f.writeEvent(je)
+					continue
+				default:
+				}
+				break
+			}
 			_ = f.fi.Close()
+			_ = f.lock.Close()
 			return
 		}
 	}
 }
+
+// writeEvent writes out an event, and hands it to the Written hook
+func (f *fsJournal) writeEvent(je *journal.Event) {
+	if err := f.putEvent(je); err != nil {
+		log.Print("failed to write out journal event", "event", je, "err", err)
+		return
+	}
+	if f.opts.Written != nil {
+		f.opts.Written(*je)
+	}
+}
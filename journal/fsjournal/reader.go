@@ -0,0 +1,242 @@
+package fsjournal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+const (
+	currentFile  = "glif-journal.ndjson"
+	rolledPrefix = "glif-journal-"
+	rolledSuffix = ".ndjson"
+	lockFile     = ".lock"
+
+	// compressedSuffix is appended to the name of compressed rolled files
+	compressedSuffix = ".gz"
+)
+
+// maxLineSize bounds the size of a single journal entry
+const maxLineSize = 16 << 20
+
+// segment is a single journal file on disk
+type segment struct {
+	path       string
+	rolled     time.Time
+	compressed bool
+}
+
+// segments lists the journal files in dir in chronological order: rolled files
+// by the time they were rolled, followed by the current file. When a rolled
+// file exists both compressed and uncompressed, because its compression was
+// interrupted, the uncompressed file is listed.
+func segments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rolled := map[string]segment{}
+	var current *segment
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		path := filepath.Join(dir, name)
+		if name == currentFile {
+			current = &segment{path: path}
+			continue
+		}
+
+		base, compressed := strings.CutSuffix(name, compressedSuffix)
+		if !strings.HasPrefix(base, rolledPrefix) || !strings.HasSuffix(base, rolledSuffix) {
+			continue
+		}
+		if _, ok := rolled[base]; ok && compressed {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimPrefix(base, rolledPrefix), rolledSuffix)
+		t, err := time.Parse(RFC3339nocolon, ts)
+		if err != nil {
+			// fall back to the modification time for files with unexpected names
+			fi, err := e.Info()
+			if err != nil {
+				return nil, err
+			}
+			t = fi.ModTime()
+		}
+		rolled[base] = segment{path: path, rolled: t, compressed: compressed}
+	}
+
+	segs := make([]segment, 0, len(rolled)+1)
+	for _, seg := range rolled {
+		segs = append(segs, seg)
+	}
+	sort.SliceStable(segs, func(i, j int) bool {
+		if segs[i].rolled.Equal(segs[j].rolled) {
+			return segs[i].path < segs[j].path
+		}
+		return segs[i].rolled.Before(segs[j].rolled)
+	})
+
+	if current != nil {
+		segs = append(segs, *current)
+	}
+	return segs, nil
+}
+
+// Reader streams the events of a journal directory, across rolled and
+// compressed files, in chronological order without loading whole files in
+// memory.
+type Reader struct {
+	segments []segment
+	idx      int
+
+	fi      *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader opens a reader over the journal stored in journalPath, the same
+// path that is passed to OpenFSJournal.
+func NewReader(journalPath string) (*Reader, error) {
+	segs, err := segments(filepath.Join(journalPath, "journal"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Reader{}, nil
+		}
+		return nil, err
+	}
+	return newReader(segs), nil
+}
+
+func newReader(segs []segment) *Reader {
+	return &Reader{segments: segs}
+}
+
+// Next returns the next event, or io.EOF once all files were read.
+func (r *Reader) Next() (journal.Event, error) {
+	b, err := r.nextLine()
+	if err != nil {
+		return journal.Event{}, err
+	}
+
+	var evt journal.Event
+	if err := json.Unmarshal(b, &evt); err != nil {
+		return journal.Event{}, fmt.Errorf("%s:%d: %w", r.segments[r.idx].path, r.line, err)
+	}
+	return evt, nil
+}
+
+// nextLine returns the next non empty line as it was written, or io.EOF once
+// all files were read. The returned slice is only valid until the next call.
+func (r *Reader) nextLine() ([]byte, error) {
+	for {
+		if r.scanner == nil {
+			if r.idx >= len(r.segments) {
+				return nil, io.EOF
+			}
+			if err := r.open(r.segments[r.idx]); err != nil {
+				return nil, err
+			}
+		}
+
+		if r.scanner.Scan() {
+			r.line++
+			b := r.scanner.Bytes()
+			if len(bytes.TrimSpace(b)) == 0 {
+				continue
+			}
+			return b, nil
+		}
+		if err := r.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.segments[r.idx].path, err)
+		}
+
+		_ = r.fi.Close()
+		r.fi, r.scanner = nil, nil
+		r.idx++
+	}
+}
+
+func (r *Reader) open(seg segment) error {
+	fi, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+
+	var src io.Reader = fi
+	if seg.compressed {
+		zr, err := gzip.NewReader(fi)
+		if err != nil {
+			_ = fi.Close()
+			return fmt.Errorf("%s: %w", seg.path, err)
+		}
+		src = zr
+	}
+
+	r.fi = fi
+	r.scanner = bufio.NewScanner(src)
+	r.scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	r.line = 0
+	return nil
+}
+
+// Close releases the file currently being read.
+func (r *Reader) Close() error {
+	if r.fi == nil {
+		return nil
+	}
+	err := r.fi.Close()
+	r.fi, r.scanner = nil, nil
+	return err
+}
+
+// QueryEvents streams the events of the journal stored in journalPath that
+// are selected by the query to fn, in chronological order.
+func QueryEvents(journalPath string, q journal.Query, fn func(journal.Event) error) error {
+	r, err := NewReader(journalPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return queryReader(r, q, fn)
+}
+
+func queryReader(r *Reader, q journal.Query, fn func(journal.Event) error) error {
+	visit, flush := journal.Collect(q, fn)
+	for {
+		evt, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := visit(evt); err != nil {
+			if errors.Is(err, journal.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	if err := flush(); err != nil && !errors.Is(err, journal.ErrStop) {
+		return err
+	}
+	return nil
+}
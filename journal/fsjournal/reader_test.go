@@ -0,0 +1,91 @@
+package fsjournal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+func writeSegment(t *testing.T, path string, evts ...journal.Event) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	for _, evt := range evts {
+		b, err := json.Marshal(evt)
+		require.NoError(t, err)
+		_, err = f.Write(append(b, '\n'))
+		require.NoError(t, err)
+	}
+}
+
+func testEvent(event string, ts time.Time, data map[string]interface{}) journal.Event {
+	return journal.Event{
+		EventType: journal.EventType{System: "agent", Event: event},
+		Timestamp: ts,
+		Data:      data,
+	}
+}
+
+func TestQueryEventsAcrossRolledFiles(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "journal")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// rolled files are named after the time they were rolled, the current
+	// file holds the most recent events
+	writeSegment(t, filepath.Join(dir, rolledPrefix+t0.Add(48*time.Hour).Format(RFC3339nocolon)+rolledSuffix),
+		testEvent("borrow", t0.Add(25*time.Hour), map[string]interface{}{"tx": "0x02"}),
+	)
+	writeSegment(t, filepath.Join(dir, rolledPrefix+t0.Add(24*time.Hour).Format(RFC3339nocolon)+rolledSuffix),
+		testEvent("borrow", t0, map[string]interface{}{"tx": "0x01"}),
+		testEvent("pay", t0.Add(time.Hour), map[string]interface{}{"tx": "0x01", "error": "reverted"}),
+	)
+	writeSegment(t, filepath.Join(dir, currentFile),
+		testEvent("pay", t0.Add(49*time.Hour), map[string]interface{}{"tx": "0x03"}),
+		testEvent("withdraw", t0.Add(50*time.Hour), map[string]interface{}{"tx": "0x04"}),
+	)
+
+	query := func(q journal.Query) []string {
+		var got []string
+		err := QueryEvents(root, q, func(e journal.Event) error {
+			got = append(got, e.Event)
+			return nil
+		})
+		require.NoError(t, err)
+		return got
+	}
+
+	require.Equal(t, []string{"borrow", "pay", "borrow", "pay", "withdraw"}, query(journal.Query{}))
+	require.Equal(t, []string{"pay", "pay"}, query(journal.Query{Event: "pay"}))
+	require.Equal(t, []string{"pay"}, query(journal.Query{ErrorsOnly: true}))
+	require.Equal(t, []string{"borrow", "pay"}, query(journal.Query{Tx: "0x01"}))
+	require.Equal(t, []string{"borrow", "pay"}, query(journal.Query{Since: t0.Add(2 * time.Hour), Until: t0.Add(49 * time.Hour)}))
+	require.Equal(t, []string{"pay", "withdraw"}, query(journal.Query{Limit: 2}))
+	require.Equal(t, []string{"borrow"}, query(journal.Query{System: "agent", Event: "borrow", Limit: 1, Since: t0.Add(time.Hour)}))
+
+	// stopping early is not an error
+	n := 0
+	err := QueryEvents(root, journal.Query{}, func(e journal.Event) error {
+		n++
+		return journal.ErrStop
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestQueryEventsMissingJournal(t *testing.T) {
+	err := QueryEvents(t.TempDir(), journal.Query{}, func(e journal.Event) error {
+		t.Fatal("unexpected event")
+		return nil
+	})
+	require.NoError(t, err)
+}
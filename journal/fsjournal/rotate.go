@@ -0,0 +1,172 @@
+package fsjournal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+// Options configure when a filesystem journal rolls its current file, and
+// which rolled files it keeps.
+type Options struct {
+	// MaxSize rolls the current file once it reaches this many bytes.
+	MaxSize int64
+	// MaxAge rolls the current file once its first entry is older than this,
+	// zero disables rolling by age.
+	MaxAge time.Duration
+	// Compress gzips rolled files.
+	Compress bool
+	// MaxFiles is the number of rolled files to keep, zero keeps all of them.
+	MaxFiles int
+	// MaxFileAge removes rolled files that were rolled longer ago than this,
+	// zero keeps all of them.
+	MaxFileAge time.Duration
+	// Written is called with every entry once it is written, as it was
+	// written, e.g. to forward it. It is called from the goroutine that
+	// writes the journal, and must not block.
+	Written func(journal.Event)
+}
+
+// DefaultOptions rolls the journal every 1GiB and keeps all rolled files,
+// compressed.
+func DefaultOptions() Options {
+	return Options{
+		MaxSize:  1 << 30,
+		Compress: true,
+	}
+}
+
+// shouldRoll returns whether the current file must be rolled before the next
+// entry is written to it
+func (f *fsJournal) shouldRoll(now time.Time) bool {
+	if f.fSize == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.fSize >= f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && !f.fStart.IsZero() && now.Sub(f.fStart) >= f.opts.MaxAge
+}
+
+// compressRolled gzips the rolled files that are not compressed yet, which
+// includes files left behind by an interrupted compression
+func (f *fsJournal) compressRolled() error {
+	segs, err := segments(f.dir)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segs {
+		if seg.compressed || seg.rolled.IsZero() {
+			continue
+		}
+		if err := compressFile(seg.path); err != nil {
+			return xerrors.Errorf("failed to compress journal file: %w", err)
+		}
+	}
+	return nil
+}
+
+// compressFile replaces path with a gzipped copy named path.gz. The copy is
+// written to a temporary file first, so that a complete path.gz always exists
+// before path is removed.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + compressedSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(path)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path+compressedSuffix); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// applyRetention removes the oldest rolled files beyond MaxFiles, and the
+// rolled files older than MaxFileAge. The current file is always kept.
+func (f *fsJournal) applyRetention(now time.Time) error {
+	if f.opts.MaxFiles <= 0 && f.opts.MaxFileAge <= 0 {
+		return nil
+	}
+
+	segs, err := segments(f.dir)
+	if err != nil {
+		return err
+	}
+
+	var rolled []segment
+	for _, seg := range segs {
+		if !seg.rolled.IsZero() {
+			rolled = append(rolled, seg)
+		}
+	}
+
+	for i, seg := range rolled {
+		tooMany := f.opts.MaxFiles > 0 && len(rolled)-i > f.opts.MaxFiles
+		tooOld := f.opts.MaxFileAge > 0 && now.Sub(seg.rolled) > f.opts.MaxFileAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return xerrors.Errorf("failed to remove journal file: %w", err)
+		}
+	}
+	return nil
+}
+
+// firstEntryTime returns the timestamp of the first entry of a journal file,
+// or the zero time if the file is empty
+func firstEntryTime(path string) (time.Time, error) {
+	fi, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	defer fi.Close()
+
+	scanner := bufio.NewScanner(fi)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var evt journal.Event
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			continue
+		}
+		return evt.Timestamp, nil
+	}
+	return time.Time{}, scanner.Err()
+}
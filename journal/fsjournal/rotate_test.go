@@ -0,0 +1,69 @@
+package fsjournal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/glifio/glif/v2/journal"
+)
+
+func TestCompressedJournal(t *testing.T) {
+	root := t.TempDir()
+	segs := writeJournal(t, root, 10, Options{MaxSize: 300, Compress: true})
+	require.Greater(t, len(segs), 2)
+
+	for _, seg := range segs[:len(segs)-1] {
+		require.True(t, seg.compressed, seg.path)
+		require.Equal(t, compressedSuffix, filepath.Ext(seg.path))
+	}
+
+	// compressed files are read transparently
+	var n int
+	require.NoError(t, QueryEvents(root, journal.Query{}, func(e journal.Event) error {
+		n++
+		return nil
+	}))
+	require.Equal(t, 10, n)
+
+	report, err := Verify(root)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, uint64(10), report.LastSeq)
+
+	// the chain continues from a compressed file when the current file is empty
+	head, _, err := chainHead(filepath.Join(root, "journal"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), head)
+}
+
+func TestRollByAge(t *testing.T) {
+	root := t.TempDir()
+
+	// one event every minute, rolled every 5 minutes
+	segs := writeJournal(t, root, 10, Options{MaxAge: 5 * time.Minute})
+	require.Len(t, segs, 2)
+}
+
+func TestRetention(t *testing.T) {
+	root := t.TempDir()
+	segs := writeJournal(t, root, 20, Options{MaxSize: 300, Compress: true, MaxFiles: 2})
+	require.Len(t, segs, 3)
+
+	// removing old files does not break the chain
+	report, err := Verify(root)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Greater(t, report.FirstSeq, uint64(1))
+	require.Equal(t, uint64(20), report.LastSeq)
+
+	root = t.TempDir()
+	segs = writeJournal(t, root, 20, Options{MaxSize: 300, MaxFileAge: 5 * time.Minute})
+	for _, seg := range segs[:len(segs)-1] {
+		require.False(t, seg.compressed)
+	}
+	// the last event is recorded 20 minutes after the first one
+	require.LessOrEqual(t, len(segs), 4)
+}
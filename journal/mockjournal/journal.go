@@ -6,6 +6,7 @@ package mockjournal
 
 import (
 	reflect "reflect"
+	time "time"
 
 	journal "github.com/glifio/glif/v2/journal"
 	gomock "github.com/golang/mock/gomock"
@@ -48,6 +49,20 @@ func (mr *MockJournalMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockJournal)(nil).Close))
 }
 
+// QueryEvents mocks base method.
+func (m *MockJournal) QueryEvents(arg0 journal.Query, arg1 func(journal.Event) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryEvents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueryEvents indicates an expected call of QueryEvents.
+func (mr *MockJournalMockRecorder) QueryEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryEvents", reflect.TypeOf((*MockJournal)(nil).QueryEvents), arg0, arg1)
+}
+
 // ReadEvents mocks base method.
 func (m *MockJournal) ReadEvents() ([]journal.Event, error) {
 	m.ctrl.T.Helper()
@@ -75,6 +90,18 @@ func (mr *MockJournalMockRecorder) RecordEvent(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEvent", reflect.TypeOf((*MockJournal)(nil).RecordEvent), arg0, arg1)
 }
 
+// RecordEventAt mocks base method.
+func (m *MockJournal) RecordEventAt(arg0 journal.EventType, arg1 time.Time, arg2 func() interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordEventAt", arg0, arg1, arg2)
+}
+
+// RecordEventAt indicates an expected call of RecordEventAt.
+func (mr *MockJournalMockRecorder) RecordEventAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEventAt", reflect.TypeOf((*MockJournal)(nil).RecordEventAt), arg0, arg1, arg2)
+}
+
 // RegisterEventType mocks base method.
 func (m *MockJournal) RegisterEventType(arg0, arg1 string) journal.EventType {
 	m.ctrl.T.Helper()
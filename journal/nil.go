@@ -1,5 +1,7 @@
 package journal
 
+import "time"
+
 type nilJournal struct{}
 
 // nilj is a singleton nil journal.
@@ -13,6 +15,10 @@ func (n *nilJournal) RegisterEventType(_, _ string) EventType { return EventType
 
 func (n *nilJournal) RecordEvent(_ EventType, _ func() interface{}) {}
 
+func (n *nilJournal) RecordEventAt(_ EventType, _ time.Time, _ func() interface{}) {}
+
 func (n *nilJournal) ReadEvents() ([]Event, error) { return nil, nil }
 
+func (n *nilJournal) QueryEvents(_ Query, _ func(Event) error) error { return nil }
+
 func (n *nilJournal) Close() error { return nil }
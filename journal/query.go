@@ -0,0 +1,141 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrStop can be returned by a QueryEvents callback to end the iteration
+// early without an error.
+var ErrStop = errors.New("stop iteration")
+
+// Query selects journal events. Zero value fields match everything.
+type Query struct {
+	// System and Event match the event type, e.g. "agent" and "pay".
+	System string
+	Event  string
+
+	// Since and Until bound the event timestamps, both inclusive.
+	Since time.Time
+	Until time.Time
+
+	// ErrorsOnly selects events that recorded an error.
+	ErrorsOnly bool
+
+	// Tx selects events that recorded the given transaction hash.
+	Tx string
+
+	// Limit keeps only the most recent Limit matching events.
+	Limit int
+}
+
+// Match returns whether the event is selected by the query. Limit is not
+// taken into account, see Collect.
+func (q Query) Match(e Event) bool {
+	if q.System != "" && q.System != e.System {
+		return false
+	}
+	if q.Event != "" && q.Event != e.Event {
+		return false
+	}
+	if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Timestamp.After(q.Until) {
+		return false
+	}
+	if q.ErrorsOnly || q.Tx != "" {
+		c := eventCommon(e.Data)
+		if q.ErrorsOnly && c.Error == "" {
+			return false
+		}
+		if q.Tx != "" && !strings.EqualFold(q.Tx, c.Tx) {
+			return false
+		}
+	}
+	return true
+}
+
+// commonFields are the fields shared by the events recorded by glif commands
+type commonFields struct {
+	Error  string `json:"error"`
+	Tx     string `json:"tx"`
+	Status string `json:"status"`
+}
+
+func eventCommon(data interface{}) commonFields {
+	var c commonFields
+	if m, ok := data.(map[string]interface{}); ok {
+		c.Error, _ = m["error"].(string)
+		c.Tx, _ = m["tx"].(string)
+		c.Status, _ = m["status"].(string)
+		return c
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return c
+	}
+	_ = json.Unmarshal(b, &c)
+	return c
+}
+
+// EventError returns the error recorded by an event of a glif command, or an
+// empty string if it did not record one
+func EventError(e Event) string {
+	return eventCommon(e.Data).Error
+}
+
+// EventTx returns the transaction hash recorded by an event of a glif command,
+// or an empty string if it did not record one
+func EventTx(e Event) string {
+	return eventCommon(e.Data).Tx
+}
+
+// EventStatus returns the stage of the transaction lifecycle recorded by an
+// event of a glif command, or an empty string for events that predate it
+func EventStatus(e Event) string {
+	return eventCommon(e.Data).Status
+}
+
+// Collect is a helper for QueryEvents implementations. It returns a function
+// that filters events with the query, and a flush function that must be
+// called once all events were visited. When the query has a Limit, matching
+// events are buffered so that only the most recent ones reach fn.
+func Collect(q Query, fn func(Event) error) (visit func(Event) error, flush func() error) {
+	if q.Limit <= 0 {
+		visit = func(e Event) error {
+			if !q.Match(e) {
+				return nil
+			}
+			return fn(e)
+		}
+		return visit, func() error { return nil }
+	}
+
+	ring := make([]Event, 0, q.Limit)
+	start := 0
+	visit = func(e Event) error {
+		if !q.Match(e) {
+			return nil
+		}
+		if len(ring) < q.Limit {
+			ring = append(ring, e)
+			return nil
+		}
+		ring[start] = e
+		start = (start + 1) % q.Limit
+		return nil
+	}
+	flush = func() error {
+		for i := 0; i < len(ring); i++ {
+			if err := fn(ring[(start+i)%len(ring)]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit, flush
+}
@@ -7,6 +7,7 @@ package journal
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -87,8 +88,17 @@ type Journal interface {
 	// Implementations MUST recover from panics raised by the supplier function.
 	RecordEvent(evtType EventType, supplier func() interface{})
 
+	// RecordEventAt is RecordEvent for an event that happened at t, before it
+	// was recorded, e.g. an event rebuilt from chain.
+	RecordEventAt(evtType EventType, t time.Time, supplier func() interface{})
+
+	// ReadEvents reads all recorded events in chronological order.
 	ReadEvents() ([]Event, error)
 
+	// QueryEvents streams the recorded events selected by the query to fn
+	// in chronological order. Returning ErrStop from fn ends the iteration.
+	QueryEvents(q Query, fn func(Event) error) error
+
 	// Close closes this journal for further writing.
 	Close() error
 }
@@ -101,6 +111,12 @@ type Event struct {
 
 	Timestamp time.Time   `json:"timestamp,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
+
+	// Seq and PrevHash chain the entries of journals that are tamper-evident.
+	// Seq increases by one with every entry, and PrevHash is the hex encoded
+	// SHA-256 hash of the previous entry as it was written.
+	Seq      uint64 `json:"seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
 }
 
 func (e Event) String() string {
@@ -108,9 +124,15 @@ func (e Event) String() string {
 	if !ok {
 		return fmt.Sprintf("%s: %s", e.Timestamp.String(), e.EventType.String())
 	}
-	data := []string{}
-	for k, v := range d {
-		data = append(data, fmt.Sprintf("%s: %v", k, v))
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	data := make([]string, 0, len(keys))
+	for _, k := range keys {
+		data = append(data, fmt.Sprintf("%s: %v", k, d[k]))
 	}
 	return fmt.Sprintf("%s:\t%s\t%s", e.Timestamp.String(), e.EventType.String(), strings.Join(data, "\t"))
 }
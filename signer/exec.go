@@ -0,0 +1,93 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Exec signs with an external program, run once per transaction. The program
+// reads an ExecRequest as JSON on its standard input, and writes an
+// ExecResponse as JSON on its standard output. A non zero exit status fails
+// the signature, what the program writes on its standard error is shown to
+// the user.
+type Exec struct {
+	// Command is the program and its arguments, split on spaces
+	Command string
+}
+
+// ExecRequest asks the signer program to sign a transaction
+type ExecRequest struct {
+	From    common.Address     `json:"from"`
+	ChainID *hexutil.Big       `json:"chain_id"`
+	Tx      *types.Transaction `json:"tx"`
+	// Hash is the signing hash of the transaction
+	Hash common.Hash `json:"hash"`
+	// Payload is the preimage of Hash, for keys that hash what they sign
+	Payload hexutil.Bytes `json:"payload"`
+}
+
+// ExecResponse is the answer of the signer program, either the 65 bytes
+// [R || S || V] signature of the signing hash, or the signed transaction in
+// its binary encoding
+type ExecResponse struct {
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+	Raw       hexutil.Bytes `json:"raw,omitempty"`
+}
+
+func (e *Exec) SignTx(ctx context.Context, from common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := strings.Fields(e.Command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no signer program")
+	}
+	payload, err := signingPayload(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := json.Marshal(&ExecRequest{
+		From:    from,
+		ChainID: (*hexutil.Big)(chainID),
+		Tx:      tx,
+		Hash:    types.LatestSignerForChainID(chainID).Hash(tx),
+		Payload: payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("signer %s: %w", args[0], err)
+	}
+
+	var res ExecResponse
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return nil, fmt.Errorf("signer %s: invalid response: %w", args[0], err)
+	}
+	switch {
+	case len(res.Signature) > 0:
+		return withSignature(from, tx, chainID, res.Signature)
+	case len(res.Raw) > 0:
+		signed := new(types.Transaction)
+		if err := signed.UnmarshalBinary(res.Raw); err != nil {
+			return nil, fmt.Errorf("signer %s: invalid signed transaction: %w", args[0], err)
+		}
+		return signed, checkSigned(from, tx, signed, chainID)
+	default:
+		return nil, fmt.Errorf("signer %s: no signature in response", args[0])
+	}
+}
@@ -0,0 +1,25 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Keystore signs with the keys of the local keystore
+type Keystore struct {
+	KeyStore   *keystore.KeyStore
+	Passphrase string
+}
+
+func (k *Keystore) SignTx(ctx context.Context, from common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if !k.KeyStore.HasAddress(from) {
+		return nil, fmt.Errorf("%s is not in the keystore", from)
+	}
+	return k.KeyStore.SignTxWithPassphrase(accounts.Account{Address: from}, k.Passphrase, tx, chainID)
+}
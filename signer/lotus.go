@@ -0,0 +1,52 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/glifio/glif/v2/util"
+)
+
+// WalletAPI is the part of the lotus API that Lotus signs with
+type WalletAPI interface {
+	WalletSign(ctx context.Context, addr address.Address, msg []byte) (*crypto.Signature, error)
+}
+
+// Lotus signs with the delegated (f4) keys held in the wallet of a lotus
+// node. Connect is called for each transaction.
+type Lotus struct {
+	Connect func() (WalletAPI, func(), error)
+}
+
+func (l *Lotus) SignTx(ctx context.Context, from common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	payload, err := signingPayload(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	delegated, err := util.DelegatedFromEthAddr(from)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, closer, err := l.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	// delegated keys sign the keccak256 hash of the message, which is the
+	// signing hash of the transaction
+	sig, err := wallet.WalletSign(ctx, delegated, payload)
+	if err != nil {
+		return nil, fmt.Errorf("lotus wallet: %w", err)
+	}
+	if sig.Type != crypto.SigTypeDelegated {
+		return nil, fmt.Errorf("lotus wallet: %s is not a delegated key", delegated)
+	}
+	return withSignature(from, tx, chainID, sig.Data)
+}
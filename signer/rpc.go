@@ -0,0 +1,80 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// RPC signs with a remote signing service over JSON-RPC: Clef and services
+// implementing its account_signTransaction method, or nodes and services
+// implementing eth_signTransaction. The service holds the key, and may ask
+// its operator to approve each transaction.
+type RPC struct {
+	// URL is the HTTP, WebSocket or IPC endpoint of the service
+	URL string
+	// Method is account_signTransaction or eth_signTransaction
+	Method string
+}
+
+// NewClef returns a signer of a Clef service
+func NewClef(url string) *RPC {
+	return &RPC{URL: url, Method: "account_signTransaction"}
+}
+
+// NewEth returns a signer of a service implementing eth_signTransaction
+func NewEth(url string) *RPC {
+	return &RPC{URL: url, Method: "eth_signTransaction"}
+}
+
+type signTransactionResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+func (r *RPC) SignTx(ctx context.Context, from common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+
+	client, err := rpc.DialContext(ctx, r.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	data := hexutil.Bytes(tx.Data())
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		t := common.NewMixedcaseAddress(*tx.To())
+		to = &t
+	}
+	accessList := tx.AccessList()
+	args := &apitypes.SendTxArgs{
+		From:                 common.NewMixedcaseAddress(from),
+		To:                   to,
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                hexutil.Big(*tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Data:                 &data,
+		AccessList:           &accessList,
+		ChainID:              (*hexutil.Big)(chainID),
+	}
+
+	var res signTransactionResult
+	if err := client.CallContext(ctx, &res, r.Method, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", r.Method, err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(res.Raw); err != nil {
+		return nil, fmt.Errorf("%s: invalid signed transaction: %w", r.Method, err)
+	}
+	return signed, checkSigned(from, tx, signed, chainID)
+}
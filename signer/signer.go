@@ -0,0 +1,138 @@
+// Package signer signs the Ethereum transactions of glif accounts, with the
+// local keystore or with a key held elsewhere: in the wallet of a lotus node,
+// by a Clef compatible signing service, or by an external program.
+package signer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Signer signs the transactions of an account
+type Signer interface {
+	SignTx(ctx context.Context, from common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// Kinds of signers
+const (
+	KindKeystore = "keystore"
+	KindLotus    = "lotus"
+	KindClef     = "clef"
+	KindEth      = "eth"
+	KindExec     = "exec"
+)
+
+// Spec selects the signer of an account, as written in signers.toml:
+//
+//	keystore                      the local keystore, the default
+//	lotus                         the wallet of the lotus node glif connects to
+//	clef:<url>                    a Clef signer, with account_signTransaction
+//	eth:<url>                     a node or service with eth_signTransaction
+//	exec:<program> [args...]      an external program, see Exec
+type Spec struct {
+	Kind   string
+	Target string
+}
+
+// ParseSpec parses the signer of an account. An empty spec is the keystore.
+func ParseSpec(s string) (Spec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Spec{Kind: KindKeystore}, nil
+	}
+	kind, target, _ := strings.Cut(s, ":")
+	spec := Spec{Kind: strings.ToLower(kind), Target: strings.TrimSpace(target)}
+	switch spec.Kind {
+	case KindKeystore, KindLotus:
+		if spec.Target != "" {
+			return Spec{}, fmt.Errorf("signer %q takes no argument", spec.Kind)
+		}
+	case KindClef, KindEth:
+		if spec.Target == "" {
+			return Spec{}, fmt.Errorf("signer %q needs the URL of the signing service, e.g. %s:http://127.0.0.1:8550", spec.Kind, spec.Kind)
+		}
+	case KindExec:
+		if spec.Target == "" {
+			return Spec{}, fmt.Errorf("signer %q needs the program to run, e.g. exec:/usr/local/bin/glif-signer", spec.Kind)
+		}
+	default:
+		return Spec{}, fmt.Errorf("unknown signer %q, expected keystore, lotus, clef:<url>, eth:<url> or exec:<program>", kind)
+	}
+	return spec, nil
+}
+
+func (s Spec) String() string {
+	if s.Target == "" {
+		return s.Kind
+	}
+	return s.Kind + ":" + s.Target
+}
+
+// signingPayload is the preimage of the hash that is signed for tx, which
+// keys that hash what they sign, as lotus delegated keys do, need instead of
+// the hash
+func signingPayload(tx *types.Transaction, chainID *big.Int) ([]byte, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+	payload, err := rlp.EncodeToBytes([]interface{}{
+		chainID,
+		tx.Nonce(),
+		tx.GasTipCap(),
+		tx.GasFeeCap(),
+		tx.Gas(),
+		tx.To(),
+		tx.Value(),
+		tx.Data(),
+		tx.AccessList(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload = append([]byte{types.DynamicFeeTxType}, payload...)
+
+	signer := types.LatestSignerForChainID(chainID)
+	if !bytes.Equal(crypto.Keccak256(payload), signer.Hash(tx).Bytes()) {
+		return nil, fmt.Errorf("cannot encode the signing payload of transaction %s", tx.Hash())
+	}
+	return payload, nil
+}
+
+// withSignature adds the 65 bytes [R || S || V] signature to tx, and checks
+// that it was signed by from
+func withSignature(from common.Address, tx *types.Transaction, chainID *big.Int, sig []byte) (*types.Transaction, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	signed, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return nil, err
+	}
+	return signed, checkSigned(from, tx, signed, chainID)
+}
+
+// checkSigned checks that a transaction signed elsewhere is tx, signed by
+// from for the chain
+func checkSigned(from common.Address, tx, signed *types.Transaction, chainID *big.Int) error {
+	signer := types.LatestSignerForChainID(chainID)
+	if signer.Hash(signed) != signer.Hash(tx) {
+		return fmt.Errorf("the signer returned a different transaction than the one it was asked to sign")
+	}
+	sender, err := types.Sender(signer, signed)
+	if err != nil {
+		return err
+	}
+	if sender != from {
+		return fmt.Errorf("transaction was signed by %s instead of %s", sender, from)
+	}
+	return nil
+}
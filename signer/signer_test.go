@@ -0,0 +1,234 @@
+package signer_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/filecoin-project/go-address"
+	fcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/glifio/glif/v2/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var chainID = big.NewInt(314159)
+
+// helperKeyEnv makes the test binary act as an exec signer program, signing
+// with the hex encoded key it holds
+const helperKeyEnv = "GLIF_TEST_SIGNER_KEY"
+
+func TestMain(m *testing.M) {
+	if key := os.Getenv(helperKeyEnv); key != "" {
+		os.Exit(runExecSigner(key))
+	}
+	os.Exit(m.Run())
+}
+
+func runExecSigner(hexKey string) int {
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var req signer.ExecRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sig, err := crypto.Sign(req.Hash.Bytes(), key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(&signer.ExecResponse{Signature: sig}); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func newTx() *types.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000a6e47")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     7,
+		GasTipCap: big.NewInt(1000),
+		GasFeeCap: big.NewInt(2000000),
+		Gas:       1000000,
+		To:        &to,
+		Value:     big.NewInt(1),
+		Data:      []byte{0xde, 0xad, 0xbe, 0xef},
+	})
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// assertSigned checks that signed is tx, signed by from
+func assertSigned(t *testing.T, from common.Address, tx, signed *types.Transaction) {
+	s := types.LatestSignerForChainID(chainID)
+	sender, err := types.Sender(s, signed)
+	if assert.NoError(t, err) {
+		assert.Equal(t, from, sender)
+	}
+	assert.Equal(t, s.Hash(tx), s.Hash(signed))
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		in   string
+		want signer.Spec
+		err  bool
+	}{
+		{"", signer.Spec{Kind: signer.KindKeystore}, false},
+		{"keystore", signer.Spec{Kind: signer.KindKeystore}, false},
+		{"lotus", signer.Spec{Kind: signer.KindLotus}, false},
+		{"clef:http://127.0.0.1:8550", signer.Spec{Kind: signer.KindClef, Target: "http://127.0.0.1:8550"}, false},
+		{"eth:/run/signer.ipc", signer.Spec{Kind: signer.KindEth, Target: "/run/signer.ipc"}, false},
+		{"exec:/usr/bin/sign --key owner", signer.Spec{Kind: signer.KindExec, Target: "/usr/bin/sign --key owner"}, false},
+		{"clef", signer.Spec{}, true},
+		{"lotus:f410f", signer.Spec{}, true},
+		{"ledger", signer.Spec{}, true},
+	}
+	for _, tt := range tests {
+		got, err := signer.ParseSpec(tt.in)
+		if tt.err {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		if assert.NoError(t, err, tt.in) {
+			assert.Equal(t, tt.want, got, tt.in)
+		}
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	acc, err := ks.NewAccount("secret")
+	require.NoError(t, err)
+
+	tx := newTx()
+	s := &signer.Keystore{KeyStore: ks, Passphrase: "secret"}
+	signed, err := s.SignTx(context.Background(), acc.Address, tx, chainID)
+	if assert.NoError(t, err) {
+		assertSigned(t, acc.Address, tx, signed)
+	}
+
+	s.Passphrase = "wrong"
+	_, err = s.SignTx(context.Background(), acc.Address, tx, chainID)
+	assert.Error(t, err)
+}
+
+// lotusWallet signs as the delegated keys of a lotus wallet do
+type lotusWallet struct {
+	key *ecdsa.PrivateKey
+}
+
+func (w *lotusWallet) WalletSign(ctx context.Context, addr address.Address, msg []byte) (*fcrypto.Signature, error) {
+	sig, err := crypto.Sign(crypto.Keccak256(msg), w.key)
+	if err != nil {
+		return nil, err
+	}
+	return &fcrypto.Signature{Type: fcrypto.SigTypeDelegated, Data: sig}, nil
+}
+
+func TestLotus(t *testing.T) {
+	key, from := newKey(t)
+	s := &signer.Lotus{Connect: func() (signer.WalletAPI, func(), error) {
+		return &lotusWallet{key: key}, func() {}, nil
+	}}
+
+	tx := newTx()
+	signed, err := s.SignTx(context.Background(), from, tx, chainID)
+	if assert.NoError(t, err) {
+		assertSigned(t, from, tx, signed)
+	}
+
+	_, other := newKey(t)
+	_, err = s.SignTx(context.Background(), other, tx, chainID)
+	assert.Error(t, err)
+}
+
+// clefService implements account_signTransaction
+type clefService struct {
+	key *ecdsa.PrivateKey
+}
+
+func (c *clefService) SignTransaction(args apitypes.SendTxArgs) (map[string]interface{}, error) {
+	var to *common.Address
+	if args.To != nil {
+		addr := args.To.Address()
+		to = &addr
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     uint64(args.Nonce),
+		GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(),
+		Gas:       uint64(args.Gas),
+		To:        to,
+		Value:     args.Value.ToInt(),
+		Data:      *args.Data,
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), c.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": signed}, nil
+}
+
+func TestClef(t *testing.T) {
+	key, from := newKey(t)
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("account", &clefService{key: key}))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	defer server.Stop()
+
+	s := signer.NewClef(httpServer.URL)
+	tx := newTx()
+	signed, err := s.SignTx(context.Background(), from, tx, chainID)
+	if assert.NoError(t, err) {
+		assertSigned(t, from, tx, signed)
+	}
+
+	// a service that signs with another key is caught
+	_, other := newKey(t)
+	_, err = s.SignTx(context.Background(), other, tx, chainID)
+	assert.ErrorContains(t, err, "instead of")
+}
+
+func TestExec(t *testing.T) {
+	key, from := newKey(t)
+	t.Setenv(helperKeyEnv, hexutil.Encode(crypto.FromECDSA(key))[2:])
+
+	s := &signer.Exec{Command: os.Args[0]}
+	tx := newTx()
+	signed, err := s.SignTx(context.Background(), from, tx, chainID)
+	if assert.NoError(t, err) {
+		assertSigned(t, from, tx, signed)
+	}
+
+	t.Setenv(helperKeyEnv, "not a key")
+	_, err = s.SignTx(context.Background(), from, tx, chainID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// ErrLocked is returned by TryLockFile when another process holds the lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// FileLock is an advisory lock on a file, shared by all glif processes that
+// use the same config directory
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile blocks until it holds an exclusive lock on path, creating the file
+// if needed
+func LockFile(path string) (*FileLock, error) {
+	return lockFile(path, syscall.LOCK_EX)
+}
+
+// TryLockFile takes an exclusive lock on path, creating the file if needed. It
+// returns ErrLocked instead of blocking if another process holds the lock.
+func TryLockFile(path string) (*FileLock, error) {
+	l, err := lockFile(path, syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, ErrLocked
+	}
+	return l, err
+}
+
+func lockFile(path string, how int) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), how); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileLock{f: f}, nil
+}
+
+// File returns the locked file, e.g. to record the PID of the lock holder
+func (l *FileLock) File() *os.File {
+	return l.f
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if err := syscall.Flock(int(l.f.Fd()), syscall.LOCK_UN); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// WriteFileAtomic writes data to a temporary file in the directory of path,
+// syncs it and renames it over path, so that path always holds either its
+// previous or its new content, even if the process crashes mid-write
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// persist the rename itself
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
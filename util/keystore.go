@@ -1,6 +1,9 @@
 package util
 
 import (
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 )
 
@@ -8,16 +11,73 @@ type KeyStorage struct {
 	*Storage
 }
 
-var keyStore *keystore.KeyStore
+// LockedKeyStore is a keystore whose writes hold an advisory lock on the
+// keystore directory, so that concurrent glif processes do not interleave
+// them
+type LockedKeyStore struct {
+	*keystore.KeyStore
+	lockPath string
+}
+
+var keyStore *LockedKeyStore
 
-func KeyStore() *keystore.KeyStore {
+func KeyStore() *LockedKeyStore {
 	return keyStore
 }
 
 func NewKeyStore(keydir string) {
-	keyStore = keystore.NewKeyStore(
-		keydir,
-		keystore.StandardScryptN,
-		keystore.StandardScryptP,
-	)
+	keyStore = &LockedKeyStore{
+		KeyStore: keystore.NewKeyStore(
+			keydir,
+			keystore.StandardScryptN,
+			keystore.StandardScryptP,
+		),
+		// outside of keydir, which only holds key files
+		lockPath: keydir + ".lock",
+	}
+}
+
+func (ks *LockedKeyStore) withLock(fn func() error) error {
+	l, err := LockFile(ks.lockPath)
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	return fn()
+}
+
+func (ks *LockedKeyStore) NewAccount(passphrase string) (account accounts.Account, err error) {
+	err = ks.withLock(func() error {
+		account, err = ks.KeyStore.NewAccount(passphrase)
+		return err
+	})
+	return account, err
+}
+
+func (ks *LockedKeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (account accounts.Account, err error) {
+	err = ks.withLock(func() error {
+		account, err = ks.KeyStore.Import(keyJSON, passphrase, newPassphrase)
+		return err
+	})
+	return account, err
+}
+
+func (ks *LockedKeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (account accounts.Account, err error) {
+	err = ks.withLock(func() error {
+		account, err = ks.KeyStore.ImportECDSA(priv, passphrase)
+		return err
+	})
+	return account, err
+}
+
+func (ks *LockedKeyStore) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	return ks.withLock(func() error {
+		return ks.KeyStore.Update(a, passphrase, newPassphrase)
+	})
+}
+
+func (ks *LockedKeyStore) Delete(a accounts.Account, passphrase string) error {
+	return ks.withLock(func() error {
+		return ks.KeyStore.Delete(a, passphrase)
+	})
 }
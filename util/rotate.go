@@ -0,0 +1,121 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFile is a log file that is rotated once it reaches MaxSize bytes.
+// Rotated files are renamed to path.1, path.2 and so on, path.1 being the most
+// recent one, and only the MaxFiles most recent ones are kept.
+type RotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending. A maxSize of zero never rotates
+// the file, a maxFiles of zero keeps no rotated files.
+func OpenRotatingFile(path string, maxSize int64, maxFiles int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p does not fit in it
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		rotateErr = r.rotate()
+		if r.f == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// rotate shifts the rotated files by one, dropping the oldest one, and starts
+// a new file at path. When the files cannot be shifted, writes go on to the
+// file at path, which is only rotated again once another maxSize bytes were
+// written to it, and the error is reported.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	err := r.shift()
+	if openErr := r.open(); openErr != nil {
+		return openErr
+	}
+	if err != nil {
+		r.size = 0
+	}
+	return err
+}
+
+func (r *RotatingFile) shift() error {
+	os.Remove(r.rotatedName(r.maxFiles))
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(r.rotatedName(i), r.rotatedName(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if r.maxFiles > 0 {
+		return os.Rename(r.path, r.rotatedName(1))
+	}
+	return os.Remove(r.path)
+}
+
+func (r *RotatingFile) rotatedName(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// Close closes the file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,110 @@
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glifio/glif/v2/util"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glif.log")
+
+	f, err := util.OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+
+	// every line overflows the 10 bytes of the file, so that each one starts
+	// a new file, and the first line was rotated out
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Errorf("ReadFile(%s) error: %v", name, err)
+		} else if string(b) != content {
+			t.Errorf("%s = %q, want %q", name, b, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists, want at most 2 rotated files", path)
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glif.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := util.OpenRotatingFile(path, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	f.Write([]byte("new\n"))
+	f.Close()
+
+	b, _ := os.ReadFile(path)
+	if string(b) != "old\nnew\n" {
+		t.Errorf("file = %q, want the new line appended", b)
+	}
+}
+
+func TestRotatingFileRotateFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glif.log")
+
+	f, err := util.OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	defer f.Close()
+
+	// a directory in the way of the rotated file fails the rotation
+	if err := os.MkdirAll(filepath.Join(path+".1", "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("first\n"))
+	n, err := f.Write([]byte("second\n"))
+	if err == nil {
+		t.Errorf("Write() succeeded, want the rotation error")
+	}
+	if n != len("second\n") {
+		t.Errorf("Write() = %d, want the line written anyway", n)
+	}
+	// the rotation is only tried again once the file grew by maxSize
+	if _, err := f.Write([]byte("x\n")); err != nil {
+		t.Errorf("Write() error: %v, want no rotation", err)
+	}
+	b, _ := os.ReadFile(path)
+	if string(b) != "first\nsecond\nx\n" {
+		t.Errorf("file = %q, want the lines written while the rotation failed", b)
+	}
+
+	// the file is rotated once the way is clear
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("third\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	b, _ = os.ReadFile(path)
+	if string(b) != "third\n" {
+		t.Errorf("file = %q, want the line after the rotation", b)
+	}
+	b, _ = os.ReadFile(path + ".1")
+	if string(b) != "first\nsecond\nx\n" {
+		t.Errorf("%s.1 = %q, want the lines before the rotation", path, b)
+	}
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SignersStorage selects the signer of the accounts, by account name. It is
+// kept apart from accounts.toml, which older versions read as flat key-value
+// pairs.
+type SignersStorage struct {
+	*Storage
+}
+
+var signersStore *SignersStorage
+
+func SignersStore() *SignersStorage {
+	return signersStore
+}
+
+func NewSignersStore(filename string) error {
+	signersDefault := map[string]string{}
+
+	s, err := NewStorage(filename, signersDefault, true)
+	if err != nil {
+		return err
+	}
+
+	signersStore = &SignersStorage{s}
+
+	return nil
+}
+
+// Signer returns the signer of the account, or an empty string when it signs
+// with the local keystore
+func (s *SignersStorage) Signer(name string) string {
+	spec, _ := s.Get(name)
+	return spec
+}
+
+// SignerOf returns the signer of the account with the address in the
+// accounts, or an empty string when it signs with the local keystore
+func (s *SignersStorage) SignerOf(accounts *AccountsStorage, addr common.Address) string {
+	for name, value := range accounts.data {
+		if value != "" && common.HexToAddress(value) == addr {
+			if spec := s.Signer(name); spec != "" {
+				return spec
+			}
+		}
+	}
+	return ""
+}
+
+// SetSigner selects the signer of the account, an empty spec resets it to the
+// local keystore
+func (s *SignersStorage) SetSigner(name, spec string) error {
+	if spec == "" {
+		err := s.Delete(name)
+		var e *ErrKeyNotFound
+		if errors.As(err, &e) {
+			return nil
+		}
+		return err
+	}
+	return s.Set(name, spec)
+}
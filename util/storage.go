@@ -31,6 +31,12 @@ func NewStorage(filename string, defaultMap map[string]string, writable bool) (*
 		writable: writable,
 	}
 
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		err = s.save()
 		if err != nil {
@@ -77,7 +83,9 @@ func (s *Storage) load() error {
 	return nil
 }
 
-// save writes the current key-value pairs in the data map to the file.
+// save writes the current key-value pairs in the data map to the file. The
+// file is replaced atomically, so a crash or a concurrent reader never sees a
+// truncated file.
 func (s *Storage) save() error {
 	if !s.writable {
 		return nil
@@ -87,12 +95,44 @@ func (s *Storage) save() error {
 		return err
 	}
 
-	err = os.WriteFile(s.filename, keyStore, 0644)
+	return WriteFileAtomic(s.filename, keyStore, 0644)
+}
+
+// lock takes the advisory lock that serializes the mutations of the file
+// across glif processes
+func (s *Storage) lock() (func(), error) {
+	if !s.writable {
+		return func() {}, nil
+	}
+	l, err := LockFile(s.filename + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", s.filename, err)
+	}
+	return func() { _ = l.Unlock() }, nil
+}
+
+// update applies a mutation to the latest content of the file under its
+// lock, so that the changes saved by other processes in the meantime are kept
+func (s *Storage) update(mutate func() error) error {
+	unlock, err := s.lock()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	return nil
+	if s.writable {
+		if err := s.load(); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if s.data == nil {
+			s.data = StorageData{}
+		}
+	}
+
+	if err := mutate(); err != nil {
+		return err
+	}
+	return s.save()
 }
 
 // Get retrieves the value associated with the given key.
@@ -106,17 +146,21 @@ func (s *Storage) Get(key string) (string, error) {
 
 // Set sets a key-value pair in the data map and saves the data to the file.
 func (s *Storage) Set(key, value string) error {
-	s.data[key] = value
-	return s.save()
+	return s.update(func() error {
+		s.data[key] = value
+		return nil
+	})
 }
 
 // Delete removes a key-value pair from the data map and saves the data to the file.
 func (s *Storage) Delete(key string) error {
-	if _, ok := s.data[key]; !ok {
-		return &ErrKeyNotFound{key}
-	}
-	delete(s.data, key)
-	return s.save()
+	return s.update(func() error {
+		if _, ok := s.data[key]; !ok {
+			return &ErrKeyNotFound{key}
+		}
+		delete(s.data, key)
+		return nil
+	})
 }
 
 // AccountNames retrieves a list of all the account names
@@ -2,6 +2,7 @@ package util_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/glifio/glif/v2/util"
@@ -22,6 +23,7 @@ func TestNewStorage(t *testing.T) {
 
 	// Cleanup
 	os.Remove(testFilename)
+	os.Remove(testFilename + ".lock")
 }
 
 func TestSetNonExistentKey(t *testing.T) {
@@ -82,6 +84,7 @@ func TestGetSetDelete(t *testing.T) {
 
 	// Cleanup
 	os.Remove(testFilename)
+	os.Remove(testFilename + ".lock")
 }
 
 func TestNonexistentKey(t *testing.T) {
@@ -102,4 +105,68 @@ func TestNonexistentKey(t *testing.T) {
 
 	// Cleanup
 	os.Remove(testFilename)
+	os.Remove(testFilename + ".lock")
+}
+
+func TestConcurrentStorages(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "accounts.toml")
+
+	// two processes that loaded the file before either of them wrote to it
+	a, err := util.NewStorage(filename, map[string]string{}, true)
+	if err != nil {
+		t.Fatalf("NewStorage() error: %v", err)
+	}
+	b, err := util.NewStorage(filename, map[string]string{}, true)
+	if err != nil {
+		t.Fatalf("NewStorage() error: %v", err)
+	}
+
+	if err := a.Set("owner", "0x01"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if err := b.Set("operator", "0x02"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+
+	c, err := util.NewStorage(filename, map[string]string{}, true)
+	if err != nil {
+		t.Fatalf("NewStorage() error: %v", err)
+	}
+	for key, want := range map[string]string{"owner": "0x01", "operator": "0x02"} {
+		if got, err := c.Get(key); err != nil || got != want {
+			t.Errorf("Get(%s) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "accounts.toml" && e.Name() != "accounts.toml.lock" {
+			t.Errorf("unexpected file %s", e.Name())
+		}
+	}
+}
+
+func TestTryLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autopilot.pid")
+
+	l, err := util.TryLockFile(path)
+	if err != nil {
+		t.Fatalf("TryLockFile() error: %v", err)
+	}
+	if _, err := util.TryLockFile(path); err != util.ErrLocked {
+		t.Errorf("TryLockFile() expected ErrLocked, got %v", err)
+	}
+
+	if err := l.Unlock(); err != nil {
+		t.Fatalf("Unlock() error: %v", err)
+	}
+	l, err = util.TryLockFile(path)
+	if err != nil {
+		t.Fatalf("TryLockFile() after unlock error: %v", err)
+	}
+	l.Unlock()
 }